@@ -17,7 +17,11 @@
 
 package api
 
-import "os"
+import (
+	"os"
+
+	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
+)
 
 // Config is the configuration for the API endpoint.
 type Config struct {
@@ -26,6 +30,11 @@ type Config struct {
 	Port               int    `config:"port"`
 	User               string `config:"named_pipe.user"`
 	SecurityDescriptor string `config:"named_pipe.security_descriptor"`
+
+	// TLS configures TLS on the listener. When `client_authentication` is
+	// `required` requests without a verified client certificate are
+	// rejected with 403 Forbidden.
+	TLS *tlscommon.ServerConfig `config:"ssl"`
 }
 
 // DefaultConfig is the default configuration used by the API endpoint.
@@ -0,0 +1,134 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+// unmatchedRoute is the route reported for requests not matching any of the
+// routes of the server.
+const unmatchedRoute = "unmatched"
+
+// requestMetrics records the number and latency of the requests served, in
+// total and per route, by status class:
+//
+//	requests.<class>.count
+//	requests.<class>.duration.ns
+//	routes.<route>.<class>.count
+//	routes.<route>.<class>.duration.ns
+//
+// The route is the pattern the request matched in the ServeMux, not the
+// request path, so paths below subtree routes or with parameters do not
+// create new metrics.
+type requestMetrics struct {
+	reg *monitoring.Registry
+
+	mu    sync.Mutex
+	stats map[string]*requestStats
+}
+
+type requestStats struct {
+	count    *monitoring.Uint
+	duration *monitoring.Uint
+}
+
+func newRequestMetrics(reg *monitoring.Registry) *requestMetrics {
+	return &requestMetrics{reg: reg, stats: map[string]*requestStats{}}
+}
+
+// EnableMetrics records request metrics into reg for all requests served by
+// the server. It must be called before Start.
+func (s *Server) EnableMetrics(reg *monitoring.Registry) {
+	s.metrics = newRequestMetrics(reg)
+}
+
+// wrap returns a handler recording the metrics of the requests served by h.
+// route returns the route of a request.
+func (m *requestMetrics) wrap(h http.Handler, route func(*http.Request) string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rec, r)
+		m.record(route(r), rec.status, time.Since(start))
+	})
+}
+
+func (m *requestMetrics) record(route string, status int, d time.Duration) {
+	class := strconv.Itoa(status/100) + "xx"
+	if route == "" {
+		route = unmatchedRoute
+	}
+	// Dots separate the levels of the registry.
+	route = strings.ReplaceAll(route, ".", "_")
+
+	for _, name := range []string{"requests." + class, "routes." + route + "." + class} {
+		stats := m.get(name)
+		stats.count.Inc()
+		stats.duration.Add(uint64(d))
+	}
+}
+
+func (m *requestMetrics) get(name string) *requestStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, found := m.stats[name]
+	if !found {
+		reg := m.reg.NewNamespace(name)
+		stats = &requestStats{
+			count:    monitoring.NewUint(reg, "count"),
+			duration: monitoring.NewUint(reg, "duration.ns"),
+		}
+		m.stats[name] = stats
+	}
+	return stats
+}
+
+// statusRecorder captures the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher, it is used by streaming handlers like pprof.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,95 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+func TestRequestMetrics(t *testing.T) {
+	mux := simpleMux()
+	mux.HandleFunc("/items/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/items/error" {
+			http.Error(w, "failed", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, "item")
+	})
+	mux.HandleFunc("/v1.0/status", func(w http.ResponseWriter, r *http.Request) {})
+
+	s, err := New(nil, mux, config.MustNewConfigFrom(map[string]interface{}{
+		"host": localhostURL,
+	}))
+	require.NoError(t, err)
+
+	reg := monitoring.NewRegistry()
+	s.EnableMetrics(reg)
+	go s.Start()
+	defer func() {
+		require.NoError(t, s.Stop(), "error stopping test server")
+	}()
+
+	for _, path := range []string{"/echo-hello", "/echo-hello", "/items/1", "/items/2", "/items/error", "/missing", "/v1.0/status"} {
+		req, err := http.NewRequestWithContext(context.Background(), "GET", "http://"+s.l.Addr().String()+path, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		_, _ = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+
+	count := func(name string) uint64 {
+		t.Helper()
+		v, ok := reg.Get(name + ".count").(*monitoring.Uint)
+		require.True(t, ok, "metric %s.count not found", name)
+		return v.Get()
+	}
+	assert.Equal(t, uint64(2), count("routes./echo-hello.2xx"))
+	assert.Equal(t, uint64(2), count("routes./items/.2xx"))
+	assert.Equal(t, uint64(1), count("routes./items/.5xx"))
+	assert.Equal(t, uint64(1), count("routes.unmatched.4xx"))
+	assert.Equal(t, uint64(1), count("routes./v1_0/status.2xx"))
+	assert.Equal(t, uint64(5), count("requests.2xx"))
+	assert.Equal(t, uint64(1), count("requests.4xx"))
+	assert.Equal(t, uint64(1), count("requests.5xx"))
+
+	duration, ok := reg.Get("requests.2xx.duration.ns").(*monitoring.Uint)
+	require.True(t, ok)
+	assert.NotZero(t, duration.Get())
+
+	assert.Nil(t, reg.Get("routes./items/1"), "request paths must not create routes")
+}
+
+func TestRequestMetricsDisabled(t *testing.T) {
+	s, err := New(nil, simpleMux(), config.MustNewConfigFrom(map[string]interface{}{
+		"host": localhostURL,
+	}))
+	require.NoError(t, err)
+	defer s.Stop()
+	assert.Equal(t, s.mux, s.handler())
+}
@@ -18,6 +18,7 @@
 package api
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
@@ -27,6 +28,7 @@ import (
 
 	"github.com/elastic/elastic-agent-libs/config"
 	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
 )
 
 const (
@@ -41,6 +43,9 @@ type Server struct {
 	mux    *http.ServeMux
 	l      net.Listener
 	config Config
+
+	requireClientCert bool
+	metrics           *requestMetrics
 }
 
 // New creates a new API Server.
@@ -55,12 +60,32 @@ func New(log *logp.Logger, mux *http.ServeMux, c *config.C) (*Server, error) {
 		return nil, err
 	}
 
+	var tlsConfig *tlscommon.TLSConfig
+	if cfg.TLS.IsEnabled() {
+		tlsConfig, err = tlscommon.LoadTLSServerConfig(cfg.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS configuration: %w", err)
+		}
+	}
+
 	l, err := makeListener(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Server{mux: mux, l: l, config: cfg, log: log.Named("api")}, nil
+	s := &Server{mux: mux, l: l, config: cfg, log: log.Named("api")}
+	if tlsConfig != nil {
+		// A missing client certificate must be answered with 403 instead of a
+		// failed handshake, so the handshake only verifies certificates that
+		// are presented and the handler enforces their presence.
+		if tlsConfig.ClientAuth == tls.RequireAndVerifyClientCert {
+			s.requireClientCert = true
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+		s.l = tls.NewListener(l, tlsConfig.BuildServerConfig(""))
+	}
+
+	return s, nil
 }
 
 // Start starts the HTTP server and accepting new connection.
@@ -68,11 +93,34 @@ func (s *Server) Start() {
 	s.log.Info("Starting stats endpoint")
 	go func(l net.Listener) {
 		s.log.Infof("Metrics endpoint listening on: %s (configured: %s)", l.Addr().String(), s.config.Host)
-		err := http.Serve(l, s.mux)
+		err := http.Serve(l, s.handler())
 		s.log.Infof("Stats endpoint (%s) finished: %v", l.Addr().String(), err)
 	}(s.l)
 }
 
+// handler returns the handler serving the requests, rejecting requests that
+// don't carry a client certificate when mutual TLS is required and recording
+// the request metrics when enabled.
+func (s *Server) handler() http.Handler {
+	var h http.Handler = s.mux
+	if s.requireClientCert {
+		h = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+				http.Error(w, "client certificate required", http.StatusForbidden)
+				return
+			}
+			s.mux.ServeHTTP(w, r)
+		})
+	}
+	if s.metrics != nil {
+		h = s.metrics.wrap(h, func(r *http.Request) string {
+			_, pattern := s.mux.Handler(r)
+			return pattern
+		})
+	}
+	return h
+}
+
 // Stop stops the API server and free any resource associated with the process like unix sockets.
 func (s *Server) Stop() error {
 	return s.l.Close()
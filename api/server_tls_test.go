@@ -0,0 +1,181 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/config"
+)
+
+func TestHTTPSWithClientCertificate(t *testing.T) {
+	ca := genTestCert(t, "ca", nil)
+	serverCert := genTestCert(t, "localhost", &ca)
+	clientCert := genTestCert(t, "localhost", &ca)
+
+	s := startTLSServer(t, ca, serverCert)
+
+	client := tlsTestClient(t, ca, &clientCert)
+	r, err := client.Get("https://" + s.l.Addr().String() + "/echo-hello")
+	require.NoError(t, err)
+	defer r.Body.Close()
+
+	body, err := ioutil.ReadAll(r.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, r.StatusCode)
+	assert.Equal(t, "ehlo!", string(body))
+}
+
+func TestHTTPSRejectsClientWithoutCertificate(t *testing.T) {
+	ca := genTestCert(t, "ca", nil)
+	serverCert := genTestCert(t, "localhost", &ca)
+
+	s := startTLSServer(t, ca, serverCert)
+
+	client := tlsTestClient(t, ca, nil)
+	r, err := client.Get("https://" + s.l.Addr().String() + "/echo-hello")
+	require.NoError(t, err)
+	defer r.Body.Close()
+
+	assert.Equal(t, http.StatusForbidden, r.StatusCode)
+}
+
+func TestHTTPSRejectsUntrustedClientCertificate(t *testing.T) {
+	ca := genTestCert(t, "ca", nil)
+	serverCert := genTestCert(t, "localhost", &ca)
+	otherCA := genTestCert(t, "other-ca", nil)
+	clientCert := genTestCert(t, "localhost", &otherCA)
+
+	s := startTLSServer(t, ca, serverCert)
+
+	client := tlsTestClient(t, ca, &clientCert)
+	r, err := client.Get("https://" + s.l.Addr().String() + "/echo-hello")
+	if err == nil {
+		r.Body.Close()
+	}
+	require.Error(t, err)
+}
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM string
+	keyPEM  string
+}
+
+func startTLSServer(t *testing.T, ca, serverCert testCert) *Server {
+	cfg := config.MustNewConfigFrom(map[string]interface{}{
+		"host": localhostURL,
+		"ssl": map[string]interface{}{
+			"certificate":             serverCert.certPEM,
+			"key":                     serverCert.keyPEM,
+			"certificate_authorities": []string{ca.certPEM},
+			"client_authentication":   "required",
+		},
+	})
+
+	s, err := New(nil, simpleMux(), cfg)
+	require.NoError(t, err)
+	require.True(t, s.requireClientCert)
+	go s.Start()
+	t.Cleanup(func() {
+		require.NoError(t, s.Stop(), "error stopping test server")
+	})
+	return s
+}
+
+func tlsTestClient(t *testing.T, ca testCert, cert *testCert) *http.Client {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	tlsConfig := &tls.Config{ //nolint:gosec // test client
+		RootCAs:    roots,
+		ServerName: "localhost",
+	}
+	if cert != nil {
+		pair, err := tls.X509KeyPair([]byte(cert.certPEM), []byte(cert.keyPEM))
+		require.NoError(t, err)
+		// Always present the certificate, even when it isn't issued by one
+		// of the CAs the server asks for.
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return &pair, nil
+		}
+	}
+
+	transport := &http.Transport{TLSClientConfig: tlsConfig}
+	t.Cleanup(transport.CloseIdleConnections)
+	return &http.Client{Transport: transport, Timeout: 10 * time.Second}
+}
+
+// genTestCert generates a certificate for commonName, it is self-signed as a
+// CA when parent is nil.
+func genTestCert(t *testing.T, commonName string, parent *testCert) testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		template.DNSNames = []string{commonName}
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		keyPEM:  string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})),
+	}
+}
@@ -0,0 +1,174 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	ucfg "github.com/elastic/go-ucfg"
+)
+
+type alias struct {
+	old, new string
+}
+
+var (
+	aliasesMu sync.RWMutex
+	aliases   []alias
+	warned    = map[string]bool{}
+
+	aliasHandler = logDeprecatedAlias
+)
+
+// RegisterAlias registers oldKey as a deprecated name of newKey. When a
+// configuration using oldKey is unpacked, its value is used for newKey and
+// the handler set with OnDeprecatedAlias is called once per process. Setting
+// both keys makes unpacking fail. Keys are full paths from the root of the
+// configuration, children created with Child are unpacked with the aliases
+// below their path.
+//
+// RegisterAlias panics if a key is empty or both keys are the same.
+func RegisterAlias(oldKey, newKey string) {
+	if oldKey == "" || newKey == "" || oldKey == newKey {
+		panic(fmt.Sprintf("invalid config alias '%v' -> '%v'", oldKey, newKey))
+	}
+
+	aliasesMu.Lock()
+	defer aliasesMu.Unlock()
+	aliases = append(aliases, alias{old: oldKey, new: newKey})
+}
+
+// OnDeprecatedAlias sets the function called the first time a deprecated
+// alias registered with RegisterAlias is used. By default a deprecation
+// warning is logged with the cfgwarn selector.
+func OnDeprecatedAlias(fn func(oldKey, newKey string)) {
+	aliasesMu.Lock()
+	defer aliasesMu.Unlock()
+	aliasHandler = fn
+}
+
+// hasAliases returns true if aliases are registered.
+func hasAliases() bool {
+	aliasesMu.RLock()
+	defer aliasesMu.RUnlock()
+	return len(aliases) > 0
+}
+
+// applyAliases returns c with the values of deprecated keys moved to their
+// new names. c is returned as is if no deprecated key is used, otherwise a
+// copy keeping the position of c is returned, c is never modified. opts are
+// the options used to read the primitive values.
+func applyAliases(c *C, opts []ucfg.Option) (*C, error) {
+	aliasesMu.RLock()
+	registered := aliases
+	aliasesMu.RUnlock()
+	if len(registered) == 0 {
+		return c, nil
+	}
+
+	base := c.Path()
+	out := c
+	for _, a := range registered {
+		oldKey, ok := relativeKey(base, a.old)
+		if !ok || !hasKey(out, oldKey) {
+			continue
+		}
+		newKey, ok := relativeKey(base, a.new)
+		if !ok {
+			continue
+		}
+		if hasKey(out, newKey) {
+			return nil, fmt.Errorf("setting '%v' is deprecated and conflicts with '%v', remove '%v'", a.old, a.new, a.old)
+		}
+
+		if out == c {
+			var err error
+			if out, err = copyInPlace(c); err != nil {
+				return nil, err
+			}
+		}
+		if err := moveKey(out, oldKey, newKey, opts); err != nil {
+			return nil, err
+		}
+		warnAlias(a)
+	}
+	return out, nil
+}
+
+func relativeKey(base, key string) (string, bool) {
+	if base == "" {
+		return key, true
+	}
+	if strings.HasPrefix(key, base+".") {
+		return key[len(base)+1:], true
+	}
+	return "", false
+}
+
+func hasKey(c *C, key string) bool {
+	ok, err := c.Has(key, -1)
+	return err == nil && ok
+}
+
+// moveKey moves the setting at oldKey to newKey. Objects and lists are moved
+// as is, their variables are resolved when the configuration is unpacked.
+// Only primitive values are read with opts, they are set again without
+// expanding their variables a second time.
+func moveKey(c *C, oldKey, newKey string, opts []ucfg.Option) error {
+	pathOpts := []ucfg.Option{ucfg.PathSep(".")}
+	value, err := c.access().Child(oldKey, -1, pathOpts...)
+	if err != nil {
+		return movePrimitive(c, oldKey, newKey, opts)
+	}
+	if _, err := c.access().Remove(oldKey, -1, pathOpts...); err != nil {
+		return err
+	}
+	return c.access().SetChild(newKey, -1, value, pathOpts...)
+}
+
+func movePrimitive(c *C, oldKey, newKey string, opts []ucfg.Option) error {
+	// Only the value is read, the variables of its siblings are left to the
+	// unpacker.
+	value, err := valueAt(c, oldKey, opts)
+	if err != nil {
+		return err
+	}
+	if _, err := c.Remove(oldKey, -1); err != nil {
+		return err
+	}
+	return c.access().Merge(map[string]interface{}{newKey: value}, ucfg.PathSep("."))
+}
+
+func logDeprecatedAlias(oldKey, newKey string) {
+	logp.NewLogger("cfgwarn").Warnf("DEPRECATED: setting '%v' has been renamed to '%v'.", oldKey, newKey)
+}
+
+func warnAlias(a alias) {
+	aliasesMu.Lock()
+	fn := aliasHandler
+	first := !warned[a.old]
+	warned[a.old] = true
+	aliasesMu.Unlock()
+
+	if first && fn != nil {
+		fn(a.old, a.new)
+	}
+}
@@ -0,0 +1,230 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+func withAliases(t *testing.T, pairs ...string) *[][2]string {
+	aliasesMu.Lock()
+	prevAliases, prevWarned, prevHandler := aliases, warned, aliasHandler
+	aliases, warned = nil, map[string]bool{}
+	aliasesMu.Unlock()
+	t.Cleanup(func() {
+		aliasesMu.Lock()
+		defer aliasesMu.Unlock()
+		aliases, warned, aliasHandler = prevAliases, prevWarned, prevHandler
+	})
+
+	for i := 0; i < len(pairs); i += 2 {
+		RegisterAlias(pairs[i], pairs[i+1])
+	}
+
+	var warnings [][2]string
+	OnDeprecatedAlias(func(oldKey, newKey string) {
+		warnings = append(warnings, [2]string{oldKey, newKey})
+	})
+	return &warnings
+}
+
+func TestAliasResolution(t *testing.T) {
+	warnings := withAliases(t,
+		"output.elasticsearch.max_retry", "output.elasticsearch.retry.max",
+		"queue.mem", "queue.memory",
+	)
+
+	type settings struct {
+		Output struct {
+			Elasticsearch struct {
+				Hosts []string `config:"hosts"`
+				Retry struct {
+					Max int `config:"max"`
+				} `config:"retry"`
+			} `config:"elasticsearch"`
+		} `config:"output"`
+		Queue struct {
+			Memory struct {
+				Events int `config:"events"`
+			} `config:"memory"`
+		} `config:"queue"`
+	}
+
+	c := MustNewConfigFrom(`
+output.elasticsearch:
+  hosts: [localhost]
+  max_retry: 3
+queue.mem.events: ${events}
+events: 4096
+`)
+	for i := 0; i < 2; i++ {
+		var s settings
+		require.NoError(t, c.Unpack(&s))
+		assert.Equal(t, []string{"localhost"}, s.Output.Elasticsearch.Hosts)
+		assert.Equal(t, 3, s.Output.Elasticsearch.Retry.Max)
+		assert.Equal(t, 4096, s.Queue.Memory.Events)
+	}
+
+	// the source config is not modified and warnings are reported once.
+	assert.True(t, c.HasField("queue"))
+	has, err := c.Has("queue.mem", -1)
+	require.NoError(t, err)
+	assert.True(t, has)
+	assert.Equal(t, [][2]string{
+		{"output.elasticsearch.max_retry", "output.elasticsearch.retry.max"},
+		{"queue.mem", "queue.memory"},
+	}, *warnings)
+
+	t.Run("child config", func(t *testing.T) {
+		child, err := c.Child("output", -1)
+		require.NoError(t, err)
+
+		var s struct {
+			Elasticsearch struct {
+				Retry struct {
+					Max int `config:"max"`
+				} `config:"retry"`
+			} `config:"elasticsearch"`
+		}
+		require.NoError(t, child.UnpackWithOptions(&s))
+		assert.Equal(t, 3, s.Elasticsearch.Retry.Max)
+	})
+
+	t.Run("new key only", func(t *testing.T) {
+		var s settings
+		require.NoError(t, MustNewConfigFrom("output.elasticsearch.retry.max: 5").Unpack(&s))
+		assert.Equal(t, 5, s.Output.Elasticsearch.Retry.Max)
+	})
+}
+
+func TestAliasKeepsVariables(t *testing.T) {
+	withAliases(t, "old.name", "new.name", "old.paths", "new.paths")
+
+	var s struct {
+		New struct {
+			Name  string            `config:"name"`
+			Paths map[string]string `config:"paths"`
+		} `config:"new"`
+	}
+	c := MustNewConfigFrom(`
+base: /var/lib
+old.name: "$${raw}"
+old.paths: {data: "${base}/data", pattern: "$${date}.log"}
+`)
+	require.NoError(t, c.Unpack(&s))
+	assert.Equal(t, "${raw}", s.New.Name)
+	assert.Equal(t, map[string]string{"data": "/var/lib/data", "pattern": "${date}.log"}, s.New.Paths)
+}
+
+func TestAliasOnChildConfig(t *testing.T) {
+	withAliases(t, "output.es.old_name", "output.es.name")
+
+	c := MustNewConfigFrom(`
+top: from-parent
+output.es:
+  old_name: ${top}
+  port: ${missing}
+`)
+	child, err := c.ChildAt("output.es")
+	require.NoError(t, err)
+
+	var s struct {
+		Name string `config:"name"`
+	}
+	require.NoError(t, child.Unpack(&s))
+	assert.Equal(t, "from-parent", s.Name)
+
+	// the errors name the full path of the settings
+	var invalid struct {
+		Port int `config:"port"`
+	}
+	err = child.Unpack(&invalid)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "output.es.port")
+}
+
+func TestAliasWithVariableProvider(t *testing.T) {
+	withAliases(t, "old_user", "user")
+
+	provider := func(name string) (string, error) {
+		if name == "pw" {
+			return "secret", nil
+		}
+		return "", fmt.Errorf("unknown variable %v", name)
+	}
+	var s struct {
+		User     string `config:"user"`
+		Password string `config:"password"`
+	}
+	c := MustNewConfigFrom(`{old_user: "${ks.user}", password: "${ks.pw}"}`)
+	err := c.UnpackWithOptions(&s, WithVariableProvider("ks", provider))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown variable user")
+
+	c = MustNewConfigFrom(`{old_user: elastic, password: "${ks.pw}"}`)
+	require.NoError(t, c.UnpackWithOptions(&s, WithVariableProvider("ks", provider)))
+	assert.Equal(t, "elastic", s.User)
+	assert.Equal(t, "secret", s.Password)
+}
+
+func TestAliasDefaultWarning(t *testing.T) {
+	withAliases(t, "old.key", "new.key")
+	aliasesMu.Lock()
+	aliasHandler = logDeprecatedAlias
+	aliasesMu.Unlock()
+
+	require.NoError(t, logp.DevelopmentSetup(logp.ToObserverOutput()))
+	var s struct {
+		New struct {
+			Key int `config:"key"`
+		} `config:"new"`
+	}
+	require.NoError(t, MustNewConfigFrom("old.key: 1").Unpack(&s))
+	assert.Equal(t, 1, s.New.Key)
+
+	logs := logp.ObserverLogs().FilterMessage("DEPRECATED: setting 'old.key' has been renamed to 'new.key'.").TakeAll()
+	require.Len(t, logs, 1)
+	assert.Equal(t, "cfgwarn", logs[0].LoggerName)
+}
+
+func TestAliasConflict(t *testing.T) {
+	warnings := withAliases(t, "old.key", "new.key")
+
+	c := MustNewConfigFrom("old.key: 1\nnew.key: 2\n")
+	var s struct {
+		New struct {
+			Key int `config:"key"`
+		} `config:"new"`
+	}
+	err := c.Unpack(&s)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "setting 'old.key' is deprecated and conflicts with 'new.key'")
+	assert.Empty(t, *warnings)
+}
+
+func TestRegisterAliasInvalid(t *testing.T) {
+	withAliases(t)
+	assert.Panics(t, func() { RegisterAlias("", "new") })
+	assert.Panics(t, func() { RegisterAlias("same", "same") })
+}
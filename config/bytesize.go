@@ -0,0 +1,153 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a size in bytes that can be configured with a unit, e.g.
+// `512MB` or `1GiB`. SI units (KB, MB, GB, TB) are multiples of 1000, binary
+// units (KiB, MiB, GiB, TiB) multiples of 1024. Numbers without a unit are
+// bytes.
+type ByteSize uint64
+
+type byteUnit struct {
+	name string
+	size uint64
+}
+
+// byteUnits is ordered from the largest to the smallest unit.
+var byteUnits = []byteUnit{
+	{"TiB", 1 << 40},
+	{"TB", 1e12},
+	{"GiB", 1 << 30},
+	{"GB", 1e9},
+	{"MiB", 1 << 20},
+	{"MB", 1e6},
+	{"KiB", 1 << 10},
+	{"KB", 1e3},
+	{"B", 1},
+}
+
+// Unpack implements ucfg.Unpacker.
+func (b *ByteSize) Unpack(v interface{}) error {
+	tmp, err := ParseByteSize(v)
+	*b = tmp
+	return err
+}
+
+// Bytes returns b as a number of bytes.
+func (b ByteSize) Bytes() uint64 { return uint64(b) }
+
+// String renders b with the largest unit that represents it exactly, e.g.
+// `512MiB`, `500KB` or `1000B`.
+func (b ByteSize) String() string {
+	if b == 0 {
+		return "0B"
+	}
+	for _, u := range byteUnits {
+		if uint64(b)%u.size == 0 {
+			return strconv.FormatUint(uint64(b)/u.size, 10) + u.name
+		}
+	}
+	return strconv.FormatUint(uint64(b), 10) + "B"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (b ByteSize) MarshalText() ([]byte, error) { return []byte(b.String()), nil }
+
+// ParseByteSize converts a configuration value into a ByteSize. Units are
+// matched case-insensitively. Single letter units like `M` are rejected, as
+// they do not tell SI and binary units apart.
+func ParseByteSize(v interface{}) (ByteSize, error) {
+	switch val := v.(type) {
+	case string:
+		return parseByteSizeString(val)
+	case int64:
+		if val < 0 {
+			return 0, fmt.Errorf("invalid byte size '%v': must not be negative", val)
+		}
+		return ByteSize(val), nil
+	case uint64:
+		return ByteSize(val), nil
+	case float64:
+		return floatToByteSize(val, val, 1)
+	default:
+		return 0, fmt.Errorf("invalid byte size '%v': unsupported type %T", v, v)
+	}
+}
+
+func parseByteSizeString(s string) (ByteSize, error) {
+	str := strings.TrimSpace(s)
+	i := strings.IndexFunc(str, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.' && r != '-' && r != '+'
+	})
+	number, unit := str, ""
+	if i >= 0 {
+		number, unit = str[:i], strings.TrimSpace(str[i:])
+	}
+
+	size := uint64(1)
+	if unit != "" {
+		u, ok := lookupByteUnit(unit)
+		if !ok {
+			return 0, fmt.Errorf("invalid byte size '%v': unknown unit '%v', use one of B, KB, MB, GB, TB, KiB, MiB, GiB or TiB", s, unit)
+		}
+		size = u.size
+	}
+
+	if n, err := strconv.ParseUint(number, 10, 64); err == nil {
+		if n > math.MaxUint64/size {
+			return 0, fmt.Errorf("invalid byte size '%v': value out of range", s)
+		}
+		return ByteSize(n * size), nil
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size '%v'", s)
+	}
+	return floatToByteSize(s, f, size)
+}
+
+func lookupByteUnit(name string) (byteUnit, bool) {
+	for _, u := range byteUnits {
+		if strings.EqualFold(u.name, name) {
+			return u, true
+		}
+	}
+	return byteUnit{}, false
+}
+
+func floatToByteSize(raw interface{}, f float64, unit uint64) (ByteSize, error) {
+	if f < 0 {
+		return 0, fmt.Errorf("invalid byte size '%v': must not be negative", raw)
+	}
+	bytes := f * float64(unit)
+	if bytes >= math.MaxUint64 {
+		return 0, fmt.Errorf("invalid byte size '%v': value out of range", raw)
+	}
+	if bytes != math.Trunc(bytes) {
+		return 0, fmt.Errorf("invalid byte size '%v': not a whole number of bytes", raw)
+	}
+	return ByteSize(bytes), nil
+}
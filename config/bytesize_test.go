@@ -0,0 +1,112 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestByteSizeUnpack(t *testing.T) {
+	tests := map[string]struct {
+		yaml string
+		want uint64
+	}{
+		"bytes":         {yaml: "512", want: 512},
+		"bytes unit":    {yaml: "512B", want: 512},
+		"quoted number": {yaml: `"512"`, want: 512},
+		"KB":            {yaml: "2KB", want: 2000},
+		"MB":            {yaml: "512MB", want: 512e6},
+		"GB":            {yaml: "3GB", want: 3e9},
+		"TB":            {yaml: "1TB", want: 1e12},
+		"KiB":           {yaml: "2KiB", want: 2048},
+		"MiB":           {yaml: "512MiB", want: 512 << 20},
+		"GiB":           {yaml: "3GiB", want: 3 << 30},
+		"TiB":           {yaml: "1TiB", want: 1 << 40},
+		"lower case":    {yaml: "10mb", want: 10e6},
+		"space":         {yaml: "10 MiB", want: 10 << 20},
+		"fraction":      {yaml: "1.5KiB", want: 1536},
+		"zero":          {yaml: "0", want: 0},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewConfigFrom("size: " + test.yaml)
+			require.NoError(t, err)
+
+			var cfg struct {
+				Size ByteSize `config:"size"`
+			}
+			require.NoError(t, c.Unpack(&cfg))
+			assert.Equal(t, test.want, cfg.Size.Bytes())
+		})
+	}
+}
+
+func TestByteSizeUnpackInvalid(t *testing.T) {
+	tests := map[string]struct {
+		yaml string
+		msg  string
+	}{
+		"ambiguous unit":  {yaml: "512M", msg: "unknown unit 'M'"},
+		"unknown unit":    {yaml: "5 parsecs", msg: "unknown unit"},
+		"negative":        {yaml: "-1MB", msg: "must not be negative"},
+		"partial bytes":   {yaml: "1.5B", msg: "not a whole number of bytes"},
+		"overflow":        {yaml: "20000000TiB", msg: "out of range"},
+		"missing number":  {yaml: "MB", msg: "invalid byte size"},
+		"invalid number":  {yaml: "1.2.3MB", msg: "invalid byte size"},
+		"negative number": {yaml: "-5", msg: "must not be negative"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewConfigFrom("size: " + test.yaml)
+			require.NoError(t, err)
+
+			var cfg struct {
+				Size ByteSize `config:"size"`
+			}
+			err = c.Unpack(&cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.msg)
+		})
+	}
+}
+
+func TestByteSizeString(t *testing.T) {
+	tests := map[ByteSize]string{
+		0:         "0B",
+		1000:      "1KB",
+		1024:      "1KiB",
+		1536:      "1536B",
+		512 << 20: "512MiB",
+		512e6:     "512MB",
+		3 << 30:   "3GiB",
+		1 << 40:   "1TiB",
+		1001:      "1001B",
+	}
+	for size, want := range tests {
+		assert.Equal(t, want, size.String())
+
+		parsed, err := ParseByteSize(want)
+		require.NoError(t, err)
+		assert.Equal(t, size, parsed)
+	}
+}
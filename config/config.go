@@ -21,6 +21,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/elastic/elastic-agent-libs/str"
@@ -30,6 +33,12 @@ import (
 
 // C object to store hierarchical configurations into.
 // See https://godoc.org/github.com/elastic/go-ucfg#Config
+//
+// String values can reference other settings and environment variables with
+// `${name}`. `${name:default}` falls back to default when name is unset or
+// empty and `${name:?message}` makes unpacking the setting fail with message.
+// Expansions can be nested, e.g. `${A:${B:default}}`, and `$$` and `$}`
+// escape `$` and `}`.
 type C ucfg.Config
 
 // Namespace stores at most one configuration section by name and sub-section.
@@ -40,6 +49,10 @@ type Namespace struct {
 
 const mask = "xxxxx"
 
+// ErrPathNotFound is returned by ChildAt when there is no setting at the
+// requested path.
+var ErrPathNotFound = errors.New("path not found")
+
 var (
 	configOpts = []ucfg.Option{
 		ucfg.PathSep("."),
@@ -126,13 +139,7 @@ func MergeConfigsWithOptions(cfgs []*C, options ...ucfg.Option) (*C, error) {
 
 // NewConfigWithYAML reads a YAML configuration.
 func NewConfigWithYAML(in []byte, source string) (*C, error) {
-	opts := append(
-		[]ucfg.Option{
-			ucfg.MetaData(ucfg.Meta{Source: source}),
-		},
-		configOpts...,
-	)
-	c, err := yaml.NewConfig(in, opts...)
+	c, err := yaml.NewConfig(in, sourceOpts(source)...)
 	return fromConfig(c), err
 }
 
@@ -155,8 +162,19 @@ func (c *C) MergeWithOpts(from interface{}, opts ...ucfg.Option) error {
 	return c.access().Merge(from, o...)
 }
 
+// Unpack unpacks the configuration into to. The aliases, the settings read
+// from files and the constraints of this package are applied as with
+// UnpackWithOptions, the types using none of them are unpacked directly by
+// go-ucfg.
 func (c *C) Unpack(to interface{}) error {
-	return c.access().Unpack(to, configOpts...)
+	t := reflect.TypeOf(to)
+	if hasAliases() || usesSecretFiles(t) || usesLocalConstraints(t) {
+		return c.UnpackWithOptions(to)
+	}
+	if err := c.access().Unpack(to, configOpts...); err != nil {
+		return withIntegerRanges(err, c, to)
+	}
+	return nil
 }
 
 func (c *C) Path() string {
@@ -204,6 +222,100 @@ func (c *C) Child(name string, idx int) (*C, error) {
 	return fromConfig(sub), err
 }
 
+// ChildAt returns the sub-configuration at the dotted path, e.g.
+// `output.elasticsearch`, without unpacking the rest of the configuration.
+// The sub-configuration keeps its position in c, so variables referencing
+// settings outside of it are resolved when it is unpacked. If no setting
+// exists at path, the error wraps ErrPathNotFound.
+func (c *C) ChildAt(path string) (*C, error) {
+	sub, err := c.Child(path, -1)
+	if err != nil {
+		var uerr ucfg.Error
+		if errors.As(err, &uerr) && errors.Is(uerr.Reason(), ucfg.ErrMissing) {
+			return nil, fmt.Errorf("%w: '%v'", ErrPathNotFound, c.PathOf(path))
+		}
+		return nil, err
+	}
+	return sub, nil
+}
+
+// GetValue returns the value at the dotted path, e.g. `output.hosts`, with
+// its variables resolved. Arrays are returned as []interface{}, dictionaries
+// as map[string]interface{}, integers as int64 or uint64 and decimal numbers
+// as float64. If no setting exists at path, the error wraps ErrPathNotFound.
+func (c *C) GetValue(path string) (interface{}, error) {
+	has, err := c.Has(path, -1)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, fmt.Errorf("%w: '%v'", ErrPathNotFound, c.PathOf(path))
+	}
+
+	return valueAt(c, path, configOpts)
+}
+
+// valueAt returns the value at the dotted path, unpacked with opts.
+func valueAt(c *C, path string, opts []ucfg.Option) (interface{}, error) {
+	// Only the field of the generated struct is unpacked, the other settings
+	// are not resolved.
+	typ := reflect.StructOf([]reflect.StructField{{
+		Name: "Value",
+		Type: reflect.TypeOf((*interface{})(nil)).Elem(),
+		Tag:  reflect.StructTag("config:" + strconv.Quote(path)),
+	}})
+	to := reflect.New(typ)
+	if err := c.access().Unpack(to.Interface(), opts...); err != nil {
+		return nil, err
+	}
+	return to.Elem().Field(0).Interface(), nil
+}
+
+// ResolvePath resolves path, the value of the setting key, against the
+// directory of the configuration file defining key, e.g. a certificate next
+// to the configuration file. Settings read from an included file are resolved
+// against the included file, unless they are part of a list. Absolute paths,
+// and paths of settings not loaded from a file, are returned as is. If no
+// setting exists at key, the error wraps ErrPathNotFound.
+func (c *C) ResolvePath(path, key string) (string, error) {
+	source, err := c.source(key)
+	if err != nil {
+		return "", err
+	}
+	if source == "" || filepath.IsAbs(path) {
+		return path, nil
+	}
+	return filepath.Join(filepath.Dir(source), path), nil
+}
+
+// sourceMarker precedes the source of a value in the go-ucfg errors.
+const sourceMarker = " (source:'"
+
+// source returns the file the setting key has been loaded from, empty if
+// unknown. go-ucfg does not expose the metadata of the values but includes
+// their source in the errors, so the source is read from the error of
+// accessing the value as a dictionary.
+func (c *C) source(key string) (string, error) {
+	has, err := c.Has(key, -1)
+	if err != nil {
+		return "", err
+	}
+	if !has {
+		return "", fmt.Errorf("%w: '%v'", ErrPathNotFound, c.PathOf(key))
+	}
+
+	_, err = c.access().Child(key, -1, configOpts...)
+	if err == nil {
+		return "", fmt.Errorf("setting '%v' is not a single value", c.PathOf(key))
+	}
+	msg := err.Error()
+	i := strings.LastIndex(msg, sourceMarker)
+	if i < 0 || !strings.HasSuffix(msg, "')") {
+		return "", nil
+	}
+	return msg[i+len(sourceMarker) : len(msg)-2], nil
+}
+
 func (c *C) SetBool(name string, idx int, value bool) error {
 	return c.access().SetBool(name, idx, value, configOpts...)
 }
@@ -261,6 +373,26 @@ func (c *C) access() *ucfg.Config {
 	return (*ucfg.Config)(c)
 }
 
+// copyInPlace returns a copy of c keeping its position: the root of c is
+// copied and the copy of c is its child at the path of c. Variables
+// referencing settings outside of c are still resolved and errors name the
+// full path of the settings.
+func copyInPlace(c *C) (*C, error) {
+	root := c.access()
+	for root.Parent() != nil {
+		root = root.Parent()
+	}
+
+	out := NewConfig()
+	if err := out.Merge(fromConfig(root)); err != nil {
+		return nil, err
+	}
+	if path := c.Path(); path != "" {
+		return out.Child(path, -1)
+	}
+	return out, nil
+}
+
 // GetFields returns the list of fields in the configuration.
 func (c *C) GetFields() []string {
 	return c.access().GetFields()
@@ -0,0 +1,195 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChildAt(t *testing.T) {
+	t.Setenv("ES_PASSWORD", "secret")
+	c, err := NewConfigWithYAML([]byte(`
+cluster: production
+output:
+  elasticsearch:
+    hosts: ["https://es:9200"]
+    index: logs-${cluster}
+    password: ${ES_PASSWORD}
+    ssl:
+      verification_mode: none
+  logstash.enabled: false
+`), "test")
+	require.NoError(t, err)
+
+	es, err := c.ChildAt("output.elasticsearch")
+	require.NoError(t, err)
+
+	var settings struct {
+		Hosts    []string `config:"hosts"`
+		Index    string   `config:"index"`
+		Password string   `config:"password"`
+		SSL      struct {
+			VerificationMode string `config:"verification_mode"`
+		} `config:"ssl"`
+	}
+	require.NoError(t, es.Unpack(&settings))
+	assert.Equal(t, []string{"https://es:9200"}, settings.Hosts)
+	assert.Equal(t, "logs-production", settings.Index)
+	assert.Equal(t, "secret", settings.Password)
+	assert.Equal(t, "none", settings.SSL.VerificationMode)
+	assert.Equal(t, "output.elasticsearch", es.Path())
+
+	ssl, err := es.ChildAt("ssl")
+	require.NoError(t, err)
+	mode, err := ssl.String("verification_mode", -1)
+	require.NoError(t, err)
+	assert.Equal(t, "none", mode)
+}
+
+func TestChildAtErrors(t *testing.T) {
+	c := MustNewConfigFrom(map[string]interface{}{
+		"output.elasticsearch.index": "logs",
+	})
+
+	for _, path := range []string{"output.logstash", "missing", "missing.deep.path"} {
+		_, err := c.ChildAt(path)
+		require.Error(t, err, path)
+		assert.True(t, errors.Is(err, ErrPathNotFound), "%v: %v", path, err)
+		assert.Contains(t, err.Error(), path)
+	}
+
+	// a setting that is not a dictionary is not reported as missing
+	_, err := c.ChildAt("output.elasticsearch.index")
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrPathNotFound), err)
+}
+
+func TestGetValue(t *testing.T) {
+	c, err := NewConfigWithYAML([]byte(`
+cluster: production
+broken: ${missing}
+output:
+  elasticsearch:
+    hosts: ["https://es:9200", "https://es2:9200"]
+    index: logs-${cluster}
+    workers: 4
+    ratio: 0.5
+    enabled: true
+    ssl:
+      verification_mode: none
+      certificate_authorities: [ca.pem]
+`), "test")
+	require.NoError(t, err)
+
+	tests := map[string]interface{}{
+		"cluster":                                    "production",
+		"output.elasticsearch.index":                 "logs-production",
+		"output.elasticsearch.workers":               uint64(4),
+		"output.elasticsearch.ratio":                 0.5,
+		"output.elasticsearch.enabled":               true,
+		"output.elasticsearch.hosts":                 []interface{}{"https://es:9200", "https://es2:9200"},
+		"output.elasticsearch.hosts.1":               "https://es2:9200",
+		"output.elasticsearch.ssl":                   map[string]interface{}{"verification_mode": "none", "certificate_authorities": []interface{}{"ca.pem"}},
+		"output.elasticsearch.ssl.verification_mode": "none",
+	}
+	for path, want := range tests {
+		v, err := c.GetValue(path)
+		require.NoError(t, err, path)
+		assert.Equal(t, want, v, path)
+	}
+
+	for _, path := range []string{"missing", "output.logstash", "output.elasticsearch.ssl.key"} {
+		_, err := c.GetValue(path)
+		require.Error(t, err, path)
+		assert.True(t, errors.Is(err, ErrPathNotFound), "%v: %v", path, err)
+	}
+
+	_, err = c.GetValue("broken")
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrPathNotFound), err)
+}
+
+func TestResolvePath(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "conf")
+	require.NoError(t, os.MkdirAll(filepath.Join(sub, "outputs"), 0700))
+
+	writeConfigFile(t, filepath.Join(sub, "main.yml"), `
+ssl.certificate: cert.pem
+ssl.key: /etc/ssl/key.pem
+ssl.certificate_authorities: [ca.pem]
+output: !include outputs/es.yml
+`)
+	writeConfigFile(t, filepath.Join(sub, "outputs", "es.yml"), `
+elasticsearch.ssl.certificate: es.pem
+`)
+
+	c, err := LoadFile(filepath.Join(sub, "main.yml"))
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		key  string
+		want string
+	}{
+		"relative":     {key: "ssl.certificate", want: filepath.Join(sub, "cert.pem")},
+		"absolute":     {key: "ssl.key", want: "/etc/ssl/key.pem"},
+		"list element": {key: "ssl.certificate_authorities.0", want: filepath.Join(sub, "ca.pem")},
+		"included":     {key: "output.elasticsearch.ssl.certificate", want: filepath.Join(sub, "outputs", "es.pem")},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			value, err := c.String(test.key, -1)
+			require.NoError(t, err)
+			path, err := c.ResolvePath(value, test.key)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, path)
+		})
+	}
+
+	t.Run("child", func(t *testing.T) {
+		ssl, err := c.Child("ssl", -1)
+		require.NoError(t, err)
+		path, err := ssl.ResolvePath("cert.pem", "certificate")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(sub, "cert.pem"), path)
+	})
+
+	t.Run("merged", func(t *testing.T) {
+		merged, err := MergeConfigs(c, MustNewConfigFrom(map[string]interface{}{"ssl.key": "key.pem"}))
+		require.NoError(t, err)
+		path, err := merged.ResolvePath("cert.pem", "ssl.certificate")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(sub, "cert.pem"), path)
+		path, err = merged.ResolvePath("key.pem", "ssl.key")
+		require.NoError(t, err)
+		assert.Equal(t, "key.pem", path)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := c.ResolvePath("x.pem", "ssl.missing")
+		assert.True(t, errors.Is(err, ErrPathNotFound), err)
+		_, err = c.ResolvePath("x.pem", "ssl")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,120 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Duration is a time.Duration that must be configured with an explicit unit,
+// e.g. `5s` or `100ms`. Numbers without a unit are rejected to avoid the
+// ambiguity of plain time.Duration fields, which interpret them as seconds.
+type Duration time.Duration
+
+// SecondsDuration is a time.Duration accepting numbers without a unit as
+// seconds. Values with an explicit unit are accepted as well.
+type SecondsDuration time.Duration
+
+// MillisecondsDuration is a time.Duration accepting numbers without a unit as
+// milliseconds. Values with an explicit unit are accepted as well.
+type MillisecondsDuration time.Duration
+
+// Unpack implements ucfg.Unpacker.
+func (d *Duration) Unpack(v interface{}) error {
+	tmp, err := ParseDuration(v, 0)
+	*d = Duration(tmp)
+	return err
+}
+
+// Duration returns d as a time.Duration.
+func (d Duration) Duration() time.Duration { return time.Duration(d) }
+
+// String returns the string representation of the duration.
+func (d Duration) String() string { return time.Duration(d).String() }
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) { return []byte(d.String()), nil }
+
+// Unpack implements ucfg.Unpacker.
+func (d *SecondsDuration) Unpack(v interface{}) error {
+	tmp, err := ParseDuration(v, time.Second)
+	*d = SecondsDuration(tmp)
+	return err
+}
+
+// Duration returns d as a time.Duration.
+func (d SecondsDuration) Duration() time.Duration { return time.Duration(d) }
+
+// String returns the string representation of the duration.
+func (d SecondsDuration) String() string { return time.Duration(d).String() }
+
+// MarshalText implements encoding.TextMarshaler.
+func (d SecondsDuration) MarshalText() ([]byte, error) { return []byte(d.String()), nil }
+
+// Unpack implements ucfg.Unpacker.
+func (d *MillisecondsDuration) Unpack(v interface{}) error {
+	tmp, err := ParseDuration(v, time.Millisecond)
+	*d = MillisecondsDuration(tmp)
+	return err
+}
+
+// Duration returns d as a time.Duration.
+func (d MillisecondsDuration) Duration() time.Duration { return time.Duration(d) }
+
+// String returns the string representation of the duration.
+func (d MillisecondsDuration) String() string { return time.Duration(d).String() }
+
+// MarshalText implements encoding.TextMarshaler.
+func (d MillisecondsDuration) MarshalText() ([]byte, error) { return []byte(d.String()), nil }
+
+// ParseDuration converts a configuration value into a time.Duration. Strings
+// are parsed with time.ParseDuration. Numbers, and strings without a unit,
+// are multiplied by defaultUnit; if defaultUnit is 0 they are rejected.
+// Custom duration types with another default unit can use ParseDuration in
+// their Unpack method.
+func ParseDuration(v interface{}, defaultUnit time.Duration) (time.Duration, error) {
+	switch val := v.(type) {
+	case string:
+		d, err := time.ParseDuration(val)
+		if err == nil {
+			return d, nil
+		}
+		f, parseErr := strconv.ParseFloat(val, 64)
+		if parseErr != nil {
+			return 0, fmt.Errorf("invalid duration '%v': %w", val, err)
+		}
+		return numberToDuration(val, f, defaultUnit)
+	case int64:
+		return numberToDuration(val, float64(val), defaultUnit)
+	case uint64:
+		return numberToDuration(val, float64(val), defaultUnit)
+	case float64:
+		return numberToDuration(val, val, defaultUnit)
+	default:
+		return 0, fmt.Errorf("invalid duration '%v': unsupported type %T", v, v)
+	}
+}
+
+func numberToDuration(raw interface{}, f float64, unit time.Duration) (time.Duration, error) {
+	if unit == 0 {
+		return 0, fmt.Errorf("invalid duration '%v': missing unit, use a value like '%vs'", raw, raw)
+	}
+	return time.Duration(f * float64(unit)), nil
+}
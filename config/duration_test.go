@@ -0,0 +1,77 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDurationUnpack(t *testing.T) {
+	tests := map[string]struct {
+		yaml     string
+		strict   time.Duration
+		strictOK bool
+		seconds  time.Duration
+		millis   time.Duration
+	}{
+		"seconds": {yaml: "5s", strict: 5 * time.Second, strictOK: true, seconds: 5 * time.Second, millis: 5 * time.Second},
+		"minutes": {yaml: "5m", strict: 5 * time.Minute, strictOK: true, seconds: 5 * time.Minute, millis: 5 * time.Minute},
+		"number":  {yaml: "5", seconds: 5 * time.Second, millis: 5 * time.Millisecond},
+		"quoted":  {yaml: `"5"`, seconds: 5 * time.Second, millis: 5 * time.Millisecond},
+		"float":   {yaml: "1.5", seconds: 1500 * time.Millisecond, millis: 1500 * time.Microsecond},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewConfigFrom("strict: " + test.yaml + "\nseconds: " + test.yaml + "\nmillis: " + test.yaml)
+			require.NoError(t, err)
+
+			var strict struct {
+				D Duration `config:"strict"`
+			}
+			err = c.Unpack(&strict)
+			if test.strictOK {
+				require.NoError(t, err)
+				assert.Equal(t, test.strict, strict.D.Duration())
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "missing unit")
+			}
+
+			var lenient struct {
+				Seconds SecondsDuration      `config:"seconds"`
+				Millis  MillisecondsDuration `config:"millis"`
+			}
+			require.NoError(t, c.Unpack(&lenient))
+			assert.Equal(t, test.seconds, lenient.Seconds.Duration())
+			assert.Equal(t, test.millis, lenient.Millis.Duration())
+		})
+	}
+}
+
+func TestDurationUnpackInvalid(t *testing.T) {
+	c := MustNewConfigFrom("d: 5 parsecs")
+	var cfg struct {
+		D SecondsDuration `config:"d"`
+	}
+	assert.Error(t, c.Unpack(&cfg))
+}
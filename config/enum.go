@@ -0,0 +1,129 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnumUnpacker converts the names of an enum type, as written in the
+// configuration, to its values. Names are matched case insensitively. It is
+// meant to be used by the Unpack method of the enum type:
+//
+//	type Mode int
+//
+//	const (
+//		ModeFast Mode = iota
+//		ModeSafe
+//	)
+//
+//	var modes = config.NewEnumUnpacker(map[string]interface{}{
+//		"fast": ModeFast,
+//		"safe": ModeSafe,
+//	})
+//
+//	func (m *Mode) Unpack(v interface{}) error { return modes.Unpack(m, v) }
+//
+//	func (m Mode) String() string { return modes.Name(m) }
+type EnumUnpacker struct {
+	typ    reflect.Type
+	values map[string]reflect.Value
+	names  []string
+}
+
+// NewEnumUnpacker creates an EnumUnpacker from the names of the enum values.
+// NewEnumUnpacker panics if values is empty, if the values don't have the
+// same type or if two names only differ by case.
+func NewEnumUnpacker(values map[string]interface{}) *EnumUnpacker {
+	if len(values) == 0 {
+		panic("config: enum without values")
+	}
+
+	e := &EnumUnpacker{values: make(map[string]reflect.Value, len(values))}
+	for name, value := range values {
+		v := reflect.ValueOf(value)
+		if e.typ == nil {
+			e.typ = v.Type()
+		} else if v.Type() != e.typ {
+			panic(fmt.Sprintf("config: enum value '%v' is a %v, expected %v", name, v.Type(), e.typ))
+		}
+
+		key := strings.ToLower(name)
+		if _, exists := e.values[key]; exists {
+			panic(fmt.Sprintf("config: duplicate enum name '%v'", name))
+		}
+		e.values[key] = v
+		e.names = append(e.names, name)
+	}
+	sort.Strings(e.names)
+	return e
+}
+
+// Unpack sets the value named by v into to, a pointer to the enum type. An
+// error listing the accepted names is returned if v is not a known name.
+func (e *EnumUnpacker) Unpack(to interface{}, v interface{}) error {
+	ptr := reflect.ValueOf(to)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() || ptr.Elem().Type() != e.typ {
+		return fmt.Errorf("can not unpack enum %v into %T", e.typ, to)
+	}
+
+	name, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("invalid value '%v', accepted values are: %v", v, strings.Join(e.names, ", "))
+	}
+	value, ok := e.values[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return fmt.Errorf("invalid value '%v', accepted values are: %v", name, strings.Join(e.names, ", "))
+	}
+	ptr.Elem().Set(value)
+	return nil
+}
+
+// Name returns the name of value, or its number if value is not a known
+// value of the enum.
+func (e *EnumUnpacker) Name(value interface{}) string {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || v.Type() != e.typ {
+		return fmt.Sprintf("%v", value)
+	}
+	for _, name := range e.names {
+		if e.values[strings.ToLower(name)].Interface() == value {
+			return name
+		}
+	}
+
+	// Don't format value with fmt, its String method may call Name.
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.String:
+		return v.String()
+	}
+	return fmt.Sprintf("%+v", v.Interface())
+}
+
+// Names returns the accepted names, sorted.
+func (e *EnumUnpacker) Names() []string {
+	return append([]string(nil), e.names...)
+}
@@ -0,0 +1,95 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testMode int
+
+const (
+	testModeFast testMode = iota + 1
+	testModeSafe
+)
+
+var testModes = NewEnumUnpacker(map[string]interface{}{
+	"fast": testModeFast,
+	"safe": testModeSafe,
+})
+
+func (m *testMode) Unpack(v interface{}) error { return testModes.Unpack(m, v) }
+
+func (m testMode) String() string { return testModes.Name(m) }
+
+func TestEnumUnpacker(t *testing.T) {
+	type settings struct {
+		Mode  testMode   `config:"mode"`
+		Modes []testMode `config:"modes"`
+	}
+
+	t.Run("known values", func(t *testing.T) {
+		var s settings
+		c := MustNewConfigFrom("mode: Safe\nmodes: [fast, safe]")
+		require.NoError(t, c.Unpack(&s))
+		assert.Equal(t, testModeSafe, s.Mode)
+		assert.Equal(t, []testMode{testModeFast, testModeSafe}, s.Modes)
+	})
+
+	t.Run("default value", func(t *testing.T) {
+		s := settings{Mode: testModeFast}
+		require.NoError(t, NewConfig().Unpack(&s))
+		assert.Equal(t, testModeFast, s.Mode)
+	})
+
+	for name, yaml := range map[string]string{
+		"unknown name": "mode: slow",
+		"number":       "mode: 1",
+	} {
+		t.Run(name, func(t *testing.T) {
+			var s settings
+			err := MustNewConfigFrom(yaml).Unpack(&s)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "accepted values are: fast, safe accessing 'mode'")
+		})
+	}
+
+	t.Run("names", func(t *testing.T) {
+		assert.Equal(t, "fast", testModeFast.String())
+		assert.Equal(t, "42", testMode(42).String())
+		assert.Equal(t, []string{"fast", "safe"}, testModes.Names())
+	})
+
+	t.Run("wrong target", func(t *testing.T) {
+		var i int
+		require.Error(t, testModes.Unpack(&i, "fast"))
+	})
+}
+
+func TestNewEnumUnpackerPanics(t *testing.T) {
+	assert.Panics(t, func() { NewEnumUnpacker(nil) })
+	assert.Panics(t, func() {
+		NewEnumUnpacker(map[string]interface{}{"fast": testModeFast, "other": 2})
+	})
+	assert.Panics(t, func() {
+		NewEnumUnpacker(map[string]interface{}{"fast": testModeFast, "FAST": testModeSafe})
+	})
+}
@@ -0,0 +1,134 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"math"
+)
+
+// Equal reports whether a and b hold the same settings, after resolving
+// variables. The order of the keys in a dictionary is ignored, the order of
+// the elements in an array is not. Numbers are equal if they represent the
+// same value, e.g. `1` and `1.0`, but numbers are never equal to strings.
+// Two nil configs are equal, configs failing to be resolved are never equal.
+func Equal(a, b *C) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	va, err := configValue(a)
+	if err != nil {
+		return false
+	}
+	vb, err := configValue(b)
+	if err != nil {
+		return false
+	}
+	return valuesEqual(va, vb)
+}
+
+// configValue returns the resolved settings of c. Empty configs are
+// returned as an empty dictionary.
+func configValue(c *C) (interface{}, error) {
+	if c.IsArray() {
+		var content []interface{}
+		err := c.Unpack(&content)
+		return content, err
+	}
+
+	content := map[string]interface{}{}
+	if c.IsDict() {
+		if err := c.Unpack(&content); err != nil {
+			return nil, err
+		}
+	}
+	return content, nil
+}
+
+func valuesEqual(a, b interface{}) bool {
+	switch va := a.(type) {
+	case map[string]interface{}:
+		vb, ok := b.(map[string]interface{})
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for k, v := range va {
+			other, found := vb[k]
+			if !found || !valuesEqual(v, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		vb, ok := b.([]interface{})
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for i := range va {
+			if !valuesEqual(va[i], vb[i]) {
+				return false
+			}
+		}
+		return true
+	case int64, uint64, float64:
+		return numbersEqual(a, b)
+	case nil:
+		return b == nil
+	default:
+		return a == b
+	}
+}
+
+// numbersEqual compares the integer and float values unpacked by ucfg.
+func numbersEqual(a, b interface{}) bool {
+	switch va := a.(type) {
+	case int64:
+		switch vb := b.(type) {
+		case int64:
+			return va == vb
+		case uint64:
+			return va >= 0 && uint64(va) == vb
+		case float64:
+			return floatEqualsInt(vb, va)
+		}
+	case uint64:
+		switch vb := b.(type) {
+		case uint64:
+			return va == vb
+		case int64, float64:
+			return numbersEqual(b, a)
+		}
+	case float64:
+		switch vb := b.(type) {
+		case int64:
+			return floatEqualsInt(va, vb)
+		case uint64:
+			return va == math.Trunc(va) && va >= 0 && va < math.MaxUint64 && uint64(va) == vb
+		case float64:
+			return va == vb
+		}
+	}
+	return false
+}
+
+func floatEqualsInt(f float64, i int64) bool {
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return false
+	}
+	return int64(f) == i
+}
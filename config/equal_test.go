@@ -0,0 +1,99 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEqual(t *testing.T) {
+	base := `
+output:
+  hosts: [a, b]
+  timeout: 10
+  ssl.enabled: true
+name: ${env.test}
+env: {test: beat}
+`
+	tests := map[string]struct {
+		other string
+		equal bool
+	}{
+		"same": {other: base, equal: true},
+		"reordered keys": {equal: true, other: `
+env.test: beat
+name: beat
+output:
+  ssl: {enabled: true}
+  timeout: 10.0
+  hosts: [a, b]
+`},
+		"changed value": {other: `
+output: {hosts: [a, b], timeout: 11, ssl.enabled: true}
+name: beat
+env.test: beat
+`},
+		"reordered list": {other: `
+output: {hosts: [b, a], timeout: 10, ssl.enabled: true}
+name: beat
+env.test: beat
+`},
+		"number as string": {other: `
+output: {hosts: [a, b], timeout: "10", ssl.enabled: true}
+name: beat
+env.test: beat
+`},
+		"additional key": {other: `
+output: {hosts: [a, b], timeout: 10, ssl.enabled: true, extra: 1}
+name: beat
+env.test: beat
+`},
+		"fractional number": {other: `
+output: {hosts: [a, b], timeout: 10.5, ssl.enabled: true}
+name: beat
+env.test: beat
+`},
+	}
+
+	a := MustNewConfigFrom(base)
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := MustNewConfigFrom(test.other)
+			assert.Equal(t, test.equal, Equal(a, b))
+			assert.Equal(t, test.equal, Equal(b, a))
+		})
+	}
+}
+
+func TestEqualSpecialCases(t *testing.T) {
+	assert.True(t, Equal(nil, nil))
+	assert.False(t, Equal(NewConfig(), nil))
+	assert.True(t, Equal(NewConfig(), MustNewConfigFrom(map[string]interface{}{})))
+	assert.True(t, Equal(MustNewConfigFrom([]interface{}{1, "a"}), MustNewConfigFrom("[1.0, a]")))
+	assert.False(t, Equal(MustNewConfigFrom("a: ${missing}"), MustNewConfigFrom("a: ${missing}")))
+}
+
+func TestNumbersEqual(t *testing.T) {
+	assert.True(t, numbersEqual(int64(3), uint64(3)))
+	assert.True(t, numbersEqual(uint64(3), float64(3)))
+	assert.False(t, numbersEqual(int64(-1), uint64(1<<63)))
+	assert.False(t, numbersEqual(float64(-1), uint64(1)))
+	assert.False(t, numbersEqual(float64(1.5), int64(1)))
+}
@@ -0,0 +1,126 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// configFileExtensions lists the extensions of the files loaded when a
+// directory is passed to LoadFiles.
+var configFileExtensions = []string{".yml", ".yaml"}
+
+// LoadFile reads the configuration file at path. Files ending in .json and
+// .toml are read as JSON and TOML, all others as YAML. In YAML files, values
+// tagged with `!include` are replaced by the contents of the referenced file,
+// relative paths are resolved against the directory of the including file.
+func LoadFile(path string) (*C, error) {
+	return LoadFileWithFormat(path, formatFromPath(path))
+}
+
+// LoadFileWithFormat reads the configuration file at path in the given
+// format, regardless of its extension.
+func LoadFileWithFormat(path string, format Format) (*C, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var includes []include
+	if format == FormatYAML {
+		content, includes, err = resolveIncludes(content, path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c, err := NewConfigWithFormat(content, path, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if err := setIncludeSources(c, includes); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// LoadFiles reads and merges the configuration files found at paths. A path
+// pointing to a directory loads all the YAML files it contains in
+// lexicographical order. Later files overwrite the settings of earlier ones.
+func LoadFiles(paths ...string) (*C, error) {
+	files, err := expandConfigPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	cfgs := make([]*C, 0, len(files))
+	for _, path := range files {
+		c, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		cfgs = append(cfgs, c)
+	}
+	return MergeConfigs(cfgs...)
+}
+
+// expandConfigPaths replaces the directories in paths with the configuration
+// files they contain.
+func expandConfigPaths(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		entries, err := ioutil.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		var dirFiles []string
+		for _, entry := range entries {
+			if entry.IsDir() || !isConfigFile(entry.Name()) {
+				continue
+			}
+			dirFiles = append(dirFiles, filepath.Join(path, entry.Name()))
+		}
+		sort.Strings(dirFiles)
+		files = append(files, dirFiles...)
+	}
+	return files, nil
+}
+
+func isConfigFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range configFileExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+
+	ucfg "github.com/elastic/go-ucfg"
+)
+
+// Format is the serialization format of a configuration file.
+type Format string
+
+// Supported configuration file formats.
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+	FormatTOML Format = "toml"
+)
+
+// formatFromPath detects the format of a configuration file from its
+// extension. Files with an unknown extension are read as YAML.
+func formatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".toml":
+		return FormatTOML
+	default:
+		return FormatYAML
+	}
+}
+
+// NewConfigWithFormat reads a configuration in the given format. The
+// returned configuration does not depend on the source format, variables are
+// resolved and configurations are merged the same way for all of them.
+func NewConfigWithFormat(in []byte, source string, format Format) (*C, error) {
+	switch format {
+	case FormatYAML:
+		return NewConfigWithYAML(in, source)
+	case FormatJSON:
+		return NewConfigWithJSON(in, source)
+	case FormatTOML:
+		return NewConfigWithTOML(in, source)
+	default:
+		return nil, fmt.Errorf("unsupported config format '%s'", format)
+	}
+}
+
+// NewConfigWithJSON reads a JSON configuration.
+func NewConfigWithJSON(in []byte, source string) (*C, error) {
+	dec := json.NewDecoder(bytes.NewReader(in))
+	dec.UseNumber()
+
+	var m map[string]interface{}
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	c, err := ucfg.NewFrom(normalizeNumbers(m), sourceOpts(source)...)
+	return fromConfig(c), err
+}
+
+// NewConfigWithTOML reads a TOML configuration.
+func NewConfigWithTOML(in []byte, source string) (*C, error) {
+	tree, err := toml.LoadBytes(in)
+	if err != nil {
+		return nil, err
+	}
+	c, err := ucfg.NewFrom(normalizeNumbers(tree.ToMap()), sourceOpts(source)...)
+	return fromConfig(c), err
+}
+
+// normalizeNumbers converts the numbers decoded from JSON and TOML to the
+// types used for YAML: uint64 for non-negative integers, int64 for negative
+// ones and float64 for all others. This makes the loaded configuration
+// independent of the source format.
+func normalizeNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, elem := range v {
+			v[k] = normalizeNumbers(elem)
+		}
+		return v
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = normalizeNumbers(elem)
+		}
+		return v
+	case []map[string]interface{}:
+		for _, elem := range v {
+			normalizeNumbers(elem)
+		}
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return normalizeNumbers(i)
+		}
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return u
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return v.String()
+		}
+		return f
+	case int64:
+		if v >= 0 {
+			return uint64(v)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func sourceOpts(source string) []ucfg.Option {
+	return append(
+		[]ucfg.Option{
+			ucfg.MetaData(ucfg.Meta{Source: source}),
+		},
+		configOpts...,
+	)
+}
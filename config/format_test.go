@@ -0,0 +1,125 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var formatTestFiles = map[string]string{
+	"config.yml": `
+cluster: production
+output:
+  elasticsearch:
+    index: logs-${cluster}
+    hosts: ["localhost:9200", "localhost:9201"]
+    bulk_max_size: 50
+    compression: true
+    backoff: 1.5
+    offset: -3
+`,
+	"config.json": `{
+  "cluster": "production",
+  "output": {
+    "elasticsearch": {
+      "index": "logs-${cluster}",
+      "hosts": ["localhost:9200", "localhost:9201"],
+      "bulk_max_size": 50,
+      "compression": true,
+      "backoff": 1.5,
+      "offset": -3
+    }
+  }
+}`,
+	"config.toml": `
+cluster = "production"
+
+[output.elasticsearch]
+index = "logs-${cluster}"
+hosts = ["localhost:9200", "localhost:9201"]
+bulk_max_size = 50
+compression = true
+backoff = 1.5
+offset = -3
+`,
+}
+
+func TestLoadFileFormats(t *testing.T) {
+	dir := t.TempDir()
+
+	var expected map[string]interface{}
+	for _, name := range []string{"config.yml", "config.json", "config.toml"} {
+		path := filepath.Join(dir, name)
+		writeConfigFile(t, path, formatTestFiles[name])
+
+		c, err := LoadFile(path)
+		require.NoError(t, err, name)
+
+		index, err := c.String("output.elasticsearch.index", -1)
+		require.NoError(t, err, name)
+		assert.Equal(t, "logs-production", index, name)
+
+		var out map[string]interface{}
+		require.NoError(t, c.Unpack(&out), name)
+		if expected == nil {
+			expected = out
+			continue
+		}
+		assert.Equal(t, expected, out, name)
+	}
+}
+
+func TestLoadFilesMixedFormats(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, filepath.Join(dir, "base.toml"), formatTestFiles["config.toml"])
+	writeConfigFile(t, filepath.Join(dir, "override.json"), `{"cluster": "staging", "output.elasticsearch.bulk_max_size": 100}`)
+
+	c, err := LoadFiles(filepath.Join(dir, "base.toml"), filepath.Join(dir, "override.json"))
+	require.NoError(t, err)
+
+	var out struct {
+		Output struct {
+			Elasticsearch struct {
+				Index       string `config:"index"`
+				BulkMaxSize int    `config:"bulk_max_size"`
+			} `config:"elasticsearch"`
+		} `config:"output"`
+	}
+	require.NoError(t, c.Unpack(&out))
+	assert.Equal(t, "logs-staging", out.Output.Elasticsearch.Index)
+	assert.Equal(t, 100, out.Output.Elasticsearch.BulkMaxSize)
+}
+
+func TestLoadFileWithFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.conf")
+	writeConfigFile(t, path, formatTestFiles["config.json"])
+
+	c, err := LoadFileWithFormat(path, FormatJSON)
+	require.NoError(t, err)
+	assert.True(t, c.HasField("output"))
+
+	_, err = LoadFileWithFormat(path, FormatTOML)
+	assert.Error(t, err)
+
+	_, err = LoadFileWithFormat(path, Format("ini"))
+	assert.Error(t, err)
+}
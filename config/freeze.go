@@ -0,0 +1,176 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	ucfg "github.com/elastic/go-ucfg"
+)
+
+// ErrFrozen is returned when trying to modify a frozen configuration.
+var ErrFrozen = errors.New("config is frozen")
+
+// Frozen is a read-only view of a configuration created by Freeze. Reading
+// and unpacking the configuration work as with C, Merge, Remove and the
+// SetXxx methods return ErrFrozen.
+type Frozen struct {
+	c *C
+}
+
+// Freeze returns a read-only view of c, the configuration can be passed
+// around without being modified accidentally. Children returned by Child and
+// ChildAt are frozen too.
+//
+// Freeze is a shallow guard against modifications through the view. c is not
+// copied, modifications done through c, or through configurations merged into
+// it before, are visible in the view.
+func (c *C) Freeze() *Frozen {
+	if c == nil {
+		return nil
+	}
+	return &Frozen{c: c}
+}
+
+// Copy returns a new configuration, that can be modified, with the content of
+// f.
+func (f *Frozen) Copy() (*C, error) {
+	return MergeConfigs(f.c)
+}
+
+func (f *Frozen) Unpack(to interface{}) error {
+	return f.c.Unpack(to)
+}
+
+// UnpackWithOptions unpacks the configuration like C.UnpackWithOptions.
+func (f *Frozen) UnpackWithOptions(to interface{}, opts ...UnpackOption) error {
+	return f.c.UnpackWithOptions(to, opts...)
+}
+
+func (f *Frozen) Path() string {
+	return f.c.Path()
+}
+
+func (f *Frozen) PathOf(field string) string {
+	return f.c.PathOf(field)
+}
+
+func (f *Frozen) Has(name string, idx int) (bool, error) {
+	return f.c.Has(name, idx)
+}
+
+func (f *Frozen) HasField(name string) bool {
+	return f.c.HasField(name)
+}
+
+func (f *Frozen) CountField(name string) (int, error) {
+	return f.c.CountField(name)
+}
+
+func (f *Frozen) Bool(name string, idx int) (bool, error) {
+	return f.c.Bool(name, idx)
+}
+
+func (f *Frozen) String(name string, idx int) (string, error) {
+	return f.c.String(name, idx)
+}
+
+func (f *Frozen) Int(name string, idx int) (int64, error) {
+	return f.c.Int(name, idx)
+}
+
+func (f *Frozen) Float(name string, idx int) (float64, error) {
+	return f.c.Float(name, idx)
+}
+
+func (f *Frozen) Child(name string, idx int) (*Frozen, error) {
+	sub, err := f.c.Child(name, idx)
+	return sub.Freeze(), err
+}
+
+// ChildAt returns the frozen sub-configuration at the dotted path like
+// C.ChildAt.
+func (f *Frozen) ChildAt(path string) (*Frozen, error) {
+	sub, err := f.c.ChildAt(path)
+	return sub.Freeze(), err
+}
+
+// GetValue returns the value at the dotted path like C.GetValue.
+func (f *Frozen) GetValue(path string) (interface{}, error) {
+	return f.c.GetValue(path)
+}
+
+func (f *Frozen) IsDict() bool {
+	return f.c.IsDict()
+}
+
+func (f *Frozen) IsArray() bool {
+	return f.c.IsArray()
+}
+
+// FlattenedKeys return a sorted flattened views of the set keys in the configuration.
+func (f *Frozen) FlattenedKeys() []string {
+	return f.c.FlattenedKeys()
+}
+
+// Enabled return the configured enabled value or true by default.
+func (f *Frozen) Enabled() bool {
+	return f.c.Enabled()
+}
+
+// GetFields returns the list of fields in the configuration.
+func (f *Frozen) GetFields() []string {
+	return f.c.GetFields()
+}
+
+func (f *Frozen) Merge(interface{}) error {
+	return frozenError("merge")
+}
+
+func (f *Frozen) MergeWithOpts(from interface{}, opts ...ucfg.Option) error {
+	return frozenError("merge")
+}
+
+func (f *Frozen) Remove(name string, idx int) (bool, error) {
+	return false, frozenError("remove '" + name + "'")
+}
+
+func (f *Frozen) SetBool(name string, idx int, value bool) error {
+	return frozenError("set '" + name + "'")
+}
+
+func (f *Frozen) SetInt(name string, idx int, value int64) error {
+	return frozenError("set '" + name + "'")
+}
+
+func (f *Frozen) SetFloat(name string, idx int, value float64) error {
+	return frozenError("set '" + name + "'")
+}
+
+func (f *Frozen) SetString(name string, idx int, value string) error {
+	return frozenError("set '" + name + "'")
+}
+
+func (f *Frozen) SetChild(name string, idx int, value *C) error {
+	return frozenError("set '" + name + "'")
+}
+
+func frozenError(op string) error {
+	return fmt.Errorf("cannot %v: %w", op, ErrFrozen)
+}
@@ -0,0 +1,98 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreeze(t *testing.T) {
+	c := MustNewConfigFrom(map[string]interface{}{
+		"name": "test",
+		"output": map[string]interface{}{
+			"hosts": []string{"localhost:9200"},
+		},
+	})
+	frozen := c.Freeze()
+
+	mutations := map[string]func(f *Frozen) error{
+		"Merge":         func(f *Frozen) error { return f.Merge(map[string]interface{}{"name": "other"}) },
+		"MergeWithOpts": func(f *Frozen) error { return f.MergeWithOpts(map[string]interface{}{"name": "other"}) },
+		"SetBool":       func(f *Frozen) error { return f.SetBool("enabled", -1, true) },
+		"SetInt":        func(f *Frozen) error { return f.SetInt("count", -1, 1) },
+		"SetFloat":      func(f *Frozen) error { return f.SetFloat("ratio", -1, 0.5) },
+		"SetString":     func(f *Frozen) error { return f.SetString("name", -1, "other") },
+		"SetChild":      func(f *Frozen) error { return f.SetChild("sub", -1, NewConfig()) },
+		"Remove": func(f *Frozen) error {
+			_, err := f.Remove("name", -1)
+			return err
+		},
+	}
+	for name, mutate := range mutations {
+		t.Run(name, func(t *testing.T) {
+			err := mutate(frozen)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrFrozen), err)
+		})
+	}
+
+	// reads still work and the content is unchanged
+	var settings struct {
+		Name   string `config:"name"`
+		Output struct {
+			Hosts []string `config:"hosts"`
+		} `config:"output"`
+	}
+	require.NoError(t, frozen.Unpack(&settings))
+	assert.Equal(t, "test", settings.Name)
+	assert.Equal(t, []string{"localhost:9200"}, settings.Output.Hosts)
+	assert.False(t, frozen.HasField("enabled"))
+	assert.False(t, frozen.HasField("sub"))
+
+	// children are frozen too
+	output, err := frozen.Child("output", -1)
+	require.NoError(t, err)
+	assert.Equal(t, "output", output.Path())
+	assert.True(t, errors.Is(output.SetString("index", -1, "logs"), ErrFrozen))
+	_, err = frozen.Child("missing", -1)
+	require.Error(t, err)
+
+	// a copy can be modified without changing the frozen configuration
+	copied, err := frozen.Copy()
+	require.NoError(t, err)
+	assert.NoError(t, copied.SetString("name", -1, "other"))
+	name, err := frozen.String("name", -1)
+	require.NoError(t, err)
+	assert.Equal(t, "test", name)
+}
+
+func TestFreezeDoesNotAffectTheConfig(t *testing.T) {
+	c := NewConfig()
+	frozen := c.Freeze()
+	assert.NoError(t, c.SetString("name", -1, "test"))
+
+	// the view is shallow, the modifications of c are visible.
+	name, err := frozen.String("name", -1)
+	require.NoError(t, err)
+	assert.Equal(t, "test", name)
+	assert.Nil(t, (*C)(nil).Freeze())
+}
@@ -0,0 +1,88 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// GlobOption configures the behavior of ExpandGlobs.
+type GlobOption func(*globOptions)
+
+type globOptions struct {
+	allowNoMatch bool
+	onNoMatch    func(pattern string)
+}
+
+// AllowNoMatch makes ExpandGlobs skip the patterns not matching any file
+// instead of failing. fn, if not nil, is called with each of these patterns,
+// e.g. to log a warning.
+func AllowNoMatch(fn func(pattern string)) GlobOption {
+	return func(o *globOptions) {
+		o.allowNoMatch = true
+		o.onNoMatch = fn
+	}
+}
+
+// ExpandGlobs returns the files matching patterns, sorted and without
+// duplicates. Patterns use the syntax of filepath.Match, patterns without
+// wildcards match the file they name. Directories are not returned. An error
+// is returned if a pattern is malformed or, unless AllowNoMatch is used, does
+// not match any file.
+func ExpandGlobs(patterns []string, opts ...GlobOption) ([]string, error) {
+	var o globOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	seen := map[string]struct{}{}
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path pattern '%v': %w", pattern, err)
+		}
+
+		found := false
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			found = true
+			if _, dup := seen[match]; !dup {
+				seen[match] = struct{}{}
+				files = append(files, match)
+			}
+		}
+		if found {
+			continue
+		}
+		if !o.allowNoMatch {
+			return nil, fmt.Errorf("no file matches the path pattern '%v'", pattern)
+		}
+		if o.onNoMatch != nil {
+			o.onNoMatch(pattern)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
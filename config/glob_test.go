@@ -0,0 +1,70 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandGlobs(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "archive.log"), 0700))
+	for _, name := range []string{"b.log", "a.log", "c.txt"} {
+		writeConfigFile(t, filepath.Join(dir, name), "")
+	}
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	t.Run("matching patterns", func(t *testing.T) {
+		files, err := ExpandGlobs([]string{path("*.log"), path("a.*"), path("c.txt")})
+		require.NoError(t, err)
+		assert.Equal(t, []string{path("a.log"), path("b.log"), path("c.txt")}, files)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		for _, pattern := range []string{path("*.json"), path("missing.log"), path("archive.*")} {
+			_, err := ExpandGlobs([]string{path("*.log"), pattern})
+			require.Error(t, err, pattern)
+			assert.Contains(t, err.Error(), "no file matches the path pattern '"+pattern+"'")
+		}
+	})
+
+	t.Run("allow no match", func(t *testing.T) {
+		var skipped []string
+		files, err := ExpandGlobs([]string{path("*.json"), path("b.*")}, AllowNoMatch(func(pattern string) {
+			skipped = append(skipped, pattern)
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, []string{path("b.log")}, files)
+		assert.Equal(t, []string{path("*.json")}, skipped)
+
+		files, err = ExpandGlobs([]string{path("*.json")}, AllowNoMatch(nil))
+		require.NoError(t, err)
+		assert.Empty(t, files)
+	})
+
+	t.Run("malformed pattern", func(t *testing.T) {
+		_, err := ExpandGlobs([]string{path("[")}, AllowNoMatch(nil))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid path pattern")
+	})
+}
@@ -0,0 +1,159 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includeTag is the YAML tag used to inline the contents of another file,
+// e.g. `output: !include output.yml`. Relative paths are resolved against the
+// directory of the including file.
+const includeTag = "!include"
+
+// include is a dictionary read from an included file.
+type include struct {
+	key    string // dotted key of the dictionary, empty for the root
+	source string // path of the included file
+	node   *yaml.Node
+}
+
+// resolveIncludes replaces every node tagged with includeTag in the YAML
+// document content by the contents of the referenced file. path is the
+// location of the document and is used to resolve relative includes. The
+// included dictionaries that are not in a list are returned as well, in the
+// order they have been included.
+func resolveIncludes(content []byte, path string) ([]byte, []include, error) {
+	if !bytes.Contains(content, []byte(includeTag)) {
+		return content, nil, nil
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, nil, err
+	}
+	var includes []include
+	if err := expandIncludes(&doc, abs, []string{abs}, "", &includes); err != nil {
+		return nil, nil, err
+	}
+	out, err := yaml.Marshal(&doc)
+	return out, includes, err
+}
+
+// expandIncludes expands the includes below node, whose dotted key is key.
+// The included dictionaries are appended to includes, unless includes is nil
+// as it is in lists.
+func expandIncludes(node *yaml.Node, path string, stack []string, key string, includes *[]include) error {
+	if node.Tag != includeTag {
+		for i, child := range node.Content {
+			childKey, childIncludes := key, includes
+			switch node.Kind {
+			case yaml.MappingNode:
+				if i%2 == 0 {
+					continue
+				}
+				childKey = node.Content[i-1].Value
+				if key != "" {
+					childKey = key + "." + childKey
+				}
+			case yaml.SequenceNode:
+				childIncludes = nil
+			}
+			if err := expandIncludes(child, path, stack, childKey, childIncludes); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if node.Kind != yaml.ScalarNode || node.Value == "" {
+		return fmt.Errorf("%s in %s (line %d) requires a file path", includeTag, path, node.Line)
+	}
+
+	target := node.Value
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(path), target)
+	}
+	target = filepath.Clean(target)
+	for _, p := range stack {
+		if p == target {
+			return fmt.Errorf("circular %s of %s in %s", includeTag, target, path)
+		}
+	}
+
+	content, err := ioutil.ReadFile(target)
+	if err != nil {
+		return fmt.Errorf("failed to include file from %s: %w", path, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return fmt.Errorf("failed to parse included file %s: %w", target, err)
+	}
+	if len(doc.Content) == 0 {
+		*node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+		return nil
+	}
+
+	included := doc.Content[0]
+	if includes != nil && included.Kind == yaml.MappingNode {
+		*includes = append(*includes, include{key: key, source: target, node: included})
+	}
+	nested := make([]string, len(stack), len(stack)+1)
+	copy(nested, stack)
+	if err := expandIncludes(included, target, append(nested, target), key, includes); err != nil {
+		return err
+	}
+	*node = *included
+	return nil
+}
+
+// setIncludeSources records the included files as the source of the values
+// they define in c. c must have been created from the document the includes
+// have been expanded into, so merging them only updates the sources.
+func setIncludeSources(c *C, includes []include) error {
+	for _, inc := range includes {
+		content, err := yaml.Marshal(inc.node)
+		if err != nil {
+			return err
+		}
+		sub, err := NewConfigWithYAML(content, inc.source)
+		if err != nil {
+			return fmt.Errorf("failed to parse included file %s: %w", inc.source, err)
+		}
+
+		var from interface{} = sub
+		if inc.key != "" {
+			from = map[string]interface{}{inc.key: sub}
+		}
+		if err := c.Merge(from); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFileInclude(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "outputs"), 0700))
+
+	writeConfigFile(t, filepath.Join(dir, "main.yml"), `
+cluster: production
+output: !include outputs/es.yml
+`)
+	writeConfigFile(t, filepath.Join(dir, "outputs", "es.yml"), `
+elasticsearch:
+  index: logs-${cluster}
+  ssl: !include ssl.yml
+`)
+	writeConfigFile(t, filepath.Join(dir, "outputs", "ssl.yml"), `
+verification_mode: full
+`)
+
+	c, err := LoadFile(filepath.Join(dir, "main.yml"))
+	require.NoError(t, err)
+
+	var out struct {
+		Output struct {
+			Elasticsearch struct {
+				Index string `config:"index"`
+				SSL   struct {
+					VerificationMode string `config:"verification_mode"`
+				} `config:"ssl"`
+			} `config:"elasticsearch"`
+		} `config:"output"`
+	}
+	require.NoError(t, c.Unpack(&out))
+	assert.Equal(t, "logs-production", out.Output.Elasticsearch.Index)
+	assert.Equal(t, "full", out.Output.Elasticsearch.SSL.VerificationMode)
+}
+
+func TestLoadFileIncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, filepath.Join(dir, "a.yml"), "b: !include b.yml\n")
+	writeConfigFile(t, filepath.Join(dir, "b.yml"), "a: !include a.yml\n")
+
+	_, err := LoadFile(filepath.Join(dir, "a.yml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "circular !include")
+}
+
+func TestLoadFileIncludeMissing(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, filepath.Join(dir, "a.yml"), "b: !include missing.yml\n")
+
+	_, err := LoadFile(filepath.Join(dir, "a.yml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,145 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+var (
+	lazySecretOnce sync.Once
+	lazySecretAEAD cipher.AEAD
+	lazySecretErr  error
+)
+
+// lazySecretCipher returns the cipher encrypting the lazy secrets, its key is
+// generated once per process and never leaves the memory.
+func lazySecretCipher() (cipher.AEAD, error) {
+	lazySecretOnce.Do(func() {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			lazySecretErr = fmt.Errorf("failed to generate the secrets key: %w", err)
+			return
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			lazySecretErr = err
+			return
+		}
+		lazySecretAEAD, lazySecretErr = cipher.NewGCM(block)
+	})
+	return lazySecretAEAD, lazySecretErr
+}
+
+// LazySecret is a configuration value holding sensitive data, like Secret,
+// that stays encrypted in memory until it is used. The value is encrypted
+// with a key generated for the process and is only decrypted for the
+// duration of a call to Reveal. The value is masked whenever the secret is
+// printed or serialized to JSON or YAML.
+//
+// The configuration the secret is unpacked from still holds the plain value,
+// LazySecret limits the copies kept by the unpacked settings.
+type LazySecret struct {
+	nonce  []byte
+	sealed []byte
+}
+
+// NewLazySecret creates a LazySecret holding value. value is not modified,
+// the caller can clear it once the secret is created.
+func NewLazySecret(value []byte) (LazySecret, error) {
+	if len(value) == 0 {
+		return LazySecret{}, nil
+	}
+	aead, err := lazySecretCipher()
+	if err != nil {
+		return LazySecret{}, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return LazySecret{}, fmt.Errorf("failed to encrypt the secret: %w", err)
+	}
+	return LazySecret{nonce: nonce, sealed: aead.Seal(nil, nonce, value, nil)}, nil
+}
+
+// Unpack implements ucfg.Unpacker. Numbers and booleans are accepted and
+// stored in their string representation.
+func (s *LazySecret) Unpack(v interface{}) error {
+	var value string
+	switch val := v.(type) {
+	case string:
+		value = val
+	case int64, uint64, float64, bool:
+		value = fmt.Sprint(val)
+	default:
+		return fmt.Errorf("invalid secret: unsupported type %T", v)
+	}
+
+	plain := []byte(value)
+	defer zero(plain)
+	secret, err := NewLazySecret(plain)
+	if err != nil {
+		return err
+	}
+	*s = secret
+	return nil
+}
+
+// Reveal decrypts the secret and calls fn with its value. The value is
+// cleared when fn returns, fn must not retain it. The error returned by fn
+// is returned as is.
+func (s LazySecret) Reveal(fn func(value []byte) error) error {
+	if !s.IsSet() {
+		return fn(nil)
+	}
+	aead, err := lazySecretCipher()
+	if err != nil {
+		return err
+	}
+	plain, err := aead.Open(nil, s.nonce, s.sealed, nil)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt the secret: %w", err)
+	}
+	defer zero(plain)
+	return fn(plain)
+}
+
+// IsSet returns true if the secret is not empty.
+func (s LazySecret) IsSet() bool { return len(s.sealed) != 0 }
+
+// String returns the masked value.
+func (s LazySecret) String() string { return secretMask }
+
+// GoString returns the masked value, so %#v does not print the secret.
+func (s LazySecret) GoString() string { return secretMask }
+
+// MarshalJSON implements json.Marshaler, returning the masked value.
+func (s LazySecret) MarshalJSON() ([]byte, error) { return json.Marshal(secretMask) }
+
+// MarshalYAML implements yaml.Marshaler, returning the masked value.
+func (s LazySecret) MarshalYAML() (interface{}, error) { return secretMask, nil }
+
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,117 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func revealLazySecret(t *testing.T, s LazySecret) string {
+	t.Helper()
+	var value string
+	require.NoError(t, s.Reveal(func(b []byte) error {
+		value = string(b)
+		return nil
+	}))
+	return value
+}
+
+func TestLazySecretUnpack(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "token")
+	require.NoError(t, ioutil.WriteFile(filename, []byte("s3cr3t\n"), 0o600))
+
+	c := MustNewConfigFrom(map[string]interface{}{
+		"password":   "hunter2",
+		"pin":        1234,
+		"token_file": filename,
+	})
+	var settings struct {
+		Password LazySecret `config:"password"`
+		Pin      LazySecret `config:"pin"`
+		Token    LazySecret `config:"token"`
+		Missing  LazySecret `config:"missing"`
+	}
+	require.NoError(t, c.Unpack(&settings))
+	assert.Equal(t, "hunter2", revealLazySecret(t, settings.Password))
+	assert.Equal(t, "1234", revealLazySecret(t, settings.Pin))
+	assert.Equal(t, "s3cr3t", revealLazySecret(t, settings.Token))
+	assert.True(t, settings.Password.IsSet())
+	assert.False(t, settings.Missing.IsSet())
+	assert.Equal(t, "", revealLazySecret(t, settings.Missing))
+
+	assert.Error(t, MustNewConfigFrom(map[string]interface{}{"password": []string{"a"}}).Unpack(&settings))
+}
+
+func TestLazySecretIsEncrypted(t *testing.T) {
+	const plain = "hunter2-correct-horse"
+	var settings struct {
+		Password LazySecret `config:"password" json:"password" yaml:"password"`
+	}
+	require.NoError(t, MustNewConfigFrom(map[string]interface{}{"password": plain}).Unpack(&settings))
+
+	// The fields of the secret do not hold the plain value.
+	v := reflect.ValueOf(settings.Password)
+	for i := 0; i < v.NumField(); i++ {
+		assert.False(t, bytes.Contains(v.Field(i).Bytes(), []byte(plain)), v.Type().Field(i).Name)
+	}
+	// Two secrets with the same value are encrypted differently.
+	other, err := NewLazySecret([]byte(plain))
+	require.NoError(t, err)
+	assert.NotEqual(t, settings.Password.sealed, other.sealed)
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		assert.NotContains(t, fmt.Sprintf(format, settings), plain)
+	}
+	out, err := json.Marshal(settings)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"password": "***"}`, string(out))
+	yml, err := yaml.Marshal(settings)
+	require.NoError(t, err)
+	assert.Equal(t, "password: '***'\n", string(yml))
+}
+
+func TestLazySecretReveal(t *testing.T) {
+	s, err := NewLazySecret([]byte("hunter2"))
+	require.NoError(t, err)
+
+	var revealed []byte
+	require.NoError(t, s.Reveal(func(value []byte) error {
+		assert.Equal(t, "hunter2", string(value))
+		revealed = value
+		return nil
+	}))
+	// The value is cleared once used.
+	assert.Equal(t, make([]byte, len("hunter2")), revealed)
+	// The secret can be revealed again.
+	assert.Equal(t, "hunter2", revealLazySecret(t, s))
+
+	errUse := errors.New("failed")
+	assert.Equal(t, errUse, s.Reveal(func([]byte) error { return errUse }))
+}
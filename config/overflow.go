@@ -0,0 +1,195 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	ucfg "github.com/elastic/go-ucfg"
+	"github.com/joeshaw/multierror"
+)
+
+// withIntegerRanges returns the integer range errors of c if err is an
+// integer conversion error of go-ucfg, which does not name the range of
+// the field. Other errors are returned as is.
+func withIntegerRanges(err error, c *C, to interface{}) error {
+	var uerr ucfg.Error
+	if !errors.As(err, &uerr) || !isIntegerConversion(uerr.Reason()) {
+		return err
+	}
+	if rangeErr := checkIntegerRanges(c, to); rangeErr != nil {
+		return rangeErr
+	}
+	return err
+}
+
+func isIntegerConversion(reason error) bool {
+	return errors.Is(reason, ucfg.ErrOverflow) || errors.Is(reason, ucfg.ErrNegative)
+}
+
+// checkIntegerRanges returns an error for every integer setting of c not
+// fitting the sized integer field of to it is unpacked into. The error names
+// the setting and the range of the field.
+func checkIntegerRanges(c *C, to interface{}) error {
+	t := reflect.TypeOf(to)
+	if t == nil {
+		return nil
+	}
+
+	var errs multierror.Errors
+	checkStructRanges(c, chaseType(t), &errs)
+	return errs.Err()
+}
+
+func checkStructRanges(c *C, t reflect.Type, errs *multierror.Errors) {
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name, inline, ignore := parseConfigTag(field)
+		if ignore {
+			continue
+		}
+		ft := chaseType(field.Type)
+		if inline {
+			checkStructRanges(c, ft, errs)
+			continue
+		}
+		if !c.HasField(name) || hasCustomUnpack(ft) {
+			continue
+		}
+
+		switch {
+		case isSizedInteger(ft):
+			if err := checkIntegerRange(c, name, -1, ft); err != nil {
+				*errs = append(*errs, err)
+			}
+
+		case ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array:
+			elem := chaseType(ft.Elem())
+			n, err := c.CountField(name)
+			if err != nil {
+				continue
+			}
+			for j := 0; j < n; j++ {
+				if isSizedInteger(elem) && !hasCustomUnpack(elem) {
+					if err := checkIntegerRange(c, name, j, elem); err != nil {
+						*errs = append(*errs, err)
+					}
+				} else if sub, err := c.Child(name, j); err == nil {
+					checkStructRanges(sub, elem, errs)
+				}
+			}
+
+		case ft.Kind() == reflect.Struct:
+			if sub, err := c.Child(name, -1); err == nil {
+				checkStructRanges(sub, ft, errs)
+			}
+		}
+	}
+}
+
+// checkIntegerRange checks the value of the setting name, or of its idx-th
+// element if idx >= 0, fits in the integer type t. Values that are not
+// integers are left to the unpacker.
+func checkIntegerRange(c *C, name string, idx int, t reflect.Type) error {
+	bits := t.Bits()
+	signed := isSignedInteger(t)
+
+	var (
+		value   string
+		inRange bool
+	)
+	if i, err := c.access().Int(name, idx, configOpts...); err == nil {
+		value = strconv.FormatInt(i, 10)
+		if signed {
+			inRange = i >= minInt(bits) && i <= maxInt(bits)
+		} else {
+			inRange = i >= 0 && uint64(i) <= maxUint(bits)
+		}
+	} else if u, err := c.access().Uint(name, idx, configOpts...); err == nil {
+		value = strconv.FormatUint(u, 10)
+		if signed {
+			inRange = u <= uint64(maxInt(bits))
+		} else {
+			inRange = u <= maxUint(bits)
+		}
+	} else {
+		return nil
+	}
+	if inRange {
+		return nil
+	}
+
+	path := c.PathOf(name)
+	if idx >= 0 {
+		path = fmt.Sprintf("%v.%d", path, idx)
+	}
+	if signed {
+		return fmt.Errorf("value %v out of range for %v [%d, %d] accessing '%v'", value, t.Kind(), minInt(bits), maxInt(bits), path)
+	}
+	return fmt.Errorf("value %v out of range for %v [0, %d] accessing '%v'", value, t.Kind(), maxUint(bits), path)
+}
+
+func isSizedInteger(t reflect.Type) bool {
+	if t == tDuration {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func isSignedInteger(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+// hasCustomUnpack reports whether the values of type t are unpacked by their
+// own Unpack method.
+func hasCustomUnpack(t reflect.Type) bool {
+	_, ok := reflect.PtrTo(t).MethodByName("Unpack")
+	return ok
+}
+
+func minInt(bits int) int64 { return -1 << (bits - 1) }
+
+func maxInt(bits int) int64 { return 1<<(bits-1) - 1 }
+
+func maxUint(bits int) uint64 {
+	if bits == 64 {
+		return math.MaxUint64
+	}
+	return 1<<bits - 1
+}
@@ -0,0 +1,90 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnpackIntegerOverflow(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		target func() interface{}
+		err    string
+	}{
+		{"int8 max", "127", func() interface{} { return &struct{ V int8 }{} }, ""},
+		{"int8 overflow", "128", func() interface{} { return &struct{ V int8 }{} }, "value 128 out of range for int8 [-128, 127] accessing 'v'"},
+		{"int8 underflow", "-129", func() interface{} { return &struct{ V int8 }{} }, "value -129 out of range for int8 [-128, 127] accessing 'v'"},
+		{"int16 overflow", "32768", func() interface{} { return &struct{ V int16 }{} }, "value 32768 out of range for int16 [-32768, 32767] accessing 'v'"},
+		{"int32 min", "-2147483648", func() interface{} { return &struct{ V int32 }{} }, ""},
+		{"int32 overflow", "4000000000", func() interface{} { return &struct{ V int32 }{} }, "value 4000000000 out of range for int32 [-2147483648, 2147483647] accessing 'v'"},
+		{"int64 overflow", "9223372036854775808", func() interface{} { return &struct{ V int64 }{} }, "value 9223372036854775808 out of range for int64 [-9223372036854775808, 9223372036854775807] accessing 'v'"},
+		{"uint8 max", "255", func() interface{} { return &struct{ V uint8 }{} }, ""},
+		{"uint8 overflow", "256", func() interface{} { return &struct{ V uint8 }{} }, "value 256 out of range for uint8 [0, 255] accessing 'v'"},
+		{"uint8 underflow", "-1", func() interface{} { return &struct{ V uint8 }{} }, "value -1 out of range for uint8 [0, 255] accessing 'v'"},
+		{"uint16 overflow", "65536", func() interface{} { return &struct{ V uint16 }{} }, "value 65536 out of range for uint16 [0, 65535] accessing 'v'"},
+		{"uint32 overflow", "4294967296", func() interface{} { return &struct{ V uint32 }{} }, "value 4294967296 out of range for uint32 [0, 4294967295] accessing 'v'"},
+		{"uint64 max", "18446744073709551615", func() interface{} { return &struct{ V uint64 }{} }, ""},
+		{"uint64 underflow", "-1", func() interface{} { return &struct{ V uint64 }{} }, "value -1 out of range for uint64 [0, 18446744073709551615] accessing 'v'"},
+		{"pointer", "300", func() interface{} { return &struct{ V *uint8 }{} }, "value 300 out of range for uint8 [0, 255] accessing 'v'"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := NewConfigWithYAML([]byte("v: "+test.value), "test")
+			require.NoError(t, err)
+
+			err = c.Unpack(test.target())
+			if test.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestUnpackIntegerOverflowNested(t *testing.T) {
+	c := MustNewConfigFrom(`
+queue:
+  workers: 70000
+  sizes: [1, 300]
+outputs:
+  - {port: 65536}
+`)
+	var settings struct {
+		Queue struct {
+			Workers uint16  `config:"workers"`
+			Sizes   []uint8 `config:"sizes"`
+		} `config:"queue"`
+		Outputs []struct {
+			Port uint16 `config:"port"`
+		} `config:"outputs"`
+	}
+
+	err := c.UnpackWithOptions(&settings, ValidateAll())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "value 70000 out of range for uint16 [0, 65535] accessing 'queue.workers'")
+	assert.Contains(t, err.Error(), "value 300 out of range for uint8 [0, 255] accessing 'queue.sizes.1'")
+	assert.Contains(t, err.Error(), "value 65536 out of range for uint16 [0, 65535] accessing 'outputs.0.port'")
+}
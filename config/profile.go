@@ -0,0 +1,95 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// profileBasedOnKey is the setting a profile uses to inherit the settings of
+// another profile.
+const profileBasedOnKey = "based_on"
+
+// ApplyProfile merges the named profile beneath cfg: settings defined in cfg
+// take precedence over the ones of the profile. A profile can inherit from
+// another one by setting `based_on` to its name, settings of the inheriting
+// profile take precedence over the ones of its base. Unknown profile names and
+// circular inheritance are reported as errors. An empty profile name returns
+// cfg unchanged.
+func ApplyProfile(cfg *C, profile string, profiles map[string]*C) (*C, error) {
+	if profile == "" {
+		return cfg, nil
+	}
+
+	chain, err := resolveProfileChain(profile, profiles)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := NewConfig()
+	for i := len(chain) - 1; i >= 0; i-- {
+		if err := merged.Merge(profiles[chain[i]]); err != nil {
+			return nil, fmt.Errorf("failed to merge profile '%v': %w", chain[i], err)
+		}
+	}
+	if merged.HasField(profileBasedOnKey) {
+		if _, err := merged.Remove(profileBasedOnKey, -1); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg != nil {
+		if err := merged.Merge(cfg); err != nil {
+			return nil, fmt.Errorf("failed to apply profile '%v': %w", profile, err)
+		}
+	}
+	return merged, nil
+}
+
+// resolveProfileChain returns the names of profile and of all the profiles it
+// is based on, starting with profile itself.
+func resolveProfileChain(profile string, profiles map[string]*C) ([]string, error) {
+	var chain []string
+	for name := profile; name != ""; {
+		for _, seen := range chain {
+			if seen == name {
+				return nil, fmt.Errorf("circular profile inheritance: %v -> %v", strings.Join(chain, " -> "), name)
+			}
+		}
+
+		p, ok := profiles[name]
+		if !ok || p == nil {
+			if len(chain) == 0 {
+				return nil, fmt.Errorf("unknown profile '%v'", name)
+			}
+			return nil, fmt.Errorf("unknown profile '%v' used as %v in profile '%v'", name, profileBasedOnKey, chain[len(chain)-1])
+		}
+		chain = append(chain, name)
+
+		name = ""
+		if p.HasField(profileBasedOnKey) {
+			base, err := p.String(profileBasedOnKey, -1)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %v in profile '%v': %w", profileBasedOnKey, chain[len(chain)-1], err)
+			}
+			name = base
+		}
+	}
+	return chain, nil
+}
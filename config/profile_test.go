@@ -0,0 +1,136 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testProfiles() map[string]*C {
+	return map[string]*C{
+		"base": MustNewConfigFrom(map[string]interface{}{
+			"logging.level":       "info",
+			"output.bulk_size":    50,
+			"output.compression":  0,
+			"monitoring.enabled":  false,
+			"monitoring.interval": "30s",
+		}),
+		"dev": MustNewConfigFrom(map[string]interface{}{
+			"based_on":           "base",
+			"logging.level":      "debug",
+			"monitoring.enabled": true,
+		}),
+		"dev-local": MustNewConfigFrom(map[string]interface{}{
+			"based_on":         "dev",
+			"output.bulk_size": 10,
+		}),
+		"prod": MustNewConfigFrom(map[string]interface{}{
+			"based_on":           "base",
+			"output.compression": 3,
+		}),
+	}
+}
+
+type profileSettings struct {
+	Logging struct {
+		Level string `config:"level"`
+	} `config:"logging"`
+	Output struct {
+		BulkSize    int `config:"bulk_size"`
+		Compression int `config:"compression"`
+	} `config:"output"`
+	Monitoring struct {
+		Enabled  bool   `config:"enabled"`
+		Interval string `config:"interval"`
+	} `config:"monitoring"`
+}
+
+func TestApplyProfile(t *testing.T) {
+	profiles := testProfiles()
+	cfg := MustNewConfigFrom(map[string]interface{}{
+		"logging.level":       "warning",
+		"monitoring.interval": "10s",
+	})
+
+	merged, err := ApplyProfile(cfg, "prod", profiles)
+	require.NoError(t, err)
+
+	var s profileSettings
+	require.NoError(t, merged.Unpack(&s))
+	assert.Equal(t, "warning", s.Logging.Level)
+	assert.Equal(t, 50, s.Output.BulkSize)
+	assert.Equal(t, 3, s.Output.Compression)
+	assert.False(t, s.Monitoring.Enabled)
+	assert.Equal(t, "10s", s.Monitoring.Interval)
+	assert.False(t, merged.HasField("based_on"))
+
+	// inputs are not modified
+	assert.False(t, cfg.HasField("output"))
+	assert.True(t, profiles["prod"].HasField("based_on"))
+}
+
+func TestApplyProfileInheritanceChain(t *testing.T) {
+	merged, err := ApplyProfile(NewConfig(), "dev-local", testProfiles())
+	require.NoError(t, err)
+
+	var s profileSettings
+	require.NoError(t, merged.Unpack(&s))
+	assert.Equal(t, "debug", s.Logging.Level)
+	assert.Equal(t, 10, s.Output.BulkSize)
+	assert.Equal(t, 0, s.Output.Compression)
+	assert.True(t, s.Monitoring.Enabled)
+	assert.Equal(t, "30s", s.Monitoring.Interval)
+	assert.False(t, merged.HasField("based_on"))
+}
+
+func TestApplyProfileErrors(t *testing.T) {
+	cycle := map[string]*C{
+		"a": MustNewConfigFrom(map[string]interface{}{"based_on": "b"}),
+		"b": MustNewConfigFrom(map[string]interface{}{"based_on": "c"}),
+		"c": MustNewConfigFrom(map[string]interface{}{"based_on": "a"}),
+		"d": MustNewConfigFrom(map[string]interface{}{"based_on": "d"}),
+		"e": MustNewConfigFrom(map[string]interface{}{"based_on": "missing"}),
+	}
+
+	tests := map[string]struct {
+		profile string
+		err     string
+	}{
+		"unknown profile":      {profile: "missing", err: "unknown profile 'missing'"},
+		"unknown base":         {profile: "e", err: "unknown profile 'missing' used as based_on in profile 'e'"},
+		"circular inheritance": {profile: "a", err: "circular profile inheritance: a -> b -> c -> a"},
+		"based on itself":      {profile: "d", err: "circular profile inheritance: d -> d"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ApplyProfile(NewConfig(), test.profile, cycle)
+			require.Error(t, err)
+			assert.Equal(t, test.err, err.Error())
+		})
+	}
+}
+
+func TestApplyProfileEmptyName(t *testing.T) {
+	cfg := MustNewConfigFrom(map[string]interface{}{"a": 1})
+	merged, err := ApplyProfile(cfg, "", nil)
+	require.NoError(t, err)
+	assert.Same(t, cfg, merged)
+}
@@ -0,0 +1,69 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const secretMask = "***"
+
+// Secret is a configuration value holding sensitive data, like a password or
+// an API key. The value is masked whenever the secret is printed or
+// serialized to JSON or YAML. Use Reveal to access the actual value.
+type Secret struct {
+	value string
+}
+
+// NewSecret creates a Secret holding value.
+func NewSecret(value string) Secret {
+	return Secret{value: value}
+}
+
+// Unpack implements ucfg.Unpacker. Numbers and booleans are accepted and
+// stored in their string representation.
+func (s *Secret) Unpack(v interface{}) error {
+	switch val := v.(type) {
+	case string:
+		s.value = val
+	case int64, uint64, float64, bool:
+		s.value = fmt.Sprint(val)
+	default:
+		return fmt.Errorf("invalid secret: unsupported type %T", v)
+	}
+	return nil
+}
+
+// Reveal returns the actual value of the secret.
+func (s Secret) Reveal() string { return s.value }
+
+// IsSet returns true if the secret is not empty.
+func (s Secret) IsSet() bool { return s.value != "" }
+
+// String returns the masked value.
+func (s Secret) String() string { return secretMask }
+
+// GoString returns the masked value, so %#v does not print the secret.
+func (s Secret) GoString() string { return secretMask }
+
+// MarshalJSON implements json.Marshaler, returning the masked value.
+func (s Secret) MarshalJSON() ([]byte, error) { return json.Marshal(secretMask) }
+
+// MarshalYAML implements yaml.Marshaler, returning the masked value.
+func (s Secret) MarshalYAML() (interface{}, error) { return secretMask, nil }
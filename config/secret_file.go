@@ -0,0 +1,199 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	ucfg "github.com/elastic/go-ucfg"
+)
+
+// secretFileSuffix is appended to the name of a setting to read its value
+// from a file, e.g. `password_file: /run/secrets/password`.
+const secretFileSuffix = "_file"
+
+// fromFileTagOption enables reading a string field from a file, like the
+// Secret fields.
+const fromFileTagOption = "from_file"
+
+var (
+	secretFileMu      sync.RWMutex
+	secretFileHandler = logInsecureSecretFile
+)
+
+// OnInsecureSecretFile sets the function called when a setting is read from
+// a file that can be accessed by other users than its owner. By default a
+// warning is logged with the cfgwarn selector. The check is not done on
+// Windows.
+func OnInsecureSecretFile(fn func(path string, mode os.FileMode)) {
+	secretFileMu.Lock()
+	defer secretFileMu.Unlock()
+	secretFileHandler = fn
+}
+
+// applySecretFiles returns c with the fields of to read from files set. The
+// value of a Secret or LazySecret field, or of a string field with the
+// `from_file` config tag option, is read from the file set in the setting
+// named after the field with the `_file` suffix. The contents of the file are trimmed of
+// surrounding whitespace and used as is, variables are not expanded.
+//
+// c is returned as is if no file is set, otherwise a copy keeping the
+// position of c is returned, c is never modified.
+func applySecretFiles(c *C, to interface{}) (*C, error) {
+	t := reflect.TypeOf(to)
+	if !usesSecretFiles(t) {
+		return c, nil
+	}
+
+	values := map[string]interface{}{}
+	if err := collectSecretFiles(c, chaseType(t), "", values); err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return c, nil
+	}
+
+	out, err := copyInPlace(c)
+	if err != nil {
+		return nil, err
+	}
+	// Merge without variable expansion, the secrets are used as is.
+	if err := out.access().Merge(values, ucfg.PathSep(".")); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func collectSecretFiles(c *C, t reflect.Type, path string, values map[string]interface{}) error {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name, inline, ignore := parseConfigTag(field)
+		if ignore {
+			continue
+		}
+		ft := chaseType(field.Type)
+		if inline {
+			if err := collectSecretFiles(c, ft, path, values); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key := name
+		if path != "" {
+			key = path + "." + name
+		}
+
+		if isFileSetting(field, ft) {
+			fileKey := name + secretFileSuffix
+			if !c.HasField(fileKey) {
+				continue
+			}
+			if c.HasField(name) {
+				return fmt.Errorf("settings '%v' and '%v' can not be used together", c.PathOf(name), c.PathOf(fileKey))
+			}
+			filename, err := c.String(fileKey, -1)
+			if err != nil {
+				return err
+			}
+			value, err := readSecretFile(filename)
+			if err != nil {
+				return fmt.Errorf("failed to read the value of '%v' from '%v': %w", c.PathOf(name), c.PathOf(fileKey), err)
+			}
+			values[key] = value
+			continue
+		}
+
+		if ft.Kind() == reflect.Struct && c.HasField(name) {
+			sub, err := c.Child(name, -1)
+			if err != nil {
+				continue
+			}
+			if err := collectSecretFiles(sub, ft, key, values); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// secretFileTypes caches whether a type has fields read from files.
+var secretFileTypes sync.Map // reflect.Type -> bool
+
+// usesSecretFiles returns true if t, or a type of its fields, has a field
+// that can be read from a file.
+func usesSecretFiles(t reflect.Type) bool {
+	return cachedTypeHasField(&secretFileTypes, t, func(field reflect.StructField) bool {
+		return isFileSetting(field, chaseType(field.Type))
+	})
+}
+
+func isFileSetting(field reflect.StructField, ft reflect.Type) bool {
+	if ft == tSecret || ft == tLazySecret {
+		return true
+	}
+	if ft.Kind() != reflect.String {
+		return false
+	}
+	for _, opt := range strings.Split(field.Tag.Get("config"), ",")[1:] {
+		if strings.TrimSpace(opt) == fromFileTagOption {
+			return true
+		}
+	}
+	return false
+}
+
+func logInsecureSecretFile(path string, mode os.FileMode) {
+	logp.NewLogger("cfgwarn").Warnf("Configuration secret file %v can be accessed by other users (permissions %v), restrict it to its owner.", path, mode)
+}
+
+func readSecretFile(filename string) (string, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return "", err
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		secretFileMu.RLock()
+		fn := secretFileHandler
+		secretFileMu.RUnlock()
+		if fn != nil {
+			fn(filename, info.Mode().Perm())
+		}
+	}
+
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
@@ -0,0 +1,165 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+type secretFileSettings struct {
+	Output struct {
+		Username string `config:"username"`
+		Password Secret `config:"password" validate:"required"`
+		APIKey   string `config:"api_key,from_file"`
+	} `config:"output"`
+}
+
+func writeSecretFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "secret")
+	writeConfigFile(t, path, content)
+	return path
+}
+
+func TestUnpackSecretFiles(t *testing.T) {
+	password := writeSecretFile(t, "s3cr3t${not.a.variable}\n")
+	apiKey := writeSecretFile(t, "  id:key  ")
+
+	c := MustNewConfigFrom(map[string]interface{}{
+		"output.username":      "elastic",
+		"output.password_file": password,
+		"output.api_key_file":  apiKey,
+	})
+
+	var s secretFileSettings
+	require.NoError(t, c.Unpack(&s))
+	assert.Equal(t, "elastic", s.Output.Username)
+	assert.Equal(t, "s3cr3t${not.a.variable}", s.Output.Password.Reveal())
+	assert.Equal(t, "id:key", s.Output.APIKey)
+
+	var other secretFileSettings
+	require.NoError(t, c.UnpackWithOptions(&other, ValidateAll()))
+	assert.Equal(t, s, other)
+
+	has, err := c.Has("output.password", -1)
+	require.NoError(t, err)
+	assert.False(t, has, "the configuration must not be modified")
+}
+
+func TestUnpackSecretFilesOnChildConfig(t *testing.T) {
+	password := writeSecretFile(t, "s3cr3t")
+	c := MustNewConfigFrom(map[string]interface{}{
+		"top":                      "elastic",
+		"outputs.es.name":          "${top}",
+		"outputs.es.password_file": password,
+	})
+	child, err := c.ChildAt("outputs.es")
+	require.NoError(t, err)
+
+	var s struct {
+		Name     string `config:"name"`
+		Password Secret `config:"password"`
+	}
+	require.NoError(t, child.Unpack(&s))
+	assert.Equal(t, "elastic", s.Name)
+	assert.Equal(t, "s3cr3t", s.Password.Reveal())
+	assert.Equal(t, "outputs.es.password", child.PathOf("password"))
+}
+
+func TestUnpackSecretFilesErrors(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "missing")
+		c := MustNewConfigFrom(map[string]interface{}{"output.password_file": missing})
+
+		var s secretFileSettings
+		err := c.Unpack(&s)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read the value of 'output.password' from 'output.password_file'")
+		assert.Contains(t, err.Error(), missing)
+	})
+
+	t.Run("both settings", func(t *testing.T) {
+		c := MustNewConfigFrom(map[string]interface{}{
+			"output.password":      "inline",
+			"output.password_file": writeSecretFile(t, "secret"),
+		})
+
+		var s secretFileSettings
+		err := c.Unpack(&s)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "settings 'output.password' and 'output.password_file' can not be used together")
+	})
+
+	t.Run("plain strings are not read from files", func(t *testing.T) {
+		c := MustNewConfigFrom(map[string]interface{}{
+			"output.password":      "inline",
+			"output.username_file": writeSecretFile(t, "user"),
+		})
+
+		var s secretFileSettings
+		require.NoError(t, c.Unpack(&s))
+		assert.Empty(t, s.Output.Username)
+	})
+}
+
+func TestUnpackSecretFilesPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions are not checked on windows")
+	}
+
+	var warned []string
+	OnInsecureSecretFile(func(path string, mode os.FileMode) {
+		warned = append(warned, path)
+	})
+	defer OnInsecureSecretFile(logInsecureSecretFile)
+
+	private := writeSecretFile(t, "secret")
+	open := writeSecretFile(t, "secret")
+	require.NoError(t, os.Chmod(open, 0644))
+
+	var s secretFileSettings
+	require.NoError(t, MustNewConfigFrom(map[string]interface{}{"output.password_file": private}).Unpack(&s))
+	assert.Empty(t, warned)
+
+	require.NoError(t, MustNewConfigFrom(map[string]interface{}{"output.password_file": open}).Unpack(&s))
+	assert.Equal(t, []string{open}, warned)
+}
+
+func TestUnpackSecretFilesDefaultWarning(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions are not checked on windows")
+	}
+
+	require.NoError(t, logp.DevelopmentSetup(logp.ToObserverOutput()))
+	open := writeSecretFile(t, "secret")
+	require.NoError(t, os.Chmod(open, 0644))
+
+	var s secretFileSettings
+	require.NoError(t, MustNewConfigFrom(map[string]interface{}{"output.password_file": open}).Unpack(&s))
+	logs := logp.ObserverLogs().FilterMessageSnippet("Configuration secret file " + open + " can be accessed by other users").TakeAll()
+	require.Len(t, logs, 1)
+	assert.Equal(t, "cfgwarn", logs[0].LoggerName)
+}
@@ -0,0 +1,84 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yamlv2 "gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSecretUnpack(t *testing.T) {
+	c, err := NewConfigWithYAML([]byte("password: hunter2\npin: 1234\n"), "test")
+	require.NoError(t, err)
+
+	var settings struct {
+		Password Secret `config:"password"`
+		Pin      Secret `config:"pin"`
+		Missing  Secret `config:"missing"`
+	}
+	require.NoError(t, c.Unpack(&settings))
+	assert.Equal(t, "hunter2", settings.Password.Reveal())
+	assert.Equal(t, "1234", settings.Pin.Reveal())
+	assert.True(t, settings.Password.IsSet())
+	assert.False(t, settings.Missing.IsSet())
+
+	c, err = NewConfigWithYAML([]byte("password: [a, b]\n"), "test")
+	require.NoError(t, err)
+	assert.Error(t, c.Unpack(&settings))
+}
+
+func TestSecretRedaction(t *testing.T) {
+	settings := struct {
+		User     string `json:"user" yaml:"user"`
+		Password Secret `json:"password" yaml:"password"`
+	}{User: "elastic", Password: NewSecret("hunter2")}
+
+	assert.Equal(t, "hunter2", settings.Password.Reveal())
+
+	for name, format := range map[string]string{"v": "%v", "+v": "%+v", "#v": "%#v", "s": "%s", "q": "%q"} {
+		t.Run("fmt "+name, func(t *testing.T) {
+			out := fmt.Sprintf(format, settings)
+			assert.NotContains(t, out, "hunter2")
+			assert.Contains(t, out, "***")
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		out, err := json.Marshal(settings)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"user": "elastic", "password": "***"}`, string(out))
+	})
+
+	t.Run("yaml.v2", func(t *testing.T) {
+		out, err := yamlv2.Marshal(settings)
+		require.NoError(t, err)
+		assert.Equal(t, "user: elastic\npassword: '***'\n", string(out))
+	})
+
+	t.Run("yaml.v3", func(t *testing.T) {
+		out, err := yaml.Marshal(settings)
+		require.NoError(t, err)
+		assert.Equal(t, "user: elastic\npassword: '***'\n", string(out))
+	})
+}
@@ -0,0 +1,174 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	tDuration      = reflect.TypeOf(time.Duration(0))
+	tSecret        = reflect.TypeOf(Secret{})
+	tLazySecret    = reflect.TypeOf(LazySecret{})
+	tConfigPtr     = reflect.TypeOf((*C)(nil))
+	tTextMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// FromStruct creates a configuration from the struct v, the reverse of
+// Unpack. Fields are named by their `config` tag, or by their lowercased
+// name if the tag is missing. The tag options `inline` and `squash` merge the
+// fields of a nested struct or map into the parent, `ignore` skips the field
+// and `omitempty` skips the field if it holds a zero value. Values
+// implementing encoding.TextMarshaler and durations are stored as strings,
+// so they unpack into the same value. Secrets are stored revealed.
+func FromStruct(v interface{}) (*C, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot create config from %T: a struct is required", v)
+	}
+
+	fields := map[string]interface{}{}
+	if err := structFields(rv, fields); err != nil {
+		return nil, err
+	}
+	return NewConfigFrom(fields)
+}
+
+func structFields(v reflect.Value, out map[string]interface{}) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name, inline, ignore := parseConfigTag(field)
+		if ignore || omitEmpty(field) && v.Field(i).IsZero() {
+			continue
+		}
+
+		value, err := structValue(v.Field(i))
+		if err != nil {
+			return fmt.Errorf("field %v: %w", field.Name, err)
+		}
+
+		if inline {
+			inlined, ok := value.(map[string]interface{})
+			if !ok {
+				if value == nil {
+					continue
+				}
+				return fmt.Errorf("field %v: inline requires a struct or map, got %v", field.Name, field.Type)
+			}
+			for k, v := range inlined {
+				out[k] = v
+			}
+			continue
+		}
+		out[name] = value
+	}
+	return nil
+}
+
+func structValue(v reflect.Value) (interface{}, error) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Type() == tConfigPtr {
+			return v.Interface(), nil
+		}
+	}
+
+	switch {
+	case v.Type() == tDuration:
+		return v.Interface().(time.Duration).String(), nil
+	case v.Type() == tSecret:
+		return v.Interface().(Secret).Reveal(), nil
+	case v.Type() == tLazySecret:
+		var value string
+		err := v.Interface().(LazySecret).Reveal(func(b []byte) error {
+			value = string(b)
+			return nil
+		})
+		return value, err
+	case v.Type().Implements(tTextMarshaler):
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return structValue(v.Elem())
+	case reflect.Struct:
+		fields := map[string]interface{}{}
+		if err := structFields(v, fields); err != nil {
+			return nil, err
+		}
+		return fields, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %v", v.Type().Key())
+		}
+		fields := map[string]interface{}{}
+		iter := v.MapRange()
+		for iter.Next() {
+			value, err := structValue(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			fields[iter.Key().String()] = value
+		}
+		return fields, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, nil
+		}
+		values := make([]interface{}, v.Len())
+		for i := range values {
+			value, err := structValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	default:
+		return v.Interface(), nil
+	}
+}
+
+func omitEmpty(field reflect.StructField) bool {
+	for _, opt := range strings.Split(field.Tag.Get("config"), ",")[1:] {
+		if strings.TrimSpace(opt) == "omitempty" {
+			return true
+		}
+	}
+	return false
+}
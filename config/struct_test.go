@@ -0,0 +1,140 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fromStructOutput struct {
+	Hosts    []string          `config:"hosts"`
+	Workers  int               `config:"workers"`
+	Timeout  time.Duration     `config:"timeout"`
+	Backoff  Duration          `config:"backoff"`
+	Password Secret            `config:"password"`
+	Headers  map[string]string `config:"headers,omitempty"`
+}
+
+type fromStructSettings struct {
+	Name     string              `config:"name"`
+	Enabled  bool                `config:"enabled"`
+	Ratio    float64             `config:"ratio"`
+	Output   fromStructOutput    `config:"output.elasticsearch"`
+	Inputs   []fromStructInput   `config:"inputs"`
+	Limits   *fromStructLimits   `config:"limits"`
+	Tags     []string            `config:"tags,omitempty"`
+	Extra    map[string]int      `config:"extra"`
+	Internal string              `config:"internal,ignore"`
+	Common   fromStructCommon    `config:",inline"`
+	Optional *fromStructOptional `config:"optional,omitempty"`
+}
+
+type fromStructInput struct {
+	Type  string `config:"type"`
+	Paths []string
+}
+
+type fromStructLimits struct {
+	Max int `config:"max"`
+}
+
+type fromStructCommon struct {
+	Version string `config:"version"`
+}
+
+type fromStructOptional struct {
+	Value string `config:"value"`
+}
+
+func TestFromStructRoundTrip(t *testing.T) {
+	in := fromStructSettings{
+		Name:    "test",
+		Enabled: true,
+		Ratio:   0.5,
+		Output: fromStructOutput{
+			Hosts:    []string{"localhost:9200", "other:9200"},
+			Workers:  2,
+			Timeout:  90 * time.Second,
+			Backoff:  Duration(250 * time.Millisecond),
+			Password: NewSecret("changeme"),
+			Headers:  map[string]string{"X-Test": "1"},
+		},
+		Inputs: []fromStructInput{
+			{Type: "log", Paths: []string{"/var/log/*.log"}},
+			{Type: "tcp"},
+		},
+		Limits:   &fromStructLimits{Max: 10},
+		Extra:    map[string]int{"a": 1, "b": -2},
+		Internal: "skipped",
+		Common:   fromStructCommon{Version: "8.0.0"},
+	}
+
+	c, err := FromStruct(&in)
+	require.NoError(t, err)
+
+	assert.False(t, c.HasField("internal"))
+	assert.False(t, c.HasField("tags"))
+	assert.False(t, c.HasField("optional"))
+	assert.False(t, c.HasField("common"))
+	version, err := c.String("version", -1)
+	require.NoError(t, err)
+	assert.Equal(t, "8.0.0", version)
+	timeout, err := c.String("output.elasticsearch.timeout", -1)
+	require.NoError(t, err)
+	assert.Equal(t, "1m30s", timeout)
+	paths, err := c.String("inputs.0.paths", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "/var/log/*.log", paths)
+
+	var out fromStructSettings
+	require.NoError(t, c.Unpack(&out))
+	in.Internal = ""
+	assert.Equal(t, in, out)
+	assert.Equal(t, "changeme", out.Output.Password.Reveal())
+}
+
+func TestFromStructErrors(t *testing.T) {
+	_, err := FromStruct(map[string]interface{}{"a": 1})
+	assert.Error(t, err)
+
+	_, err = FromStruct(struct {
+		M map[int]string `config:"m"`
+	}{M: map[int]string{1: "a"}})
+	assert.Error(t, err)
+
+	_, err = FromStruct(struct {
+		N int `config:",inline"`
+	}{N: 1})
+	assert.Error(t, err)
+}
+
+func TestFromStructNestedConfig(t *testing.T) {
+	sub := MustNewConfigFrom("a.b: 1")
+	c, err := FromStruct(struct {
+		Sub *C `config:"sub"`
+	}{Sub: sub})
+	require.NoError(t, err)
+
+	v, err := c.Int("sub.a.b", -1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), v)
+}
@@ -0,0 +1,394 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/elastic/go-ucfg/parse"
+)
+
+// UnpackOption configures the behavior of UnpackWithOptions.
+type UnpackOption func(*unpackOptions)
+
+type unpackOptions struct {
+	splitSep    string
+	boolParsing BoolParsing
+	validateAll bool
+	resolvers   []ucfg.Option
+}
+
+// VariableProvider returns the value of a variable, or an error if it can not
+// be resolved.
+type VariableProvider func(name string) (string, error)
+
+// BoolParsing selects the values accepted when unpacking boolean settings.
+type BoolParsing int
+
+const (
+	// BoolParsingDefault accepts the values supported by go-ucfg: booleans
+	// and the strings accepted by strconv.ParseBool.
+	BoolParsingDefault BoolParsing = iota
+	// BoolParsingStrict only accepts booleans and the strings true and false.
+	BoolParsingStrict
+	// BoolParsingLenient accepts booleans, the numbers 1 and 0, and the
+	// strings true/false, t/f, yes/no, y/n, on/off and 1/0.
+	BoolParsingLenient
+)
+
+// Accepted boolean tokens, as true/false pairs.
+var (
+	strictBoolTokens  = []string{"true", "false"}
+	lenientBoolTokens = []string{"true", "false", "t", "f", "yes", "no", "y", "n", "on", "off", "1", "0"}
+)
+
+// SplitStrings makes string settings unpacked into a slice of strings to be
+// split on sep. The split only applies to scalar strings, lists are unpacked
+// as is. Separators can be included in a value by quoting the value or by
+// escaping the separator with a backslash, e.g. `a\,b,c` yields `a,b` and `c`.
+func SplitStrings(sep string) UnpackOption {
+	return func(o *unpackOptions) {
+		o.splitSep = sep
+	}
+}
+
+// ParseBools selects the values accepted for boolean settings. Values
+// of boolean settings are compared case insensitively. BoolParsingDefault is
+// used if the option is not set.
+func ParseBools(mode BoolParsing) UnpackOption {
+	return func(o *unpackOptions) {
+		o.boolParsing = mode
+	}
+}
+
+// WithVariableProvider resolves the variables named `prefix.name`, e.g.
+// `${keystore.es.password}`, with provider. The provider is called with the
+// name without the prefix. The value is used as is, it is not parsed as a
+// list or dictionary and does not expand other variables. Variables without
+// the prefix are resolved as usual. Note that `${prefix:name}` can not be
+// used, as it references the variable prefix with name as default value.
+func WithVariableProvider(prefix string, provider VariableProvider) UnpackOption {
+	prefix += "."
+	resolve := func(name string) (string, parse.Config, error) {
+		if !strings.HasPrefix(name, prefix) {
+			return "", parse.NoopConfig, ucfg.ErrMissing
+		}
+		value, err := provider(strings.TrimPrefix(name, prefix))
+		if err != nil {
+			return "", parse.NoopConfig, fmt.Errorf("failed to resolve '${%v}': %w", name, err)
+		}
+		return value, parse.NoopConfig, nil
+	}
+	return func(o *unpackOptions) {
+		o.resolvers = append(o.resolvers, ucfg.Resolve(resolve))
+	}
+}
+
+// UnpackWithOptions unpacks the configuration into to, like Unpack, and
+// applies the given options.
+func (c *C) UnpackWithOptions(to interface{}, opts ...UnpackOption) error {
+	var o unpackOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// The resolvers are tried last to first and the error of the last one
+	// tried is reported, keep the provider errors.
+	ucfgOpts := append(append([]ucfg.Option{}, o.resolvers...), configOpts...)
+
+	src, err := applyAliases(c, ucfgOpts)
+	if err != nil {
+		return err
+	}
+	if src, err = applySecretFiles(src, to); err != nil {
+		return err
+	}
+	if o.boolParsing != BoolParsingDefault {
+		if src, err = normalizeBools(src, to, o.boolParsing, ucfgOpts); err != nil {
+			return err
+		}
+	}
+	// go-ucfg stops at the first violation and does not know the
+	// constraints of this package, validateFields checks them instead.
+	validate := o.validateAll || usesLocalConstraints(reflect.TypeOf(to))
+	unpackOpts := ucfgOpts
+	if validate {
+		unpackOpts = append(append([]ucfg.Option{}, ucfgOpts...), ucfg.ValidatorTag(noValidateTag))
+	}
+	if err := src.access().Unpack(to, unpackOpts...); err != nil {
+		return withIntegerRanges(err, src, to)
+	}
+
+	if o.splitSep != "" {
+		splitStringFields(src, reflect.ValueOf(to), o.splitSep)
+	}
+
+	if !validate {
+		return nil
+	}
+	validation := fieldsValidation{all: o.validateAll, opts: ucfgOpts}
+	validateFields(src, reflect.ValueOf(to), "", &validation)
+	return validation.err()
+}
+
+// normalizeBools returns a copy of c where the values of the settings
+// unpacked into boolean fields of to are replaced by booleans. An error is
+// returned if a value is not accepted by mode. The variables are resolved
+// with opts.
+func normalizeBools(c *C, to interface{}, mode BoolParsing, opts []ucfg.Option) (*C, error) {
+	tokens := strictBoolTokens
+	if mode == BoolParsingLenient {
+		tokens = lenientBoolTokens
+	}
+
+	var fields map[string]interface{}
+	if err := c.access().Unpack(&fields, opts...); err != nil {
+		return nil, err
+	}
+
+	t := reflect.TypeOf(to)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if err := normalizeBoolFields(fields, t, "", tokens, mode == BoolParsingLenient); err != nil {
+		return nil, err
+	}
+	// The values are resolved, do not expand the variables again.
+	out, err := ucfg.NewFrom(fields, ucfg.PathSep("."))
+	return fromConfig(out), err
+}
+
+func normalizeBoolFields(fields map[string]interface{}, t reflect.Type, path string, tokens []string, acceptNumbers bool) error {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name, inline, ignore := parseConfigTag(field)
+		if ignore {
+			continue
+		}
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if inline {
+			if err := normalizeBoolFields(fields, ft, path, tokens, acceptNumbers); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, found := fields[name]
+		if !found || value == nil {
+			continue
+		}
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+
+		switch ft.Kind() {
+		case reflect.Bool:
+			b, err := parseBool(value, fieldPath, tokens, acceptNumbers)
+			if err != nil {
+				return err
+			}
+			fields[name] = b
+
+		case reflect.Struct:
+			if sub, ok := value.(map[string]interface{}); ok {
+				if err := normalizeBoolFields(sub, ft, fieldPath, tokens, acceptNumbers); err != nil {
+					return err
+				}
+			}
+
+		case reflect.Slice, reflect.Array:
+			elemType := ft.Elem()
+			for elemType.Kind() == reflect.Ptr {
+				elemType = elemType.Elem()
+			}
+			list, ok := value.([]interface{})
+			if !ok || elemType.Kind() != reflect.Struct {
+				continue
+			}
+			for j, elem := range list {
+				if sub, ok := elem.(map[string]interface{}); ok {
+					if err := normalizeBoolFields(sub, elemType, fmt.Sprintf("%v.%d", fieldPath, j), tokens, acceptNumbers); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func parseBool(value interface{}, path string, tokens []string, acceptNumbers bool) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		v = strings.ToLower(strings.TrimSpace(v))
+		for i, token := range tokens {
+			if v == token {
+				return i%2 == 0, nil
+			}
+		}
+	case int64:
+		if acceptNumbers && (v == 0 || v == 1) {
+			return v == 1, nil
+		}
+	case uint64:
+		if acceptNumbers && (v == 0 || v == 1) {
+			return v == 1, nil
+		}
+	}
+	return false, fmt.Errorf("invalid boolean value '%v' accessing '%v', accepted values are: %v",
+		value, path, strings.Join(tokens, ", "))
+}
+
+// splitStringFields walks the struct v alongside the configuration c it has
+// been unpacked from and splits the string slices configured as a scalar.
+func splitStringFields(c *C, v reflect.Value, sep string) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct || c == nil {
+		return
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name, inline, ignore := parseConfigTag(field)
+		if ignore {
+			continue
+		}
+		fv := v.Field(i)
+		if inline {
+			splitStringFields(c, fv, sep)
+			continue
+		}
+		if !c.HasField(name) {
+			continue
+		}
+
+		ft := field.Type
+		switch {
+		case ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.String:
+			if _, err := c.Child(name, -1); err == nil {
+				// configured as a list or a dictionary
+				continue
+			}
+			s, err := c.String(name, -1)
+			if err != nil {
+				continue
+			}
+			parts := splitQuoted(s, sep)
+			slice := reflect.MakeSlice(ft, len(parts), len(parts))
+			for j, part := range parts {
+				slice.Index(j).SetString(part)
+			}
+			fv.Set(slice)
+
+		case ft.Kind() == reflect.Slice:
+			for j := 0; j < fv.Len(); j++ {
+				sub, err := c.Child(name, j)
+				if err != nil {
+					break
+				}
+				splitStringFields(sub, fv.Index(j), sep)
+			}
+
+		default:
+			if sub, err := c.Child(name, -1); err == nil {
+				splitStringFields(sub, fv, sep)
+			}
+		}
+	}
+}
+
+// parseConfigTag returns the setting name of a struct field, following the
+// rules used by go-ucfg.
+func parseConfigTag(field reflect.StructField) (name string, inline, ignore bool) {
+	tag := strings.Split(field.Tag.Get("config"), ",")
+	name = tag[0]
+	for _, opt := range tag[1:] {
+		switch strings.TrimSpace(opt) {
+		case "inline", "squash":
+			inline = true
+		case "ignore":
+			ignore = true
+		}
+	}
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, inline, ignore
+}
+
+// splitQuoted splits s on sep. Separators inside double quotes or escaped
+// with a backslash are kept. Surrounding whitespace and quotes are removed
+// from each part, empty parts are dropped.
+func splitQuoted(s, sep string) []string {
+	var (
+		parts   []string
+		current strings.Builder
+		quoted  bool
+	)
+
+	flush := func() {
+		if part := strings.TrimSpace(current.String()); part != "" {
+			parts = append(parts, part)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			i++
+			current.WriteByte(s[i])
+		case s[i] == '"':
+			quoted = !quoted
+		case !quoted && strings.HasPrefix(s[i:], sep):
+			flush()
+			i += len(sep) - 1
+		default:
+			current.WriteByte(s[i])
+		}
+	}
+	flush()
+	return parts
+}
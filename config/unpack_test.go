@@ -0,0 +1,221 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnpackWithOptionsSplitStrings(t *testing.T) {
+	type output struct {
+		Hosts []string `config:"hosts"`
+	}
+	type settings struct {
+		Tags    []string `config:"tags"`
+		Name    string   `config:"name"`
+		Output  output   `config:"output"`
+		Outputs []output `config:"outputs"`
+		Inline  struct {
+			Labels []string `config:"labels"`
+		} `config:",inline"`
+	}
+
+	tests := map[string]struct {
+		yaml string
+		sep  string
+		want []string
+	}{
+		"comma separated": {yaml: `tags: "a,b,c"`, sep: ",", want: []string{"a", "b", "c"}},
+		"whitespace":      {yaml: `tags: "a, b ,c,"`, sep: ",", want: []string{"a", "b", "c"}},
+		"list unchanged":  {yaml: `tags: ["a,b", "c"]`, sep: ",", want: []string{"a,b", "c"}},
+		"escaped comma":   {yaml: `tags: 'a\,b,c'`, sep: ",", want: []string{"a,b", "c"}},
+		"quoted comma":    {yaml: `tags: '"a,b",c'`, sep: ",", want: []string{"a,b", "c"}},
+		"custom sep":      {yaml: `tags: "a,b;c"`, sep: ";", want: []string{"a,b", "c"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := MustNewConfigFrom(test.yaml)
+			var s settings
+			require.NoError(t, c.UnpackWithOptions(&s, SplitStrings(test.sep)))
+			assert.Equal(t, test.want, s.Tags)
+		})
+	}
+
+	t.Run("nested fields", func(t *testing.T) {
+		c := MustNewConfigFrom(`
+name: "a,b"
+labels: "x,y"
+output.hosts: "h1,h2"
+outputs:
+  - hosts: "h3,h4"
+  - hosts: ["h5,h6"]
+`)
+		var s settings
+		require.NoError(t, c.UnpackWithOptions(&s, SplitStrings(",")))
+		assert.Equal(t, "a,b", s.Name)
+		assert.Equal(t, []string{"x", "y"}, s.Inline.Labels)
+		assert.Equal(t, []string{"h1", "h2"}, s.Output.Hosts)
+		require.Len(t, s.Outputs, 2)
+		assert.Equal(t, []string{"h3", "h4"}, s.Outputs[0].Hosts)
+		assert.Equal(t, []string{"h5,h6"}, s.Outputs[1].Hosts)
+	})
+
+	t.Run("without option", func(t *testing.T) {
+		var s settings
+		require.NoError(t, MustNewConfigFrom(`tags: "a,b"`).UnpackWithOptions(&s))
+		assert.Equal(t, []string{"a,b"}, s.Tags)
+	})
+}
+
+func TestUnpackWithOptionsParseBools(t *testing.T) {
+	type output struct {
+		Enabled *bool `config:"enabled"`
+	}
+	type settings struct {
+		Enabled bool     `config:"enabled"`
+		Name    string   `config:"name"`
+		Output  output   `config:"output"`
+		Outputs []output `config:"outputs"`
+	}
+
+	tests := map[string]struct {
+		yaml    string
+		mode    BoolParsing
+		want    bool
+		wantErr string
+	}{
+		"default bool":             {yaml: `enabled: true`, mode: BoolParsingDefault, want: true},
+		"default ParseBool string": {yaml: `enabled: "t"`, mode: BoolParsingDefault, want: true},
+		"default yes string":       {yaml: `enabled: "yes"`, mode: BoolParsingDefault, wantErr: "can not convert 'string' into 'bool'"},
+		"strict bool":              {yaml: `enabled: false`, mode: BoolParsingStrict, want: false},
+		"strict true string":       {yaml: `enabled: "True"`, mode: BoolParsingStrict, want: true},
+		"strict ParseBool string":  {yaml: `enabled: "1"`, mode: BoolParsingStrict, wantErr: "invalid boolean value '1' accessing 'enabled', accepted values are: true, false"},
+		"strict number":            {yaml: `enabled: 1`, mode: BoolParsingStrict, wantErr: "accepted values are: true, false"},
+		"lenient yes":              {yaml: `enabled: "yes"`, mode: BoolParsingLenient, want: true},
+		"lenient no":               {yaml: `enabled: "no"`, mode: BoolParsingLenient, want: false},
+		"lenient on":               {yaml: `enabled: "ON"`, mode: BoolParsingLenient, want: true},
+		"lenient off":              {yaml: `enabled: "off"`, mode: BoolParsingLenient, want: false},
+		"lenient 1 string":         {yaml: `enabled: "1"`, mode: BoolParsingLenient, want: true},
+		"lenient 0 number":         {yaml: `enabled: 0`, mode: BoolParsingLenient, want: false},
+		"lenient 1 number":         {yaml: `enabled: 1`, mode: BoolParsingLenient, want: true},
+		"lenient other number":     {yaml: `enabled: 2`, mode: BoolParsingLenient, wantErr: "invalid boolean value '2'"},
+		"lenient invalid": {
+			yaml:    `enabled: "maybe"`,
+			mode:    BoolParsingLenient,
+			wantErr: "invalid boolean value 'maybe' accessing 'enabled', accepted values are: true, false, t, f, yes, no, y, n, on, off, 1, 0",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var s settings
+			err := MustNewConfigFrom(test.yaml).UnpackWithOptions(&s, ParseBools(test.mode))
+			if test.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, s.Enabled)
+		})
+	}
+
+	t.Run("nested fields", func(t *testing.T) {
+		c := MustNewConfigFrom(`
+name: "on"
+output.enabled: "on"
+outputs:
+  - enabled: "off"
+  - enabled: "yes"
+`)
+		var s settings
+		require.NoError(t, c.UnpackWithOptions(&s, ParseBools(BoolParsingLenient)))
+		assert.Equal(t, "on", s.Name)
+		require.NotNil(t, s.Output.Enabled)
+		assert.True(t, *s.Output.Enabled)
+		require.Len(t, s.Outputs, 2)
+		assert.False(t, *s.Outputs[0].Enabled)
+		assert.True(t, *s.Outputs[1].Enabled)
+
+		err := c.UnpackWithOptions(&s, ParseBools(BoolParsingStrict))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "accessing 'output.enabled'")
+
+		c = MustNewConfigFrom(`outputs: [{enabled: "nope"}]`)
+		err = c.UnpackWithOptions(&s, ParseBools(BoolParsingLenient))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "accessing 'outputs.0.enabled'")
+	})
+
+	t.Run("variables are expanded once", func(t *testing.T) {
+		t.Setenv("PARSE_BOOLS_NAME", "$${raw}")
+		c := MustNewConfigFrom(`{enabled: "on", name: "${PARSE_BOOLS_NAME}"}`)
+		var s settings
+		require.NoError(t, c.UnpackWithOptions(&s, ParseBools(BoolParsingLenient)))
+		assert.True(t, s.Enabled)
+		assert.Equal(t, "$${raw}", s.Name)
+	})
+
+	t.Run("combined with split strings", func(t *testing.T) {
+		var s struct {
+			Enabled bool     `config:"enabled"`
+			Tags    []string `config:"tags"`
+		}
+		c := MustNewConfigFrom(`{enabled: "yes", tags: "a,b"}`)
+		require.NoError(t, c.UnpackWithOptions(&s, ParseBools(BoolParsingLenient), SplitStrings(",")))
+		assert.True(t, s.Enabled)
+		assert.Equal(t, []string{"a", "b"}, s.Tags)
+	})
+}
+
+func TestUnpackWithOptionsVariableProvider(t *testing.T) {
+	vars := map[string]string{"host": "localhost:9200", "raw": "${host}"}
+	provider := WithVariableProvider("vars", func(name string) (string, error) {
+		v, ok := vars[name]
+		if !ok {
+			return "", fmt.Errorf("unknown variable '%v'", name)
+		}
+		return v, nil
+	})
+
+	var settings struct {
+		Host  string `config:"host"`
+		Raw   string `config:"raw"`
+		Other string `config:"other"`
+	}
+
+	c := MustNewConfigFrom(map[string]interface{}{
+		"host":  "${vars.host}",
+		"raw":   "${vars.raw}",
+		"other": "${host}",
+	})
+	require.NoError(t, c.UnpackWithOptions(&settings, provider))
+	assert.Equal(t, "localhost:9200", settings.Host)
+	assert.Equal(t, "${host}", settings.Raw)
+	assert.Equal(t, "localhost:9200", settings.Other)
+
+	c = MustNewConfigFrom(map[string]interface{}{"host": "${vars.port}"})
+	err := c.UnpackWithOptions(&settings, provider)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown variable 'port'")
+}
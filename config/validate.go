@@ -0,0 +1,344 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/joeshaw/multierror"
+)
+
+// validateTag is the struct tag holding the validation constraints of a field.
+const validateTag = "validate"
+
+// noValidateTag is a struct tag no field uses. It disables the evaluation of
+// the validation constraints by go-ucfg when they are checked by
+// validateFields.
+const noValidateTag = "validate_all_disabled"
+
+// fieldValidateTag holds the go-ucfg constraints of a single field checked
+// by validateFields.
+const fieldValidateTag = "validate_field"
+
+// Constraints checked by this package. go-ucfg does not know them, the
+// configurations of structs using them are validated by validateFields.
+const (
+	oneOfConstraint             = "oneof"
+	mutuallyExclusiveConstraint = "mutually_exclusive"
+	requiresConstraint          = "requires"
+)
+
+// ValidateAll makes UnpackWithOptions report all the violations of the
+// validation constraints set in the `validate` struct tags, instead of
+// failing on the first one. Besides the constraints of go-ucfg (required,
+// nonzero, positive, min and max), the following constraints are supported:
+//
+//   - oneof=a b c: the value must be one of the space separated values.
+//   - mutually_exclusive=a b: the settings a and b must not be set if the
+//     field is set.
+//   - requires=a b: the settings a and b must be set if the field is set.
+//
+// These constraints are also supported when unpacking without this option.
+// The mutually_exclusive and requires constraints relate a field to the
+// other fields of the same struct, all their violations are always reported
+// together.
+func ValidateAll() UnpackOption {
+	return func(o *unpackOptions) {
+		o.validateAll = true
+	}
+}
+
+// validateOneOf implements the `oneof` validation constraint.
+func validateOneOf(value reflect.Value, param string) error {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	v := fmt.Sprint(value.Interface())
+	allowed := strings.Fields(param)
+	for _, a := range allowed {
+		if v == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("value '%v' is not one of (%v)", v, strings.Join(allowed, ", "))
+}
+
+// localConstraints caches whether a type uses the constraints checked by
+// this package.
+var localConstraints sync.Map // reflect.Type -> bool
+
+// usesLocalConstraints returns true if t, or a type of its fields, uses a
+// validation constraint checked by this package.
+func usesLocalConstraints(t reflect.Type) bool {
+	return cachedTypeHasField(&localConstraints, t, func(field reflect.StructField) bool {
+		for _, constraint := range strings.Split(field.Tag.Get(validateTag), ",") {
+			if isLocalConstraint(constraintName(constraint)) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// cachedTypeHasField returns typeHasField(t, match), caching the result in
+// cache.
+func cachedTypeHasField(cache *sync.Map, t reflect.Type, match func(reflect.StructField) bool) bool {
+	if t == nil {
+		return false
+	}
+	if found, ok := cache.Load(t); ok {
+		return found.(bool)
+	}
+	found := typeHasField(t, match, map[reflect.Type]bool{})
+	cache.Store(t, found)
+	return found
+}
+
+// typeHasField returns true if a field of t, or of the types of its fields,
+// matches.
+func typeHasField(t reflect.Type, match func(reflect.StructField) bool, seen map[reflect.Type]bool) bool {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+			continue
+		}
+		break
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		if match(field) || typeHasField(field.Type, match, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+func constraintName(constraint string) string {
+	return strings.TrimSpace(strings.SplitN(constraint, "=", 2)[0])
+}
+
+func isLocalConstraint(name string) bool {
+	return name == oneOfConstraint || isRelation(name)
+}
+
+func isRelation(name string) bool {
+	return name == mutuallyExclusiveConstraint || name == requiresConstraint
+}
+
+// fieldsValidation collects the violations found by validateFields.
+type fieldsValidation struct {
+	all  bool          // Report all the violations, not only the first one.
+	opts []ucfg.Option // Options used to unpack the fields checked by go-ucfg.
+
+	errs      multierror.Errors
+	relations multierror.Errors
+}
+
+// err returns the violations found. Without the all option only the first
+// constraint violation is returned, and the violations of the relations if
+// there are none.
+func (v *fieldsValidation) err() error {
+	if v.all {
+		return append(v.errs, v.relations...).Err()
+	}
+	if len(v.errs) > 0 {
+		return v.errs[0]
+	}
+	return v.relations.Err()
+}
+
+// validateFields validates the fields of the struct v unpacked from c. The
+// constraints supported by go-ucfg are checked by go-ucfg, one field at a
+// time, the others by this package.
+func validateFields(c *C, v reflect.Value, path string, validation *fieldsValidation) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name, inline, ignore := parseConfigTag(field)
+		if ignore {
+			continue
+		}
+		fv := v.Field(i)
+		if inline {
+			validateFields(c, fv, path, validation)
+			continue
+		}
+
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+		set := c != nil && c.HasField(name)
+
+		var ucfgConstraints []string
+		for _, constraint := range strings.Split(field.Tag.Get(validateTag), ",") {
+			constraint = strings.TrimSpace(constraint)
+			switch cname := constraintName(constraint); {
+			case constraint == "":
+			case isRelation(cname):
+				validateRelation(c, set, path, name, constraint, validation)
+			case cname == oneOfConstraint:
+				if !set {
+					continue
+				}
+				param := strings.TrimSpace(strings.TrimPrefix(constraint[len(cname):], "="))
+				if err := validateOneOf(fv, param); err != nil {
+					validation.errs = append(validation.errs, fmt.Errorf("%w accessing '%v'", err, fieldPath))
+				}
+			case set || cname == "required":
+				// Only the values present in the configuration are
+				// validated, the defaults are expected to be valid.
+				ucfgConstraints = append(ucfgConstraints, constraint)
+			}
+		}
+		if len(ucfgConstraints) > 0 && c != nil {
+			if err := validateUcfgConstraints(c, name, field.Type, ucfgConstraints, validation.opts); err != nil {
+				validation.errs = append(validation.errs, err)
+			}
+		}
+
+		if !set {
+			continue
+		}
+		switch chaseType(fv.Type()).Kind() {
+		case reflect.Struct:
+			sub, err := c.Child(name, -1)
+			if err == nil {
+				validateFields(sub, fv, fieldPath, validation)
+			}
+		case reflect.Slice, reflect.Array:
+			if chaseType(fv.Type().Elem()).Kind() != reflect.Struct {
+				continue
+			}
+			for j := 0; j < fv.Len(); j++ {
+				sub, err := c.Child(name, j)
+				if err != nil {
+					break
+				}
+				validateFields(sub, fv.Index(j), fmt.Sprintf("%v.%d", fieldPath, j), validation)
+			}
+		}
+	}
+}
+
+// validateUcfgConstraints has go-ucfg check the constraints of the setting
+// name of c, by unpacking it alone into a struct with a single field. The
+// settings of structs and lists are unpacked into generic values, unless
+// the type has an Unpack method, to not validate their fields again.
+func validateUcfgConstraints(c *C, name string, t reflect.Type, constraints []string, opts []ucfg.Option) error {
+	if _, unpacker := reflect.PtrTo(chaseType(t)).MethodByName("Unpack"); !unpacker {
+		switch chaseType(t).Kind() {
+		case reflect.Struct, reflect.Map:
+			t = reflect.TypeOf(map[string]interface{}{})
+		case reflect.Slice, reflect.Array:
+			t = reflect.TypeOf([]interface{}{})
+		}
+	}
+
+	tag := fmt.Sprintf(`config:%q %v:%q`, name, fieldValidateTag, strings.Join(constraints, ","))
+	single := reflect.New(singleFieldType(t, reflect.StructTag(tag)))
+	opts = append(append([]ucfg.Option{}, opts...), ucfg.ValidatorTag(fieldValidateTag))
+	return c.access().Unpack(single.Interface(), opts...)
+}
+
+type singleField struct {
+	t   reflect.Type
+	tag reflect.StructTag
+}
+
+// singleFieldTypes caches the struct types created by singleFieldType.
+var singleFieldTypes sync.Map // singleField -> reflect.Type
+
+// singleFieldType returns a struct type with a single field of type t with
+// the tag.
+func singleFieldType(t reflect.Type, tag reflect.StructTag) reflect.Type {
+	key := singleField{t: t, tag: tag}
+	if typ, ok := singleFieldTypes.Load(key); ok {
+		return typ.(reflect.Type)
+	}
+	typ := reflect.StructOf([]reflect.StructField{{Name: "Value", Type: t, Tag: tag}})
+	singleFieldTypes.Store(key, typ)
+	return typ
+}
+
+// validateRelation checks a constraint between the field name and the other
+// settings of the configuration c.
+func validateRelation(c *C, set bool, path, name, constraint string, validation *fieldsValidation) {
+	if !set {
+		return
+	}
+
+	relation, param := constraint, ""
+	if idx := strings.IndexByte(constraint, '='); idx >= 0 {
+		relation, param = strings.TrimSpace(constraint[:idx]), constraint[idx+1:]
+	}
+
+	fullPath := func(name string) string {
+		if path == "" {
+			return name
+		}
+		return path + "." + name
+	}
+
+	for _, other := range strings.Fields(param) {
+		otherSet := c.HasField(other)
+		switch {
+		case relation == mutuallyExclusiveConstraint && otherSet:
+			validation.relations = append(validation.relations, fmt.Errorf("setting is mutually exclusive with '%v' accessing '%v'", fullPath(other), fullPath(name)))
+		case relation == requiresConstraint && !otherSet:
+			validation.relations = append(validation.relations, fmt.Errorf("setting requires '%v' accessing '%v'", fullPath(other), fullPath(name)))
+		}
+	}
+}
+
+func chaseType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,198 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/joeshaw/multierror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validatedEndpoint struct {
+	Host string `config:"host" validate:"required"`
+	Port int    `config:"port" validate:"min=1,max=65535"`
+}
+
+type validatedSettings struct {
+	Name      string              `config:"name" validate:"required"`
+	Mode      string              `config:"mode" validate:"oneof=pull push"`
+	Workers   uint                `config:"workers" validate:"nonzero,max=64"`
+	Ratio     float64             `config:"ratio" validate:"min=0,max=1"`
+	Timeout   time.Duration       `config:"timeout" validate:"min=1s,max=1m"`
+	Endpoint  validatedEndpoint   `config:"endpoint"`
+	Endpoints []validatedEndpoint `config:"endpoints"`
+}
+
+func TestUnpackWithOptionsValidateAll(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		c := MustNewConfigFrom(`
+name: test
+mode: push
+workers: 4
+ratio: 0.5
+timeout: 30s
+endpoint: {host: localhost, port: 9200}
+endpoints:
+  - {host: a, port: 1}
+  - {host: b, port: 65535}
+`)
+		s := validatedSettings{Mode: "pull", Workers: 1}
+		require.NoError(t, c.UnpackWithOptions(&s, ValidateAll()))
+		assert.Equal(t, "push", s.Mode)
+		assert.Equal(t, 30*time.Second, s.Timeout)
+	})
+
+	t.Run("defaults are not validated", func(t *testing.T) {
+		s := validatedSettings{Timeout: 5 * time.Minute}
+		require.NoError(t, MustNewConfigFrom(`{name: test, endpoint.host: localhost}`).UnpackWithOptions(&s, ValidateAll()))
+	})
+
+	t.Run("all violations are reported", func(t *testing.T) {
+		c := MustNewConfigFrom(`
+mode: poll
+workers: 0
+ratio: 1.5
+timeout: 500ms
+endpoint: {port: 0}
+endpoints:
+  - {host: a, port: 70000}
+`)
+		var s validatedSettings
+		err := c.UnpackWithOptions(&s, ValidateAll())
+		require.Error(t, err)
+
+		var merr *multierror.MultiError
+		require.ErrorAs(t, err, &merr)
+		var messages []string
+		for _, err := range merr.Errors {
+			messages = append(messages, err.Error())
+		}
+		assert.Equal(t, []string{
+			"string value is not set accessing 'name'",
+			"value 'poll' is not one of (pull, push) accessing 'mode'",
+			"zero value accessing 'workers'",
+			"requires value <= 1 accessing 'ratio'",
+			"requires duration >= 1s accessing 'timeout'",
+			"string value is not set accessing 'endpoint.host'",
+			"requires value >= 1 accessing 'endpoint.port'",
+			"requires value <= 65535 accessing 'endpoints.0.port'",
+		}, messages)
+	})
+
+	t.Run("without option the first violation is reported", func(t *testing.T) {
+		var s validatedSettings
+		err := MustNewConfigFrom(`{name: test, mode: poll, workers: 0}`).Unpack(&s)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "value 'poll' is not one of (pull, push)")
+	})
+}
+
+type relatedOutput struct {
+	Hosts    []string `config:"hosts" validate:"mutually_exclusive=cloud_id"`
+	CloudID  string   `config:"cloud_id" validate:"mutually_exclusive=hosts"`
+	Username string   `config:"username" validate:"requires=password"`
+	Password string   `config:"password"`
+	APIKey   string   `config:"api_key" validate:"mutually_exclusive=username password"`
+}
+
+type relatedSettings struct {
+	Outputs []relatedOutput `config:"outputs"`
+	Output  relatedOutput   `config:"output"`
+}
+
+func relationErrors(t *testing.T, err error) []string {
+	t.Helper()
+	require.Error(t, err)
+
+	var merr *multierror.MultiError
+	require.ErrorAs(t, err, &merr)
+	var messages []string
+	for _, err := range merr.Errors {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
+
+func TestUnpackRelations(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		for _, cfg := range []string{
+			`{output: {hosts: [localhost], username: elastic, password: changeme}}`,
+			`{output: {cloud_id: "test:abc", api_key: key}}`,
+			`{output: {}}`,
+		} {
+			var s relatedSettings
+			assert.NoError(t, MustNewConfigFrom(cfg).Unpack(&s), cfg)
+			assert.NoError(t, MustNewConfigFrom(cfg).UnpackWithOptions(&s, ValidateAll()), cfg)
+		}
+	})
+
+	t.Run("mutually exclusive", func(t *testing.T) {
+		var s relatedSettings
+		err := MustNewConfigFrom(`{output: {hosts: [localhost], cloud_id: "test:abc"}}`).Unpack(&s)
+		assert.Equal(t, []string{
+			"setting is mutually exclusive with 'output.cloud_id' accessing 'output.hosts'",
+			"setting is mutually exclusive with 'output.hosts' accessing 'output.cloud_id'",
+		}, relationErrors(t, err))
+	})
+
+	t.Run("requires", func(t *testing.T) {
+		var s relatedSettings
+		err := MustNewConfigFrom(`{output: {hosts: [localhost], username: elastic}}`).Unpack(&s)
+		assert.Equal(t, []string{
+			"setting requires 'output.password' accessing 'output.username'",
+		}, relationErrors(t, err))
+	})
+
+	t.Run("all violations are reported", func(t *testing.T) {
+		c := MustNewConfigFrom(`
+output: {cloud_id: "test:abc", username: elastic, api_key: key}
+outputs:
+  - {hosts: [a], cloud_id: "test:abc"}
+  - {username: elastic}
+`)
+		var s relatedSettings
+		assert.Equal(t, []string{
+			"setting is mutually exclusive with 'outputs.0.cloud_id' accessing 'outputs.0.hosts'",
+			"setting is mutually exclusive with 'outputs.0.hosts' accessing 'outputs.0.cloud_id'",
+			"setting requires 'outputs.1.password' accessing 'outputs.1.username'",
+			"setting requires 'output.password' accessing 'output.username'",
+			"setting is mutually exclusive with 'output.username' accessing 'output.api_key'",
+		}, relationErrors(t, c.Unpack(&s)))
+		assert.Len(t, relationErrors(t, c.UnpackWithOptions(&s, ValidateAll())), 5)
+	})
+}
+
+func TestConstraintsAreNotRegisteredInUcfg(t *testing.T) {
+	// Other packages can register their own validators with these names.
+	for _, name := range []string{"oneof", "mutually_exclusive", "requires"} {
+		require.NoError(t, ucfg.RegisterValidator(name, func(interface{}, string) error { return nil }), name)
+	}
+
+	var s validatedSettings
+	err := MustNewConfigFrom(`{name: test, mode: poll}`).Unpack(&s)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "value 'poll' is not one of (pull, push)")
+
+	var r relatedSettings
+	err = MustNewConfigFrom(`{output: {username: elastic}}`).Unpack(&r)
+	assert.Equal(t, []string{"setting requires 'output.password' accessing 'output.username'"}, relationErrors(t, err))
+}
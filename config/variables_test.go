@@ -0,0 +1,79 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvVariableExpansion(t *testing.T) {
+	t.Setenv("TEST_CONFIG_HOST", "es.example.com")
+	t.Setenv("TEST_CONFIG_EMPTY", "")
+
+	tests := map[string]struct {
+		value    string
+		expected string
+	}{
+		"set":                         {value: "${TEST_CONFIG_HOST}", expected: "es.example.com"},
+		"set ignores default":         {value: "${TEST_CONFIG_HOST:localhost}", expected: "es.example.com"},
+		"unset with default":          {value: "${TEST_CONFIG_UNSET:localhost}", expected: "localhost"},
+		"empty with default":          {value: "${TEST_CONFIG_EMPTY:localhost}", expected: "localhost"},
+		"default with colons":         {value: "${TEST_CONFIG_UNSET:http://localhost:9200}", expected: "http://localhost:9200"},
+		"empty default":               {value: "${TEST_CONFIG_UNSET:}", expected: ""},
+		"set required":                {value: "${TEST_CONFIG_HOST:?host is required}", expected: "es.example.com"},
+		"nested default":              {value: "${TEST_CONFIG_UNSET:${TEST_CONFIG_HOST}}", expected: "es.example.com"},
+		"nested unset default":        {value: "${TEST_CONFIG_UNSET:${TEST_CONFIG_UNSET_TOO:localhost}}", expected: "localhost"},
+		"spliced":                     {value: "https://${TEST_CONFIG_HOST}:${TEST_CONFIG_PORT:9200}", expected: "https://es.example.com:9200"},
+		"escaped expansion":           {value: "$${TEST_CONFIG_HOST}", expected: "${TEST_CONFIG_HOST}"},
+		"escaped brace in default":    {value: "${TEST_CONFIG_UNSET:a$}b}", expected: "a}b"},
+		"reference to other settings": {value: "${other}", expected: "other value"},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			c := MustNewConfigFrom(map[string]interface{}{
+				"value": test.value,
+				"other": "other value",
+			})
+
+			var out struct {
+				Value string `config:"value"`
+			}
+			require.NoError(t, c.Unpack(&out))
+			assert.Equal(t, test.expected, out.Value)
+		})
+	}
+}
+
+func TestEnvVariableExpansionRequired(t *testing.T) {
+	c, err := NewConfigWithYAML([]byte(`
+output.elasticsearch.hosts: ["${TEST_CONFIG_UNSET:?TEST_CONFIG_UNSET must be set to the Elasticsearch host}"]
+`), "test.yml")
+	require.NoError(t, err)
+
+	var out struct {
+		Hosts []string `config:"output.elasticsearch.hosts"`
+	}
+	err = c.Unpack(&out)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "TEST_CONFIG_UNSET must be set to the Elasticsearch host")
+}
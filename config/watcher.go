@@ -0,0 +1,222 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWatchPeriod   = time.Second
+	defaultWatchDebounce = 500 * time.Millisecond
+)
+
+// Watcher periodically checks a set of configuration files and directories
+// and reloads them when they change. The callback is only invoked when the
+// effective, merged configuration differs from the last one loaded.
+//
+// Files are compared by modification time and size, so editors replacing a
+// file through a rename are detected the same way as in-place writes.
+type Watcher struct {
+	paths    []string
+	onChange func(*C)
+	period   time.Duration
+	debounce time.Duration
+
+	errs chan error
+	done chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+
+	files      map[string]fileState
+	current    map[string]interface{}
+	pending    bool
+	lastChange time.Time
+}
+
+// WatcherOption is a configuration option for Watcher.
+type WatcherOption func(w *Watcher)
+
+// WatchPeriod configures how often the watched paths are checked for
+// changes. The default is 1s.
+func WatchPeriod(d time.Duration) WatcherOption {
+	return func(w *Watcher) {
+		w.period = d
+	}
+}
+
+// WatchDebounce configures how long the watched paths must remain unchanged
+// before the configuration is reloaded. The default is 500ms.
+func WatchDebounce(d time.Duration) WatcherOption {
+	return func(w *Watcher) {
+		w.debounce = d
+	}
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewWatcher creates a Watcher for the given files and directories. The
+// configuration is loaded once to establish the baseline, an error is
+// returned if this fails. onChange is called from the watcher goroutine with
+// the new merged configuration.
+func NewWatcher(paths []string, onChange func(*C), opts ...WatcherOption) (*Watcher, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("no paths to watch")
+	}
+	if onChange == nil {
+		return nil, errors.New("onChange callback is required")
+	}
+
+	w := &Watcher{
+		paths:    paths,
+		onChange: onChange,
+		period:   defaultWatchPeriod,
+		debounce: defaultWatchDebounce,
+		errs:     make(chan error, 1),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.period <= 0 {
+		return nil, errors.New("watch period must be greater than 0")
+	}
+
+	files, err := w.scan()
+	if err != nil {
+		return nil, err
+	}
+	_, content, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+	w.files = files
+	w.current = content
+	return w, nil
+}
+
+// Start starts watching the configured paths in a new goroutine.
+func (w *Watcher) Start() {
+	w.wg.Add(1)
+	go w.run()
+}
+
+// Stop stops the watcher and waits for it to return. It is safe to call Stop
+// more than once.
+func (w *Watcher) Stop() {
+	w.once.Do(func() { close(w.done) })
+	w.wg.Wait()
+}
+
+// Errors returns a channel reporting the errors hit while reloading the
+// configuration. Errors are dropped if the channel is not drained.
+func (w *Watcher) Errors() <-chan error {
+	return w.errs
+}
+
+func (w *Watcher) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case now := <-ticker.C:
+			w.check(now)
+		}
+	}
+}
+
+func (w *Watcher) check(now time.Time) {
+	files, err := w.scan()
+	if err != nil {
+		// Files might be missing temporarily while being replaced.
+		w.reportError(err)
+		return
+	}
+
+	if !reflect.DeepEqual(files, w.files) {
+		w.files = files
+		w.pending = true
+		w.lastChange = now
+		return
+	}
+
+	if !w.pending || now.Sub(w.lastChange) < w.debounce {
+		return
+	}
+	w.pending = false
+
+	cfg, content, err := w.load()
+	if err != nil {
+		w.reportError(err)
+		return
+	}
+	if reflect.DeepEqual(content, w.current) {
+		return
+	}
+	w.current = content
+	w.onChange(cfg)
+}
+
+func (w *Watcher) scan() (map[string]fileState, error) {
+	files, err := expandConfigPaths(w.paths)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]fileState, len(files))
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		states[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+	return states, nil
+}
+
+func (w *Watcher) load() (*C, map[string]interface{}, error) {
+	cfg, err := LoadFiles(w.paths...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var content map[string]interface{}
+	if err := cfg.Unpack(&content); err != nil {
+		return nil, nil, err
+	}
+	return cfg, content, nil
+}
+
+func (w *Watcher) reportError(err error) {
+	select {
+	case w.errs <- err:
+	default:
+	}
+}
@@ -0,0 +1,105 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yml")
+	writeConfigFile(t, path, "name: initial\n")
+
+	changes := make(chan *C, 10)
+	w, err := NewWatcher([]string{dir}, func(c *C) { changes <- c },
+		WatchPeriod(10*time.Millisecond),
+		WatchDebounce(20*time.Millisecond),
+	)
+	require.NoError(t, err)
+	w.Start()
+	defer w.Stop()
+
+	t.Run("atomic replace triggers reload", func(t *testing.T) {
+		tmp := filepath.Join(dir, ".config.yml.tmp")
+		writeConfigFile(t, tmp, "name: replaced\n")
+		require.NoError(t, os.Rename(tmp, path))
+
+		c := waitForChange(t, changes)
+		name, err := c.String("name", -1)
+		require.NoError(t, err)
+		assert.Equal(t, "replaced", name)
+	})
+
+	t.Run("unchanged content does not trigger callback", func(t *testing.T) {
+		writeConfigFile(t, path, "# comment only\nname: replaced\n")
+		select {
+		case <-changes:
+			t.Fatal("callback invoked without an effective change")
+		case <-time.After(200 * time.Millisecond):
+		}
+	})
+
+	t.Run("new file in directory is merged", func(t *testing.T) {
+		writeConfigFile(t, filepath.Join(dir, "extra.yml"), "port: 8080\n")
+
+		c := waitForChange(t, changes)
+		port, err := c.Int("port", -1)
+		require.NoError(t, err)
+		assert.EqualValues(t, 8080, port)
+	})
+
+	t.Run("reload errors are reported", func(t *testing.T) {
+		writeConfigFile(t, path, "name: [unterminated\n")
+
+		select {
+		case err := <-w.Errors():
+			assert.Error(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for reload error")
+		}
+	})
+}
+
+func TestNewWatcherFailsOnMissingPath(t *testing.T) {
+	_, err := NewWatcher([]string{filepath.Join(t.TempDir(), "missing.yml")}, func(*C) {})
+	assert.Error(t, err)
+}
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+}
+
+func waitForChange(t *testing.T, changes <-chan *C) *C {
+	t.Helper()
+	select {
+	case c := <-changes:
+		return c
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for config change")
+	}
+	return nil
+}
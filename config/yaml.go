@@ -0,0 +1,58 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// ToYAML renders the configuration as a YAML document. Variables are resolved
+// and values keep their types, so loading the document with
+// NewConfigWithYAML results in the same configuration.
+func (c *C) ToYAML() ([]byte, error) {
+	return c.toYAML(false)
+}
+
+// ToRedactedYAML renders the configuration as a YAML document like ToYAML,
+// with the values of the keys that might contain sensitive data masked. See
+// ApplyLoggingMask.
+func (c *C) ToRedactedYAML() ([]byte, error) {
+	return c.toYAML(true)
+}
+
+func (c *C) toYAML(redact bool) ([]byte, error) {
+	var content interface{}
+	if c.IsArray() {
+		var arr []interface{}
+		if err := c.Unpack(&arr); err != nil {
+			return nil, err
+		}
+		content = arr
+	} else {
+		dict := map[string]interface{}{}
+		if err := c.Unpack(&dict); err != nil {
+			return nil, err
+		}
+		content = dict
+	}
+
+	if redact {
+		ApplyLoggingMask(content)
+	}
+	return yaml.Marshal(content)
+}
@@ -0,0 +1,130 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const yamlRoundTripConfig = `
+name: ${NAME}
+port: 9200
+ratio: 0.5
+enabled: true
+version: "8.0"
+timeout: 30s
+output.elasticsearch:
+  hosts: ["https://es1:9200", "https://es2:9200"]
+  password: changeme
+  headers:
+    X-Custom: value
+inputs:
+  - type: log
+    paths: [/var/log/*.log]
+  - type: metrics
+    period: 10
+    processors:
+      - add_fields: {target: project, fields: {id: 42}}
+`
+
+func TestToYAMLRoundTrip(t *testing.T) {
+	t.Setenv("NAME", "beat1")
+	c, err := NewConfigWithYAML([]byte(yamlRoundTripConfig), "test")
+	require.NoError(t, err)
+
+	out, err := c.ToYAML()
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "name: beat1\n")
+	assert.Contains(t, string(out), "port: 9200\n")
+	assert.Contains(t, string(out), "version: \"8.0\"\n")
+
+	reloaded, err := NewConfigWithYAML(out, "reloaded")
+	require.NoError(t, err)
+
+	var expected, actual map[string]interface{}
+	require.NoError(t, c.Unpack(&expected))
+	require.NoError(t, reloaded.Unpack(&actual))
+	assert.Equal(t, expected, actual)
+
+	port, err := reloaded.Int("port", -1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(9200), port)
+	version, err := reloaded.String("version", -1)
+	require.NoError(t, err)
+	assert.Equal(t, "8.0", version)
+	enabled, err := reloaded.Bool("enabled", -1)
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	var inputs []struct {
+		Type   string   `config:"type"`
+		Paths  []string `config:"paths"`
+		Period int      `config:"period"`
+	}
+	sub, err := reloaded.Child("inputs", -1)
+	require.NoError(t, err)
+	require.NoError(t, sub.Unpack(&inputs))
+	require.Len(t, inputs, 2)
+	assert.Equal(t, []string{"/var/log/*.log"}, inputs[0].Paths)
+	assert.Equal(t, 10, inputs[1].Period)
+}
+
+func TestToYAMLArray(t *testing.T) {
+	c := MustNewConfigFrom([]interface{}{
+		map[string]interface{}{"id": 1},
+		map[string]interface{}{"id": 2},
+	})
+
+	out, err := c.ToYAML()
+	require.NoError(t, err)
+	assert.Equal(t, "- id: 1\n- id: 2\n", string(out))
+}
+
+func TestToRedactedYAML(t *testing.T) {
+	t.Setenv("NAME", "beat1")
+	c, err := NewConfigWithYAML([]byte(yamlRoundTripConfig), "test")
+	require.NoError(t, err)
+
+	out, err := c.ToRedactedYAML()
+	require.NoError(t, err)
+
+	reloaded, err := NewConfigWithYAML(out, "reloaded")
+	require.NoError(t, err)
+
+	password, err := reloaded.String("output.elasticsearch.password", -1)
+	require.NoError(t, err)
+	assert.Equal(t, mask, password)
+
+	var hosts []string
+	sub, err := reloaded.Child("output.elasticsearch.hosts", -1)
+	require.NoError(t, err)
+	require.NoError(t, sub.Unpack(&hosts))
+	assert.Equal(t, []string{mask, mask}, hosts)
+
+	port, err := reloaded.Int("port", -1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(9200), port)
+
+	// the configuration itself is not modified
+	password, err = c.String("output.elasticsearch.password", -1)
+	require.NoError(t, err)
+	assert.Equal(t, "changeme", password)
+}
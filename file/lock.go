@@ -0,0 +1,117 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package file
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// ErrLocked is returned by TryLock if the lock is held by someone else.
+var ErrLocked = errors.New("file is locked")
+
+// FileLock is an advisory exclusive lock on a file, used to prevent multiple
+// processes from using the same state files. It is implemented with flock on
+// Unix and LockFileEx on Windows. The operating system releases the lock when
+// the process exits, so a crashed process does not leave a stale lock behind.
+// The lock file itself is not removed on Unlock.
+type FileLock struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileLock creates an unlocked FileLock for path. The file is created when
+// the lock is acquired if it does not exist.
+func NewFileLock(path string) *FileLock {
+	return &FileLock{path: path}
+}
+
+// Lock acquires an exclusive lock on path, blocking until it is available.
+func Lock(path string) (*FileLock, error) {
+	l := NewFileLock(path)
+	if err := l.Lock(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Lock acquires the lock, blocking until it is available.
+func (l *FileLock) Lock() error {
+	return l.lock(true)
+}
+
+// TryLock acquires the lock without blocking. ErrLocked is returned if the
+// lock is held by another process or FileLock.
+func (l *FileLock) TryLock() error {
+	return l.lock(false)
+}
+
+func (l *FileLock) lock(wait bool) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file != nil {
+		return fmt.Errorf("lock on %s is already held", l.path)
+	}
+
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := lockFile(f, wait); err != nil {
+		f.Close()
+		if errors.Is(err, ErrLocked) {
+			return err
+		}
+		return fmt.Errorf("failed to lock %s: %w", l.path, err)
+	}
+
+	l.file = f
+	return nil
+}
+
+// Unlock releases the lock. Unlocking a FileLock that is not locked is a
+// no-op.
+func (l *FileLock) Unlock() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+
+	err := unlockFile(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to unlock %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// Path returns the path of the lock file.
+func (l *FileLock) Path() string {
+	return l.path
+}
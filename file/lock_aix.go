@@ -0,0 +1,50 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package file
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// AIX does not provide flock, so fcntl record locks are used. These locks
+// are held per process, so they only exclude other processes.
+func lockFile(f *os.File, wait bool) error {
+	cmd := unix.F_SETLK
+	if wait {
+		cmd = unix.F_SETLKW
+	}
+	lock := unix.Flock_t{Type: unix.F_WRLCK, Whence: 0}
+	for {
+		err := unix.FcntlFlock(f.Fd(), cmd, &lock)
+		switch {
+		case errors.Is(err, unix.EINTR):
+			continue
+		case errors.Is(err, unix.EAGAIN), errors.Is(err, unix.EACCES):
+			return ErrLocked
+		}
+		return err
+	}
+}
+
+func unlockFile(f *os.File) error {
+	lock := unix.Flock_t{Type: unix.F_UNLCK, Whence: 0}
+	return unix.FcntlFlock(f.Fd(), unix.F_SETLK, &lock)
+}
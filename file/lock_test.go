@@ -0,0 +1,116 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+// +build !integration
+
+package file
+
+import (
+	"bufio"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lockHelperEnv = "FILE_LOCK_HELPER_PATH"
+
+// TestLockHelperProcess is not a real test. It is run as a separate process
+// by TestLockAcrossProcesses to hold the lock until its stdin is closed.
+func TestLockHelperProcess(t *testing.T) {
+	path := os.Getenv(lockHelperEnv)
+	if path == "" {
+		t.Skip("helper process for TestLockAcrossProcesses")
+	}
+
+	if _, err := Lock(path); err != nil {
+		os.Exit(1)
+	}
+	os.Stdout.WriteString("locked\n")
+	_, _ = ioutil.ReadAll(os.Stdin)
+	// exit without unlocking, the lock is released by the operating system.
+	os.Exit(0)
+}
+
+func TestLockAcrossProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.lock")
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestLockHelperProcess$")
+	cmd.Env = append(os.Environ(), lockHelperEnv+"="+path)
+	stdin, err := cmd.StdinPipe()
+	require.NoError(t, err)
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+	defer func() { _ = cmd.Process.Kill() }()
+
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "locked\n", line)
+
+	l := NewFileLock(path)
+	assert.True(t, errors.Is(l.TryLock(), ErrLocked))
+
+	acquired := make(chan error, 1)
+	go func() { acquired <- l.Lock() }()
+
+	select {
+	case err := <-acquired:
+		t.Fatalf("lock acquired while held by another process: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	require.NoError(t, stdin.Close())
+	require.NoError(t, cmd.Wait())
+
+	select {
+	case err := <-acquired:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("lock not acquired after the other process exited")
+	}
+	assert.NoError(t, l.Unlock())
+}
+
+func TestLockTryLockUnlock(t *testing.T) {
+	if runtime.GOOS == "aix" {
+		t.Skip("fcntl locks do not exclude locks held by the same process")
+	}
+	path := filepath.Join(t.TempDir(), "state.lock")
+
+	first, err := Lock(path)
+	require.NoError(t, err)
+	assert.Error(t, first.TryLock(), "lock is already held")
+
+	second := NewFileLock(path)
+	assert.True(t, errors.Is(second.TryLock(), ErrLocked))
+
+	require.NoError(t, first.Unlock())
+	require.NoError(t, first.Unlock())
+
+	require.NoError(t, second.TryLock())
+	require.NoError(t, second.Unlock())
+	assert.FileExists(t, path)
+}
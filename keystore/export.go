@@ -0,0 +1,112 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package keystore
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// Version of the export format, added at the beginning of the exported data.
+var exportVersion = []byte("export-v1")
+
+// ErrMissingExportPassword is returned when exporting or importing secrets
+// without a password.
+var ErrMissingExportPassword = errors.New("a password is required to export or import a keystore")
+
+// Export returns the secrets of the keystore encrypted with the given
+// password, to be restored on another host with Import.
+//
+// The exported data is independent of the keystore file: it is encrypted with
+// AES-256-GCM using a key derived from password with PBKDF2 and a random
+// salt, the password of the keystore is never part of it. The exported data
+// is as sensitive as the secrets it holds: anyone getting it can try to guess
+// the password offline, so a strong password must be used, and the data must
+// be deleted once imported. The format is versioned to allow changing the
+// encryption scheme in the future.
+func (k *FileKeystore) Export(password []byte) ([]byte, error) {
+	if len(password) == 0 {
+		return nil, ErrMissingExportPassword
+	}
+
+	k.RLock()
+	w := new(bytes.Buffer)
+	err := json.NewEncoder(w).Encode(k.secrets)
+	k.RUnlock()
+	if err != nil {
+		return nil, fmt.Errorf("cannot serialize the keystore before exporting it: %w", err)
+	}
+
+	encrypted, err := encryptWithPassword(password, w)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encrypt the exported keystore: %w", err)
+	}
+	raw, err := ioutil.ReadAll(encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(exportVersion)+base64.StdEncoding.EncodedLen(len(raw)))
+	copy(out, exportVersion)
+	base64.StdEncoding.Encode(out[len(exportVersion):], raw)
+	return out, nil
+}
+
+// Import adds the secrets exported by Export to the keystore, existing keys
+// are replaced. The keystore is encrypted with its own password when saved,
+// Save must be called to persist the imported secrets.
+func (k *FileKeystore) Import(data, password []byte) error {
+	if len(password) == 0 {
+		return ErrMissingExportPassword
+	}
+
+	if !bytes.HasPrefix(data, exportVersion) {
+		return fmt.Errorf("exported keystore format doesn't match expected version: '%s'", exportVersion)
+	}
+
+	raw := make([]byte, base64.StdEncoding.DecodedLen(len(data)-len(exportVersion)))
+	n, err := base64.StdEncoding.Decode(raw, data[len(exportVersion):])
+	if err != nil {
+		return fmt.Errorf("corrupt exported keystore: %w", err)
+	}
+
+	plaintext, err := decryptWithPassword(password, bytes.NewReader(raw[:n]))
+	if err != nil {
+		return fmt.Errorf("could not decrypt the exported keystore: %w", err)
+	}
+
+	var secrets map[string]serializableSecureString
+	if err := json.NewDecoder(plaintext).Decode(&secrets); err != nil {
+		return fmt.Errorf("could not read the exported keystore: %w", err)
+	}
+
+	k.Lock()
+	defer k.Unlock()
+	if k.secrets == nil {
+		k.secrets = make(map[string]serializableSecureString, len(secrets))
+	}
+	for key, secret := range secrets {
+		k.secrets[key] = secret
+	}
+	k.dirty = true
+	return nil
+}
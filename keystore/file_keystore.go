@@ -334,6 +334,13 @@ func (k *FileKeystore) load() error {
 
 // Encrypt the data payload using a derived keys and the AES-256-GCM algorithm.
 func (k *FileKeystore) encrypt(reader io.Reader) (io.Reader, error) {
+	password, _ := k.password.Get()
+	return encryptWithPassword(password, reader)
+}
+
+// encryptWithPassword encrypts the data payload using a key derived from
+// password and the AES-256-GCM algorithm.
+func encryptWithPassword(password []byte, reader io.Reader) (io.Reader, error) {
 	// randomly generate the salt and the initialization vector, this information will be saved
 	// on disk in the file as part of the header
 	iv, err := randomBytes(iVLength)
@@ -348,8 +355,7 @@ func (k *FileKeystore) encrypt(reader io.Reader) (io.Reader, error) {
 	}
 
 	// Stretch the user provided key
-	password, _ := k.password.Get()
-	passwordBytes := k.hashPassword(password, salt)
+	passwordBytes := hashPassword(password, salt)
 
 	// Select AES-256: because len(passwordBytes) == 32 bytes
 	block, err := aes.NewCipher(passwordBytes)
@@ -380,6 +386,12 @@ func (k *FileKeystore) encrypt(reader io.Reader) (io.Reader, error) {
 
 // should receive an io.reader...
 func (k *FileKeystore) decrypt(reader io.Reader) (io.Reader, error) {
+	password, _ := k.password.Get()
+	return decryptWithPassword(password, reader)
+}
+
+// decryptWithPassword decrypts data encrypted by encryptWithPassword.
+func decryptWithPassword(password []byte, reader io.Reader) (io.Reader, error) {
 	data, err := ioutil.ReadAll(reader)
 	if err != nil {
 		return nil, fmt.Errorf("could not read all the data from the encrypted file, error: %w", err)
@@ -394,8 +406,7 @@ func (k *FileKeystore) decrypt(reader io.Reader) (io.Reader, error) {
 	iv := data[saltLength : saltLength+iVLength]
 	encodedBytes := data[saltLength+iVLength:]
 
-	password, _ := k.password.Get()
-	passwordBytes := k.hashPassword(password, salt)
+	passwordBytes := hashPassword(password, salt)
 
 	block, err := aes.NewCipher(passwordBytes)
 	if err != nil {
@@ -463,7 +474,7 @@ func (k *FileKeystore) ConfiguredPath() string {
 	return k.Path
 }
 
-func (k *FileKeystore) hashPassword(password, salt []byte) []byte {
+func hashPassword(password, salt []byte) []byte {
 	return pbkdf2.Key(password, salt, iterationsCount, keyLength, sha512.New)
 }
 
@@ -193,6 +193,58 @@ func TestCannotDecryptKeyStoreWithWrongPassword(t *testing.T) {
 	}
 }
 
+func TestExportImportAcrossKeystores(t *testing.T) {
+	sourcePath := GetTemporaryKeystoreFile()
+	defer os.Remove(sourcePath)
+	targetPath := GetTemporaryKeystoreFile()
+	defer os.Remove(targetPath)
+
+	source, err := NewFileKeystoreWithPassword(sourcePath, NewSecureString([]byte("source-password")))
+	require.NoError(t, err)
+	writableSource, err := AsWritableKeystore(source)
+	require.NoError(t, err)
+	require.NoError(t, writableSource.Store(keyValue, secretValue))
+	require.NoError(t, writableSource.Store("hello", []byte("world")))
+	require.NoError(t, writableSource.Save())
+
+	exportPassword := []byte("export-password")
+	exported, err := source.(*FileKeystore).Export(exportPassword)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(exported, []byte("export-v1")))
+	assert.NotContains(t, string(exported), string(secretValue))
+
+	_, err = source.(*FileKeystore).Export(nil)
+	assert.ErrorIs(t, err, ErrMissingExportPassword)
+
+	target, err := NewFileKeystoreWithPassword(targetPath, NewSecureString([]byte("target-password")))
+	require.NoError(t, err)
+	targetKeystore := target.(*FileKeystore)
+
+	err = targetKeystore.Import(exported, []byte("wrong-password"))
+	assert.Error(t, err)
+	err = targetKeystore.Import(bytes.TrimPrefix(exported, []byte("export-v1")), exportPassword)
+	assert.Error(t, err)
+
+	require.NoError(t, targetKeystore.Import(exported, exportPassword))
+	require.NoError(t, targetKeystore.Save())
+
+	// The imported secrets are persisted with the password of the target keystore.
+	_, err = NewFileKeystoreWithPassword(targetPath, NewSecureString([]byte("source-password")))
+	assert.Error(t, err)
+
+	reloaded, err := NewFileKeystoreWithPassword(targetPath, NewSecureString([]byte("target-password")))
+	require.NoError(t, err)
+	keys, err := reloaded.(ListingKeystore).List()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{keyValue, "hello"}, keys)
+
+	secret, err := reloaded.Retrieve(keyValue)
+	require.NoError(t, err)
+	value, err := secret.Get()
+	require.NoError(t, err)
+	assert.Equal(t, secretValue, value)
+}
+
 func TestUserDefinedPasswordUTF8(t *testing.T) {
 	createAndReadKeystoreWithPassword(t, []byte("mysecret¥¥password"))
 }
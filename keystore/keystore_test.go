@@ -24,6 +24,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/elastic/elastic-agent-libs/config"
+
 	ucfg "github.com/elastic/go-ucfg"
 	"github.com/elastic/go-ucfg/parse"
 )
@@ -63,3 +65,113 @@ func TestResolverWhenTheKeyExist(t *testing.T) {
 	require.True(t, ok, "parsed secret is not a string")
 	require.Equal(t, string(secretValue), secret)
 }
+
+type memoryKeystore map[string][]byte
+
+func (m memoryKeystore) Retrieve(key string) (*SecureString, error) {
+	v, ok := m[key]
+	if !ok {
+		return nil, ErrKeyDoesntExists
+	}
+	return NewSecureString(v), nil
+}
+
+func (m memoryKeystore) GetConfig() (*config.C, error) { return config.NewConfig(), nil }
+
+func (m memoryKeystore) IsPersisted() bool { return false }
+
+func TestResolveSecrets(t *testing.T) {
+	store := memoryKeystore{
+		"es.password": []byte("p@ss:${word}"),
+		"es.port":     []byte("9200"),
+	}
+
+	var settings struct {
+		Username string `config:"username"`
+		Password string `config:"password"`
+		Port     int    `config:"port"`
+		Enabled  bool   `config:"enabled"`
+	}
+
+	t.Run("resolves keys", func(t *testing.T) {
+		c := config.MustNewConfigFrom(map[string]interface{}{
+			"username": "elastic",
+			"password": "${keystore.es.password}",
+			"port":     "${keystore.es.port}",
+			"enabled":  "yes",
+		})
+		err := c.UnpackWithOptions(&settings, ResolveSecrets(store), config.ParseBools(config.BoolParsingLenient))
+		require.NoError(t, err)
+		assert.Equal(t, "elastic", settings.Username)
+		assert.Equal(t, "p@ss:${word}", settings.Password)
+		assert.Equal(t, 9200, settings.Port)
+		assert.True(t, settings.Enabled)
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		c := config.MustNewConfigFrom(map[string]interface{}{
+			"password": "${keystore.es.missing}",
+		})
+		err := c.UnpackWithOptions(&settings, ResolveSecrets(store))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "key 'es.missing' not found in the keystore")
+	})
+
+	t.Run("not resolved by Unpack", func(t *testing.T) {
+		c := config.MustNewConfigFrom(map[string]interface{}{
+			"password": "${keystore.es.password}",
+		})
+		require.Error(t, c.Unpack(&settings))
+	})
+
+	t.Run("keystore value is not modified", func(t *testing.T) {
+		c := config.MustNewConfigFrom(map[string]interface{}{
+			"password": "${keystore.es.password}",
+		})
+		for i := 0; i < 2; i++ {
+			require.NoError(t, c.UnpackWithOptions(&settings, ResolveSecrets(store)))
+			assert.Equal(t, "p@ss:${word}", settings.Password)
+		}
+		assert.Equal(t, []byte("p@ss:${word}"), store["es.password"])
+
+		v, err := retrieve(store, "es.password")
+		require.NoError(t, err)
+		zero(v)
+		assert.Equal(t, []byte("p@ss:${word}"), store["es.password"])
+	})
+}
+
+func TestLazySecrets(t *testing.T) {
+	store := memoryKeystore{"es.password": []byte("hunter2")}
+	reveal := func(t *testing.T, s config.LazySecret) string {
+		var value string
+		require.NoError(t, s.Reveal(func(b []byte) error {
+			value = string(b)
+			return nil
+		}))
+		return value
+	}
+
+	t.Run("retrieve", func(t *testing.T) {
+		s, err := RetrieveLazySecret(store, "es.password")
+		require.NoError(t, err)
+		assert.Equal(t, "hunter2", reveal(t, s))
+		// the keystore buffer is not modified
+		assert.Equal(t, []byte("hunter2"), store["es.password"])
+
+		_, err = RetrieveLazySecret(store, "es.missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "key 'es.missing' not found in the keystore")
+	})
+
+	t.Run("unpack", func(t *testing.T) {
+		var settings struct {
+			Password config.LazySecret `config:"password"`
+		}
+		c := config.MustNewConfigFrom(map[string]interface{}{
+			"password": "${keystore.es.password}",
+		})
+		require.NoError(t, c.UnpackWithOptions(&settings, ResolveSecrets(store)))
+		assert.Equal(t, "hunter2", reveal(t, settings.Password))
+	})
+}
@@ -0,0 +1,84 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package keystore
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/elastic/elastic-agent-libs/config"
+)
+
+// VariablePrefix is the prefix of the variables resolved by ResolveSecrets.
+const VariablePrefix = "keystore"
+
+// ResolveSecrets returns an option making config.C.UnpackWithOptions
+// resolve the variables `${keystore.<key>}` with the secrets of store, e.g.
+// `password: ${keystore.es.password}`. Unpacking fails if a key is not in the
+// store. Secrets are not parsed, they are used as strings.
+//
+// The secret is copied from the keystore and the copy is zeroed once it is
+// converted to the string given to the unpacker. The string itself cannot
+// be zeroed.
+func ResolveSecrets(store Keystore) config.UnpackOption {
+	return config.WithVariableProvider(VariablePrefix, func(key string) (string, error) {
+		v, err := retrieve(store, key)
+		if err != nil {
+			return "", err
+		}
+		defer zero(v)
+		return string(v), nil
+	})
+}
+
+// RetrieveLazySecret returns the secret key of store as a config.LazySecret,
+// encrypted in memory until it is revealed. The value is copied from the
+// keystore buffer without going through a string, the copy is zeroed once it
+// is encrypted.
+func RetrieveLazySecret(store Keystore, key string) (config.LazySecret, error) {
+	v, err := retrieve(store, key)
+	if err != nil {
+		return config.LazySecret{}, err
+	}
+	defer zero(v)
+	return config.NewLazySecret(v)
+}
+
+// retrieve returns a copy of the secret key of store, the slice returned by
+// SecureString.Get is the value kept by the keystore and must not be
+// modified.
+func retrieve(store Keystore, key string) ([]byte, error) {
+	secret, err := store.Retrieve(key)
+	if err != nil {
+		if errors.Is(err, ErrKeyDoesntExists) {
+			return nil, fmt.Errorf("key '%v' not found in the keystore", key)
+		}
+		return nil, err
+	}
+	value, err := secret.Get()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), value...), nil
+}
+
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -54,6 +54,11 @@ type Connection struct {
 
 	HTTP    *http.Client
 	Version version.V
+
+	// OnWarning, if set, is called with the warnings of the Warning headers
+	// of every response, e.g. the use of a deprecated API. The clients
+	// created by NewClientWithConfig log each warning once.
+	OnWarning func(Warning)
 }
 
 type Client struct {
@@ -149,7 +154,21 @@ func NewClientWithConfigDefault(config *ClientConfig, defaultPort int, binaryNam
 	if config.SpaceID != "" {
 		p = path.Join(p, "s", config.SpaceID)
 	}
-	kibanaURL, err := MakeURL(config.Protocol, p, config.Host, defaultPort)
+	host := config.Host
+	var transportOpts []httpcommon.TransportOption
+	if httpcommon.IsUnixSocketURL(host) {
+		socketHost, socketPath, err := httpcommon.ParseUnixSocketURL(host)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Kibana host: %w", err)
+		}
+		host = socketHost
+		// The HTTP requests are addressed to the host, without port, while
+		// the connections are made to the unix socket.
+		defaultPort = 0
+		transportOpts = append(transportOpts, httpcommon.WithUnixSocket(socketPath))
+	}
+
+	kibanaURL, err := MakeURL(config.Protocol, p, host, defaultPort)
 	if err != nil {
 		return nil, fmt.Errorf("invalid Kibana host: %w", err)
 	}
@@ -167,8 +186,8 @@ func NewClientWithConfigDefault(config *ClientConfig, defaultPort int, binaryNam
 		password, _ = u.User.Password()
 		u.User = nil
 
-		if config.APIKey != "" && (username != "" || password != "") {
-			return nil, fmt.Errorf("cannot set api_key with username/password in Kibana URL")
+		if err := validateAuth(config.APIKey, config.ServiceToken, username, password); err != nil {
+			return nil, fmt.Errorf("invalid credentials in Kibana URL: %w", err)
 		}
 
 		// Re-write URL without credentials.
@@ -187,7 +206,8 @@ func NewClientWithConfigDefault(config *ClientConfig, defaultPort int, binaryNam
 		binaryName = "Libbeat"
 	}
 	userAgent := useragent.UserAgent(binaryName, version, commit, buildtime)
-	rt, err := config.Transport.Client(httpcommon.WithHeaderRoundTripper(map[string]string{"User-Agent": userAgent}))
+	transportOpts = append(transportOpts, httpcommon.WithHeaderRoundTripper(map[string]string{"User-Agent": userAgent}))
+	rt, err := config.Transport.Client(transportOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -201,6 +221,9 @@ func NewClientWithConfigDefault(config *ClientConfig, defaultPort int, binaryNam
 			ServiceToken: config.ServiceToken,
 			Headers:      headers,
 			HTTP:         rt,
+			OnWarning: DedupWarnings(func(w Warning) {
+				log.Warnf("Kibana returned a warning: %s", w.Text)
+			}),
 		},
 		log: log,
 	}
@@ -291,7 +314,13 @@ func addHeaders(out, in http.Header) {
 
 // Implements RoundTrip interface
 func (conn *Connection) RoundTrip(r *http.Request) (*http.Response, error) {
-	return conn.HTTP.Do(r)
+	resp, err := conn.HTTP.Do(r)
+	if err == nil && conn.OnWarning != nil {
+		for _, w := range ParseWarnings(resp.Header) {
+			conn.OnWarning(w)
+		}
+	}
+	return resp, err
 }
 
 func (client *Client) readVersion() error {
@@ -19,6 +19,7 @@ package kibana
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/elastic/elastic-agent-libs/transport/httpcommon"
 )
@@ -57,10 +58,29 @@ func DefaultClientConfig() ClientConfig {
 	}
 }
 
+// Validate checks that at most one authentication method is configured.
 func (c *ClientConfig) Validate() error {
-	if c.APIKey != "" && (c.Username != "" || c.Password != "") {
-		return fmt.Errorf("cannot set both api_key and username/password")
+	return validateAuth(c.APIKey, c.ServiceToken, c.Username, c.Password)
+}
+
+func validateAuth(apiKey, serviceToken, username, password string) error {
+	var methods []string
+	if apiKey != "" {
+		methods = append(methods, "api_key")
+	}
+	if serviceToken != "" {
+		methods = append(methods, "service_token")
+	}
+	if username != "" || password != "" {
+		methods = append(methods, "username/password")
 	}
 
-	return nil
+	switch len(methods) {
+	case 0, 1:
+		return nil
+	case 2:
+		return fmt.Errorf("cannot set both %s and %s", methods[0], methods[1])
+	default:
+		return fmt.Errorf("cannot set more than one of %s", strings.Join(methods, ", "))
+	}
 }
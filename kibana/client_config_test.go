@@ -59,6 +59,29 @@ func TestClientConfigValdiate(t *testing.T) {
 			APIKey:   "apiKey",
 		},
 		err: fmt.Errorf("cannot set both api_key and username/password"),
+	}, {
+		name: "api_key and service_token",
+		c: &ClientConfig{
+			APIKey:       "apiKey",
+			ServiceToken: "service_token",
+		},
+		err: fmt.Errorf("cannot set both api_key and service_token"),
+	}, {
+		name: "password and service_token",
+		c: &ClientConfig{
+			Password:     "pass",
+			ServiceToken: "service_token",
+		},
+		err: fmt.Errorf("cannot set both service_token and username/password"),
+	}, {
+		name: "all auth methods",
+		c: &ClientConfig{
+			Username:     "user",
+			Password:     "pass",
+			APIKey:       "apiKey",
+			ServiceToken: "service_token",
+		},
+		err: fmt.Errorf("cannot set more than one of api_key, service_token, username/password"),
 	}}
 
 	for _, tt := range tests {
@@ -0,0 +1,158 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kibana
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const savedObjectsFindAPI = "/api/saved_objects/_find"
+
+const defaultFindPerPage = 100
+
+// SavedObject is a saved object returned by the saved objects API.
+type SavedObject struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id"`
+	Attributes map[string]interface{} `json:"attributes"`
+	References []SavedObjectReference `json:"references"`
+	Namespaces []string               `json:"namespaces"`
+	UpdatedAt  string                 `json:"updated_at"`
+	Version    string                 `json:"version"`
+}
+
+// SavedObjectReference is a reference from a saved object to another one.
+type SavedObjectReference struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// FindParams selects the saved objects returned by FindAll.
+type FindParams struct {
+	// Types of the saved objects to find, at least one is required.
+	Types []string
+	// Search is a simple_query_string query matched against SearchFields.
+	Search       string
+	SearchFields []string
+	// Fields limits the attributes returned, all are returned by default.
+	Fields []string
+	// PerPage is the number of objects requested per page, 100 by default.
+	PerPage int
+	// Space is the ID of the space to search, the default space if empty.
+	Space string
+}
+
+type findResponse struct {
+	Page         int           `json:"page"`
+	PerPage      int           `json:"per_page"`
+	Total        int           `json:"total"`
+	SavedObjects []SavedObject `json:"saved_objects"`
+}
+
+// FindAll returns an iterator over the pages of saved objects matching
+// params. Every call of the iterator requests the next page, it returns
+// io.EOF once all pages were returned. If a page fails, the error is returned
+// and the next call requests the same page again. The iterator stops with
+// the error of ctx once it is done.
+func (client *Client) FindAll(ctx context.Context, params FindParams) (func() ([]SavedObject, error), error) {
+	if len(params.Types) == 0 {
+		return nil, errors.New("at least one saved object type is required")
+	}
+	if params.PerPage <= 0 {
+		params.PerPage = defaultFindPerPage
+	}
+
+	path := savedObjectsFindAPI
+	if params.Space != "" {
+		path = "/s/" + url.PathEscape(params.Space) + path
+	}
+
+	query := url.Values{}
+	query.Set("per_page", strconv.Itoa(params.PerPage))
+	for _, t := range params.Types {
+		query.Add("type", t)
+	}
+	if params.Search != "" {
+		query.Set("search", params.Search)
+	}
+	for _, f := range params.SearchFields {
+		query.Add("search_fields", f)
+	}
+	for _, f := range params.Fields {
+		query.Add("fields", f)
+	}
+
+	page, done := 1, false
+	return func() ([]SavedObject, error) {
+		if done {
+			return nil, io.EOF
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		query.Set("page", strconv.Itoa(page))
+		resp, err := client.findPage(ctx, path, query)
+		if err != nil {
+			return nil, fmt.Errorf("fail to get page %d of saved objects: %w", page, err)
+		}
+
+		if len(resp.SavedObjects) == 0 || page*params.PerPage >= resp.Total {
+			done = true
+		}
+		page++
+		if len(resp.SavedObjects) == 0 {
+			return nil, io.EOF
+		}
+		return resp.SavedObjects, nil
+	}, nil
+}
+
+func (client *Client) findPage(ctx context.Context, path string, query url.Values) (*findResponse, error) {
+	resp, err := client.Connection.SendWithContext(ctx, http.MethodGet, path, query, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fail to execute the HTTP GET request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	result, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("HTTP GET request to %s%s fails with status %d. Response: %s",
+			client.Connection.URL, path, resp.StatusCode, truncateString(result))
+	}
+
+	var page findResponse
+	if err := json.Unmarshal(result, &page); err != nil {
+		return nil, fmt.Errorf("fail to unmarshal the response from GET %s%s. Response: %s: %w",
+			client.Connection.URL, path, truncateString(result), err)
+	}
+	return &page, nil
+}
@@ -0,0 +1,155 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kibana
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// findServer serves total dashboards in pages. The page set in failPage
+// fails once.
+func findServer(t *testing.T, total int, failPage int) (*httptest.Server, *[]string) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/s/ops"+savedObjectsFindAPI {
+			http.NotFound(w, r)
+			return
+		}
+		requests = append(requests, r.URL.RawQuery)
+
+		q := r.URL.Query()
+		page, _ := strconv.Atoi(q.Get("page"))
+		perPage, _ := strconv.Atoi(q.Get("per_page"))
+		if page == failPage {
+			failPage = 0
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"message":"internal error"}`))
+			return
+		}
+
+		resp := findResponse{Page: page, PerPage: perPage, Total: total, SavedObjects: []SavedObject{}}
+		for i := (page - 1) * perPage; i < page*perPage && i < total; i++ {
+			resp.SavedObjects = append(resp.SavedObjects, SavedObject{Type: q.Get("type"), ID: fmt.Sprintf("dashboard-%d", i)})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func newFindClient(t *testing.T, url string) *Client {
+	client, err := NewClientWithConfig(&ClientConfig{Host: url, IgnoreVersion: true}, binaryName, v, commit, buildTime)
+	require.NoError(t, err)
+	return client
+}
+
+func collectPages(t *testing.T, next func() ([]SavedObject, error)) []int {
+	var sizes []int
+	for {
+		objects, err := next()
+		if errors.Is(err, io.EOF) {
+			return sizes
+		}
+		require.NoError(t, err)
+		sizes = append(sizes, len(objects))
+	}
+}
+
+func TestFindAll(t *testing.T) {
+	params := FindParams{Types: []string{"dashboard"}, Search: "web*", PerPage: 2, Space: "ops"}
+
+	t.Run("multiple pages", func(t *testing.T) {
+		server, requests := findServer(t, 5, 0)
+		next, err := newFindClient(t, server.URL).FindAll(context.Background(), params)
+		require.NoError(t, err)
+
+		assert.Equal(t, []int{2, 2, 1}, collectPages(t, next))
+		assert.Len(t, *requests, 3)
+		assert.Equal(t, "page=3&per_page=2&search=web%2A&type=dashboard", (*requests)[2])
+
+		_, err = next()
+		assert.Equal(t, io.EOF, err)
+	})
+
+	t.Run("exact pages", func(t *testing.T) {
+		server, requests := findServer(t, 4, 0)
+		next, err := newFindClient(t, server.URL).FindAll(context.Background(), params)
+		require.NoError(t, err)
+
+		assert.Equal(t, []int{2, 2}, collectPages(t, next))
+		assert.Len(t, *requests, 2)
+	})
+
+	t.Run("no results", func(t *testing.T) {
+		server, _ := findServer(t, 0, 0)
+		next, err := newFindClient(t, server.URL).FindAll(context.Background(), params)
+		require.NoError(t, err)
+
+		assert.Empty(t, collectPages(t, next))
+	})
+
+	t.Run("page error", func(t *testing.T) {
+		server, _ := findServer(t, 5, 2)
+		next, err := newFindClient(t, server.URL).FindAll(context.Background(), params)
+		require.NoError(t, err)
+
+		objects, err := next()
+		require.NoError(t, err)
+		assert.Equal(t, "dashboard-0", objects[0].ID)
+
+		_, err = next()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "page 2")
+		assert.Contains(t, err.Error(), "internal error")
+
+		// The failed page is requested again.
+		objects, err = next()
+		require.NoError(t, err)
+		assert.Equal(t, "dashboard-2", objects[0].ID)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		server, requests := findServer(t, 5, 0)
+		ctx, cancel := context.WithCancel(context.Background())
+		next, err := newFindClient(t, server.URL).FindAll(ctx, params)
+		require.NoError(t, err)
+
+		_, err = next()
+		require.NoError(t, err)
+		cancel()
+		_, err = next()
+		assert.Equal(t, context.Canceled, err)
+		assert.Len(t, *requests, 1)
+	})
+
+	t.Run("types required", func(t *testing.T) {
+		_, err := newFindClient(t, "http://localhost:5601").FindAll(context.Background(), FindParams{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,202 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kibana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// StatusLevel is the health level of Kibana or one of its components.
+type StatusLevel string
+
+const (
+	StatusAvailable   StatusLevel = "available"
+	StatusDegraded    StatusLevel = "degraded"
+	StatusUnavailable StatusLevel = "unavailable"
+	StatusCritical    StatusLevel = "critical"
+)
+
+// legacyStatusLevels maps the states reported by the status API before 8.0
+// to the levels used since.
+var legacyStatusLevels = map[string]StatusLevel{
+	"green":  StatusAvailable,
+	"yellow": StatusDegraded,
+	"red":    StatusUnavailable,
+}
+
+// StatusResponse is the response of the Kibana status API.
+type StatusResponse struct {
+	Name    string
+	UUID    string
+	Version StatusVersion
+
+	// Overall is the status of Kibana as a whole.
+	Overall ComponentStatus
+	// Core holds the status of the core services, keyed by service name.
+	Core map[string]ComponentStatus
+	// Plugins holds the status of the plugins, keyed by plugin name.
+	Plugins map[string]ComponentStatus
+}
+
+// StatusVersion describes the Kibana build reporting the status.
+type StatusVersion struct {
+	Number      string
+	BuildHash   string
+	BuildNumber int
+	Snapshot    bool
+}
+
+// ComponentStatus is the status of Kibana or one of its components.
+type ComponentStatus struct {
+	Level   StatusLevel
+	Summary string
+}
+
+// IsAvailable returns true if the level is available.
+func (s ComponentStatus) IsAvailable() bool { return s.Level == StatusAvailable }
+
+// Status returns the status reported by Kibana. The response format used
+// before 8.0, with green, yellow and red states, is converted to the levels
+// of the current format. Kibana responds with 503 if it is unavailable, in
+// that case the status is returned without an error.
+func (client *Client) Status(ctx context.Context) (*StatusResponse, error) {
+	resp, err := client.Connection.SendWithContext(ctx, http.MethodGet, statusAPI, nil, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fail to execute the HTTP GET request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	result, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusServiceUnavailable {
+		return nil, fmt.Errorf("HTTP GET request to %s/api/status fails with status %d. Response: %s",
+			client.Connection.URL, resp.StatusCode, truncateString(result))
+	}
+
+	status, err := parseStatus(result)
+	if err != nil {
+		return nil, fmt.Errorf("fail to unmarshal the response from GET %s/api/status. Response: %s. Kibana status api returns: %w",
+			client.Connection.URL, truncateString(result), err)
+	}
+	return status, nil
+}
+
+type statusAPIResponse struct {
+	Name    string `json:"name"`
+	UUID    string `json:"uuid"`
+	Version struct {
+		Number      string `json:"number"`
+		BuildHash   string `json:"build_hash"`
+		BuildNumber int    `json:"build_number"`
+		Snapshot    bool   `json:"build_snapshot"`
+	} `json:"version"`
+	Status struct {
+		Overall struct {
+			Level   StatusLevel `json:"level"`
+			Summary string      `json:"summary"`
+
+			// before 8.0
+			State    string `json:"state"`
+			Nickname string `json:"nickname"`
+		} `json:"overall"`
+		Core    map[string]statusAPIComponent `json:"core"`
+		Plugins map[string]statusAPIComponent `json:"plugins"`
+
+		// before 8.0
+		Statuses []struct {
+			ID      string `json:"id"`
+			State   string `json:"state"`
+			Message string `json:"message"`
+		} `json:"statuses"`
+	} `json:"status"`
+}
+
+type statusAPIComponent struct {
+	Level   StatusLevel `json:"level"`
+	Summary string      `json:"summary"`
+}
+
+func parseStatus(data []byte) (*StatusResponse, error) {
+	var raw statusAPIResponse
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	status := &StatusResponse{
+		Name: raw.Name,
+		UUID: raw.UUID,
+		Version: StatusVersion{
+			Number:      raw.Version.Number,
+			BuildHash:   raw.Version.BuildHash,
+			BuildNumber: raw.Version.BuildNumber,
+			Snapshot:    raw.Version.Snapshot,
+		},
+		Core:    map[string]ComponentStatus{},
+		Plugins: map[string]ComponentStatus{},
+	}
+
+	overall := raw.Status.Overall
+	if overall.Level != "" {
+		status.Overall = ComponentStatus{Level: overall.Level, Summary: overall.Summary}
+	} else {
+		status.Overall = ComponentStatus{Level: legacyStatusLevel(overall.State), Summary: overall.Nickname}
+	}
+
+	for name, c := range raw.Status.Core {
+		status.Core[name] = ComponentStatus(c)
+	}
+	for name, c := range raw.Status.Plugins {
+		status.Plugins[name] = ComponentStatus(c)
+	}
+
+	// Before 8.0 components are listed with IDs like core:elasticsearch@7.10.2
+	// or plugin:alerts@7.10.2.
+	for _, c := range raw.Status.Statuses {
+		kind, name := "plugin", c.ID
+		if i := strings.Index(name, ":"); i >= 0 {
+			kind, name = name[:i], name[i+1:]
+		}
+		if i := strings.LastIndex(name, "@"); i >= 0 {
+			name = name[:i]
+		}
+
+		component := ComponentStatus{Level: legacyStatusLevel(c.State), Summary: c.Message}
+		if kind == "core" {
+			status.Core[name] = component
+		} else {
+			status.Plugins[name] = component
+		}
+	}
+
+	return status, nil
+}
+
+func legacyStatusLevel(state string) StatusLevel {
+	if level, ok := legacyStatusLevels[state]; ok {
+		return level
+	}
+	return StatusLevel(state)
+}
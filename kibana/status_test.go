@@ -0,0 +1,124 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kibana
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatus(t *testing.T) {
+	tests := map[string]StatusResponse{
+		"7.10.2.json": {
+			Name:    "kibana-7",
+			UUID:    "5b2de169-2785-441b-ae8c-186a1936b17d",
+			Version: StatusVersion{Number: "7.10.2", BuildHash: "a0b793698735eb1d0f6c4eb211bd6f6b4ac3b8a2", BuildNumber: 36478},
+			Overall: ComponentStatus{Level: StatusDegraded, Summary: "I'll be back"},
+			Core: map[string]ComponentStatus{
+				"elasticsearch": {Level: StatusAvailable, Summary: "Elasticsearch is available"},
+				"savedObjects":  {Level: StatusAvailable, Summary: "SavedObjects service has completed migrations and is available"},
+			},
+			Plugins: map[string]ComponentStatus{
+				"taskManager": {Level: StatusDegraded, Summary: "Task Manager is unhealthy"},
+				"alerts":      {Level: StatusUnavailable, Summary: "Alerting is unavailable"},
+			},
+		},
+		"8.6.0.json": {
+			Name:    "kibana-8",
+			UUID:    "0ca2ec3f-4df0-4e5b-8b0b-6e7f1d0fdd6b",
+			Version: StatusVersion{Number: "8.6.0", BuildHash: "8a3a29b8bb2c8a4ebbccf6c4b445e73ba4937e1b", BuildNumber: 58802, Snapshot: true},
+			Overall: ComponentStatus{Level: StatusDegraded, Summary: "1 service is degraded: taskManager"},
+			Core: map[string]ComponentStatus{
+				"elasticsearch": {Level: StatusAvailable, Summary: "Elasticsearch is available"},
+				"savedObjects":  {Level: StatusAvailable, Summary: "SavedObjects service has completed migrations and is available"},
+			},
+			Plugins: map[string]ComponentStatus{
+				"taskManager": {Level: StatusDegraded, Summary: "Task Manager is unhealthy"},
+				"alerting":    {Level: StatusAvailable, Summary: "All dependencies are available"},
+			},
+		},
+	}
+
+	for fixture, expected := range tests {
+		t.Run(fixture, func(t *testing.T) {
+			data, err := ioutil.ReadFile(filepath.Join("testdata", "status", fixture))
+			require.NoError(t, err)
+
+			status, err := parseStatus(data)
+			require.NoError(t, err)
+			assert.Equal(t, &expected, status)
+		})
+	}
+}
+
+func TestClientStatus(t *testing.T) {
+	serve := func(code int, fixture string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != statusAPI {
+				http.NotFound(w, r)
+				return
+			}
+			w.WriteHeader(code)
+			if fixture != "" {
+				data, _ := ioutil.ReadFile(filepath.Join("testdata", "status", fixture))
+				_, _ = w.Write(data)
+			}
+		}))
+	}
+	newClient := func(t *testing.T, url string) *Client {
+		client, err := NewClientWithConfig(&ClientConfig{Host: url, IgnoreVersion: true}, binaryName, v, commit, buildTime)
+		require.NoError(t, err)
+		return client
+	}
+
+	t.Run("available", func(t *testing.T) {
+		server := serve(http.StatusOK, "8.6.0.json")
+		defer server.Close()
+
+		status, err := newClient(t, server.URL).Status(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "8.6.0", status.Version.Number)
+		assert.Equal(t, StatusDegraded, status.Overall.Level)
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		server := serve(http.StatusServiceUnavailable, "8.6.0-unavailable.json")
+		defer server.Close()
+
+		status, err := newClient(t, server.URL).Status(context.Background())
+		require.NoError(t, err)
+		assert.False(t, status.Overall.IsAvailable())
+		assert.Equal(t, StatusUnavailable, status.Core["elasticsearch"].Level)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		server := serve(http.StatusUnauthorized, "")
+		defer server.Close()
+
+		_, err := newClient(t, server.URL).Status(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "status 401")
+	})
+}
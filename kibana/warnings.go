@@ -0,0 +1,137 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kibana
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DeprecationWarningCode is the code of the Warning headers Kibana returns
+// when a deprecated API is used.
+const DeprecationWarningCode = 299
+
+// Warning is a warning returned by Kibana in a Warning header, see RFC 7234
+// section 5.5.
+type Warning struct {
+	Code  int
+	Agent string
+	Text  string
+}
+
+// IsDeprecation reports whether w warns about the use of a deprecated API.
+func (w Warning) IsDeprecation() bool {
+	return w.Code == DeprecationWarningCode
+}
+
+func (w Warning) String() string {
+	return strconv.Itoa(w.Code) + " " + w.Agent + " " + strconv.Quote(w.Text)
+}
+
+// ParseWarnings returns the warnings of the Warning headers of h. Values not
+// following the RFC are returned as the text of a warning with code 0.
+func ParseWarnings(h http.Header) []Warning {
+	var warnings []Warning
+	for _, value := range h.Values("Warning") {
+		for value = strings.TrimSpace(value); value != ""; {
+			var w Warning
+			w, value = parseWarning(value)
+			warnings = append(warnings, w)
+		}
+	}
+	return warnings
+}
+
+// parseWarning parses the first warning of s, `code agent "text" ["date"]`,
+// and returns the remaining warnings.
+func parseWarning(s string) (Warning, string) {
+	invalid := func() (Warning, string) {
+		return Warning{Text: s}, ""
+	}
+
+	fields := strings.SplitN(s, " ", 3)
+	if len(fields) != 3 {
+		return invalid()
+	}
+	code, err := strconv.Atoi(fields[0])
+	if err != nil || len(fields[0]) != 3 {
+		return invalid()
+	}
+
+	text, rest, ok := parseQuoted(fields[2])
+	if !ok {
+		return invalid()
+	}
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, `"`) {
+		// skip the warn-date
+		if _, rest, ok = parseQuoted(rest); !ok {
+			return invalid()
+		}
+		rest = strings.TrimSpace(rest)
+	}
+	if rest != "" && !strings.HasPrefix(rest, ",") {
+		return invalid()
+	}
+	return Warning{Code: code, Agent: fields[1], Text: text}, strings.TrimSpace(strings.TrimPrefix(rest, ","))
+}
+
+// parseQuoted parses the quoted string at the start of s and returns its
+// unescaped content and the remaining of s.
+func parseQuoted(s string) (string, string, bool) {
+	if !strings.HasPrefix(s, `"`) {
+		return "", "", false
+	}
+
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+			if i < len(s) {
+				b.WriteByte(s[i])
+			}
+		case '"':
+			return b.String(), s[i+1:], true
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", "", false
+}
+
+// DedupWarnings returns a function calling fn with the warnings it has not
+// been called with before. It can be used as Connection.OnWarning to only
+// report repeated warnings once.
+func DedupWarnings(fn func(Warning)) func(Warning) {
+	var (
+		mu   sync.Mutex
+		seen = map[Warning]struct{}{}
+	)
+	return func(w Warning) {
+		mu.Lock()
+		_, found := seen[w]
+		seen[w] = struct{}{}
+		mu.Unlock()
+		if !found {
+			fn(w)
+		}
+	}
+}
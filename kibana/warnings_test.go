@@ -0,0 +1,125 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kibana
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+// serveFixture replies to every request with the raw HTTP response stored in
+// testdata/warnings.
+func serveFixture(t *testing.T, name string) *httptest.Server {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", "warnings", name))
+	require.NoError(t, err)
+	defer f.Close()
+	resp, err := http.ReadResponse(bufio.NewReader(f), nil)
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for k, vs := range resp.Header {
+			w.Header()[k] = vs
+		}
+		w.WriteHeader(resp.StatusCode)
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+var fixtureWarnings = []Warning{
+	{Code: 299, Agent: "Kibana-8.7.0", Text: "The /api/index_patterns API is deprecated, use the /api/data_views API instead."},
+	{Code: 299, Agent: "Kibana-8.7.0", Text: `Parameter "fields" is deprecated`},
+	{Code: 199, Agent: "kibana", Text: "Miscellaneous warning"},
+}
+
+func TestConnectionOnWarning(t *testing.T) {
+	ts := serveFixture(t, "deprecated-api.http")
+
+	var warnings []Warning
+	conn := Connection{
+		URL:       ts.URL,
+		HTTP:      http.DefaultClient,
+		OnWarning: DedupWarnings(func(w Warning) { warnings = append(warnings, w) }),
+	}
+	for i := 0; i < 3; i++ {
+		code, _, err := conn.Request(http.MethodGet, "/api/index_patterns/index_pattern/logs-*", nil, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+	}
+
+	assert.Equal(t, fixtureWarnings, warnings)
+	assert.True(t, warnings[0].IsDeprecation())
+	assert.False(t, warnings[2].IsDeprecation())
+}
+
+func TestParseWarnings(t *testing.T) {
+	ts := serveFixture(t, "deprecated-api.http")
+	resp, err := http.Get(ts.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, fixtureWarnings, ParseWarnings(resp.Header))
+
+	tests := map[string][]Warning{
+		`299 - "deprecated"`:           {{Code: 299, Agent: "-", Text: "deprecated"}},
+		`299 Kibana "escaped \\ \" x"`: {{Code: 299, Agent: "Kibana", Text: `escaped \ " x`}},
+		`deprecated API`:               {{Text: "deprecated API"}},
+		`299 Kibana "unterminated`:     {{Text: `299 Kibana "unterminated`}},
+		`299 Kibana "a" junk`:          {{Text: `299 Kibana "a" junk`}},
+	}
+	for value, want := range tests {
+		h := http.Header{}
+		h.Set("Warning", value)
+		assert.Equal(t, want, ParseWarnings(h), value)
+	}
+	assert.Empty(t, ParseWarnings(http.Header{}))
+}
+
+func TestWarningString(t *testing.T) {
+	assert.Equal(t, `299 Kibana-8.7.0 "Parameter \"fields\" is deprecated"`, fixtureWarnings[1].String())
+}
+
+func TestClientLogsWarningsOnce(t *testing.T) {
+	require.NoError(t, logp.DevelopmentSetup(logp.ToObserverOutput()))
+	ts := serveFixture(t, "deprecated-api.http")
+
+	client, err := NewClientWithConfig(&ClientConfig{Host: ts.URL, IgnoreVersion: true}, binaryName, v, commit, buildTime)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, _, err := client.Request(http.MethodGet, "/api/index_patterns/index_pattern/logs-*", nil, nil, nil)
+		require.NoError(t, err)
+	}
+
+	logs := logp.ObserverLogs().FilterLevelExact(zapcore.WarnLevel).TakeAll()
+	require.Len(t, logs, len(fixtureWarnings))
+	assert.Contains(t, logs[0].Message, "/api/index_patterns API is deprecated")
+}
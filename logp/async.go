@@ -0,0 +1,219 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultAsyncBufferSize   = 8192
+	defaultAsyncFlushTimeout = 5 * time.Second
+)
+
+// AsyncDropped returns the number of log records dropped because the buffer
+// of the asynchronous file output was full, for all the file outputs. The
+// drops of each output are reported by Stats. It can be reported with
+// monitoring.NewFunc.
+func AsyncDropped() uint64 {
+	var dropped uint64
+	for _, stats := range Stats().Outputs {
+		dropped += stats.BufferDrops
+	}
+	return dropped
+}
+
+// errAsyncFlushTimeout is returned by Sync when the buffer of an
+// asynchronous writer is not drained within the flush timeout.
+var errAsyncFlushTimeout = errors.New("timeout flushing the buffered log records")
+
+type asyncRequest struct {
+	data    []byte
+	flushed chan error // set for flush requests
+}
+
+// asyncWriter writes to out from a background goroutine. The writes are
+// queued in a bounded buffer, they are dropped when the buffer is full
+// instead of blocking the caller. Close stops the goroutine, the records
+// written after Close are written directly to out.
+type asyncWriter struct {
+	out          zapcore.WriteSyncer
+	queue        chan asyncRequest
+	flushTimeout time.Duration
+	dropped      uint64
+	stats        *outputCounters
+
+	mu  sync.Mutex
+	err error // first write error since the last Sync
+
+	closeMu sync.RWMutex
+	closed  bool
+	done    chan struct{} // closed by Close
+	stopped chan struct{} // closed when the goroutine has drained the queue
+}
+
+func newAsyncWriter(out zapcore.WriteSyncer, bufferSize int, flushTimeout time.Duration, stats *outputCounters) *asyncWriter {
+	if bufferSize <= 0 {
+		bufferSize = defaultAsyncBufferSize
+	}
+	if flushTimeout <= 0 {
+		flushTimeout = defaultAsyncFlushTimeout
+	}
+	w := &asyncWriter{
+		out:          out,
+		queue:        make(chan asyncRequest, bufferSize),
+		flushTimeout: flushTimeout,
+		stats:        stats,
+		done:         make(chan struct{}),
+		stopped:      make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *asyncWriter) run() {
+	defer close(w.stopped)
+	for {
+		select {
+		case req := <-w.queue:
+			w.handle(req)
+		case <-w.done:
+			for {
+				select {
+				case req := <-w.queue:
+					w.handle(req)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (w *asyncWriter) handle(req asyncRequest) {
+	if req.flushed != nil {
+		err := w.out.Sync()
+		w.mu.Lock()
+		if w.err != nil {
+			err = w.err
+			w.err = nil
+		}
+		w.mu.Unlock()
+		req.flushed <- err
+		return
+	}
+
+	if _, err := w.out.Write(req.data); err != nil {
+		atomic.AddUint64(&w.stats.writeErrors, 1)
+		w.mu.Lock()
+		if w.err == nil {
+			w.err = err
+		}
+		w.mu.Unlock()
+	}
+}
+
+// Write queues a copy of p, the encoders reuse their buffers. The record is
+// dropped if the buffer is full.
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	w.closeMu.RLock()
+	defer w.closeMu.RUnlock()
+	if w.closed {
+		return w.out.Write(p)
+	}
+
+	data := make([]byte, len(p))
+	copy(data, p)
+
+	select {
+	case w.queue <- asyncRequest{data: data}:
+	default:
+		atomic.AddUint64(&w.dropped, 1)
+		atomic.AddUint64(&w.stats.bufferDrops, 1)
+	}
+	return len(p), nil
+}
+
+// Sync waits for the queued records to be written and syncs the output. It
+// returns the errors reported by the output since the last call, or an
+// error if the buffer is not drained within the flush timeout.
+func (w *asyncWriter) Sync() error {
+	w.closeMu.RLock()
+	defer w.closeMu.RUnlock()
+	if w.closed {
+		return w.out.Sync()
+	}
+
+	timer := time.NewTimer(w.flushTimeout)
+	defer timer.Stop()
+
+	flushed := make(chan error, 1)
+	select {
+	case w.queue <- asyncRequest{flushed: flushed}:
+	case <-timer.C:
+		return errAsyncFlushTimeout
+	}
+
+	select {
+	case err := <-flushed:
+		return err
+	case <-timer.C:
+		return errAsyncFlushTimeout
+	}
+}
+
+// Close writes the queued records and stops the background goroutine, then
+// syncs and closes out. If the queue is not drained within the flush timeout
+// the goroutine keeps writing the remaining records and out is left open.
+func (w *asyncWriter) Close() error {
+	w.closeMu.Lock()
+	if w.closed {
+		w.closeMu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.done)
+	w.closeMu.Unlock()
+
+	timer := time.NewTimer(w.flushTimeout)
+	defer timer.Stop()
+	select {
+	case <-w.stopped:
+	case <-timer.C:
+		return errAsyncFlushTimeout
+	}
+
+	err := w.out.Sync()
+	if closer, ok := w.out.(io.Closer); ok {
+		if cerr := closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Dropped returns the number of records dropped by the writer.
+func (w *asyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
@@ -0,0 +1,215 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// blockingWriter blocks the writes until release is closed.
+type blockingWriter struct {
+	release chan struct{}
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *blockingWriter) Sync() error { return nil }
+
+func (w *blockingWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncWriterDrops(t *testing.T) {
+	out := &blockingWriter{release: make(chan struct{})}
+	w := newAsyncWriter(out, 2, time.Second, statsFor("test"))
+	before := AsyncDropped()
+
+	// The first record may be taken by the background goroutine, which then
+	// blocks on the output. The buffer holds two more records.
+	for i := 0; i < 10; i++ {
+		n, err := w.Write([]byte("x"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+	}
+	dropped := w.Dropped()
+	assert.True(t, dropped == 7 || dropped == 8, dropped)
+	assert.Equal(t, dropped, AsyncDropped()-before)
+
+	close(out.release)
+	require.NoError(t, w.Sync())
+	assert.Len(t, out.String(), 10-int(dropped))
+}
+
+func TestAsyncWriterSync(t *testing.T) {
+	t.Run("drains the buffer", func(t *testing.T) {
+		out := &blockingWriter{release: make(chan struct{})}
+		close(out.release)
+		w := newAsyncWriter(out, 100, time.Second, statsFor("test"))
+
+		_, _ = w.Write([]byte("a\n"))
+		_, _ = w.Write([]byte("b\n"))
+		require.NoError(t, w.Sync())
+		assert.Equal(t, "a\nb\n", out.String())
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		out := &blockingWriter{release: make(chan struct{})}
+		defer close(out.release)
+		w := newAsyncWriter(out, 1, 50*time.Millisecond, statsFor("test"))
+
+		_, _ = w.Write([]byte("a\n"))
+		_, _ = w.Write([]byte("b\n"))
+		assert.Equal(t, errAsyncFlushTimeout, w.Sync())
+	})
+}
+
+// closingWriter records the calls to Close.
+type closingWriter struct {
+	blockingWriter
+	closed int
+}
+
+func (w *closingWriter) Close() error {
+	w.closed++
+	return nil
+}
+
+func TestAsyncWriterClose(t *testing.T) {
+	out := &closingWriter{blockingWriter: blockingWriter{release: make(chan struct{})}}
+	w := newAsyncWriter(out, 100, time.Second, statsFor("test"))
+
+	_, _ = w.Write([]byte("a\n"))
+	_, _ = w.Write([]byte("b\n"))
+	close(out.release)
+	require.NoError(t, w.Close())
+	assert.Equal(t, "a\nb\n", out.String())
+	assert.Equal(t, 1, out.closed)
+	select {
+	case <-w.stopped:
+	default:
+		t.Fatal("the background goroutine is still running")
+	}
+
+	// the writes after Close go to the output directly
+	_, err := w.Write([]byte("c\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Sync())
+	assert.Equal(t, "a\nb\nc\n", out.String())
+	require.NoError(t, w.Close())
+	assert.Equal(t, 1, out.closed)
+}
+
+func TestAsyncFileOutputClosedOnReconfigure(t *testing.T) {
+	dir := t.TempDir()
+	cfg := DefaultConfig(DefaultEnvironment)
+	cfg.ToFiles = true
+	cfg.Files.Path = dir
+	cfg.Files.Name = "reconfigured"
+	cfg.Files.Async.Enabled = true
+	require.NoError(t, Configure(cfg))
+	closers := loadLogger().closers
+	require.Len(t, closers, 1)
+	w, ok := closers[0].(*asyncWriter)
+	require.True(t, ok)
+
+	NewLogger("async").Info("before the new configuration")
+	require.NoError(t, DevelopmentSetup(ToDiscardOutput()))
+	select {
+	case <-w.stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the writer of the replaced logger is not closed")
+	}
+
+	logs, err := filepath.Glob(filepath.Join(dir, "reconfigured*"))
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	content, err := ioutil.ReadFile(logs[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "before the new configuration")
+}
+
+func TestAsyncFileOutput(t *testing.T) {
+	dir := t.TempDir()
+	cfg := DefaultConfig(DefaultEnvironment)
+	cfg.ToFiles = true
+	cfg.Files.Path = dir
+	cfg.Files.Name = "async"
+	cfg.Files.Async.Enabled = true
+	require.NoError(t, Configure(cfg))
+	defer func() {
+		require.NoError(t, DevelopmentSetup(ToDiscardOutput()))
+	}()
+
+	NewLogger("async").Info("buffered message")
+	require.NoError(t, Sync())
+
+	logs, err := filepath.Glob(filepath.Join(dir, "async*"))
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	content, err := ioutil.ReadFile(logs[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "buffered message")
+}
+
+func BenchmarkFileWriter(b *testing.B) {
+	run := func(b *testing.B, async bool) {
+		cfg := DefaultConfig(DefaultEnvironment)
+		cfg.Files.Async.Enabled = async
+		cfg.Files.RotateOnStartup = false
+		cfg.Files.MaxSize = 1024 * 1024 * 1024
+		cfg.Files.Path = b.TempDir()
+		cfg.Files.Name = "bench"
+
+		out, err := makeFileWriter("file", cfg.Files, cfg.Files.Name, nil)
+		require.NoError(b, err)
+		core := zapcore.NewCore(zapcore.NewJSONEncoder(JSONEncoderConfig()), out, zapcore.DebugLevel)
+		entry := zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), Message: "benchmark message"}
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_ = core.Write(entry, nil)
+			}
+		})
+		b.StopTimer()
+		_ = core.Sync()
+	}
+
+	b.Run("sync", func(b *testing.B) { run(b, false) })
+	b.Run("async", func(b *testing.B) { run(b, true) })
+}
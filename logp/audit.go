@@ -0,0 +1,76 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"errors"
+
+	"go.uber.org/zap"
+
+	"github.com/elastic/elastic-agent-libs/file"
+)
+
+// AuditConfig contains the configuration of an audit logger. It is
+// independent of the configuration of the logp package.
+type AuditConfig struct {
+	Beat  string     `config:",ignore"` // Name of the Beat, added as service.name.
+	Files FileConfig `config:"files"`
+}
+
+// DefaultAuditConfig returns the default audit logger configuration. Audit
+// records are written to the audit file in the logs directory.
+func DefaultAuditConfig() AuditConfig {
+	files := DefaultConfig(DefaultEnvironment).Files
+	files.Name = "audit"
+	return AuditConfig{Files: files}
+}
+
+// AuditLogger writes security audit records to a dedicated file. Records are
+// ECS encoded and are never filtered by level or debug selectors, nor are
+// they sent to the outputs configured for the logp package.
+type AuditLogger struct {
+	*Logger
+	rotator *file.Rotator
+}
+
+// NewAuditLogger creates an audit logger writing to the file configured in
+// cfg. Close must be called to release the file.
+func NewAuditLogger(cfg AuditConfig) (*AuditLogger, error) {
+	if cfg.Files.Name == "" {
+		return nil, errors.New("audit logger requires a file name")
+	}
+
+	rotator, err := makeFileRotator(cfg.Files, cfg.Files.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	core := newCore(buildEncoder(Config{}), rotator, DebugLevel.ZapLevel())
+	auditCfg := Config{Beat: cfg.Beat, addCaller: true}
+	root := zap.New(withMetadata(core, auditCfg), makeOptions(auditCfg)...)
+	return &AuditLogger{
+		Logger:  newLogger(root, "audit"),
+		rotator: rotator,
+	}, nil
+}
+
+// Close flushes the pending audit records and closes the audit file.
+func (l *AuditLogger) Close() error {
+	_ = l.Sync()
+	return l.rotator.Close()
+}
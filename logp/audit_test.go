@@ -0,0 +1,69 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogger(t *testing.T) {
+	require.NoError(t, DevelopmentSetup(ToObserverOutput()))
+
+	cfg := DefaultAuditConfig()
+	cfg.Beat = "testbeat"
+	cfg.Files.Path = t.TempDir()
+
+	audit, err := NewAuditLogger(cfg)
+	require.NoError(t, err)
+
+	NewLogger("operational").Info("operational message")
+	audit.Infow("user logged in", "user.name", "elastic")
+	audit.Debug("audit debug message")
+	require.NoError(t, audit.Close())
+
+	// Audit records must not reach the regular outputs.
+	logs := ObserverLogs().TakeAll()
+	require.Len(t, logs, 1)
+	assert.Equal(t, "operational message", logs[0].Message)
+
+	files, err := filepath.Glob(filepath.Join(cfg.Files.Path, "audit-*.ndjson"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	content, err := ioutil.ReadFile(files[0])
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 2)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "user logged in", record["message"])
+	assert.Equal(t, "info", record["log.level"])
+	assert.Equal(t, "audit", record["log.logger"])
+	assert.Equal(t, "elastic", record["user.name"])
+	assert.Equal(t, "testbeat", record["service.name"])
+	assert.Contains(t, record, "ecs.version")
+	assert.NotContains(t, string(content), "operational message")
+}
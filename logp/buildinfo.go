@@ -0,0 +1,74 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"errors"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/elastic/elastic-agent-libs/version"
+)
+
+// ErrBuildInfoSet is returned by SetBuildInfo if the build information was
+// already set.
+var ErrBuildInfoSet = errors.New("logp build information is already set")
+
+var buildInfo struct {
+	sync.Mutex
+	set     bool
+	version string
+	commit  string
+}
+
+// SetBuildInfo sets the version and the commit hash of the build, added as
+// service.version and build.commit to every log line. The commit is optional.
+// It can only be called once, usually at init before the logger is
+// configured. The fields are added by the following calls to Configure.
+func SetBuildInfo(v *version.V, commit string) error {
+	if v == nil {
+		return errors.New("version must be set")
+	}
+
+	buildInfo.Lock()
+	defer buildInfo.Unlock()
+	if buildInfo.set {
+		return ErrBuildInfoSet
+	}
+	buildInfo.set = true
+	buildInfo.version = v.String()
+	buildInfo.commit = commit
+	return nil
+}
+
+// buildInfoFields returns the fields set by SetBuildInfo.
+func buildInfoFields() []zapcore.Field {
+	buildInfo.Lock()
+	defer buildInfo.Unlock()
+
+	var fields []zapcore.Field
+	if buildInfo.version != "" {
+		fields = append(fields, zap.String("service.version", buildInfo.version))
+	}
+	if buildInfo.commit != "" {
+		fields = append(fields, zap.String("build.commit", buildInfo.commit))
+	}
+	return fields
+}
@@ -0,0 +1,71 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/version"
+)
+
+func resetBuildInfo() {
+	buildInfo.Lock()
+	defer buildInfo.Unlock()
+	buildInfo.set, buildInfo.version, buildInfo.commit = false, "", ""
+}
+
+func TestBuildInfoFields(t *testing.T) {
+	resetBuildInfo()
+	defer resetBuildInfo()
+
+	require.NoError(t, SetBuildInfo(version.MustNew("8.5.0-SNAPSHOT"), "abc123"))
+	assert.Equal(t, ErrBuildInfoSet, SetBuildInfo(version.MustNew("9.0.0"), "def456"))
+
+	cfg := DefaultConfig(DefaultEnvironment)
+	cfg.Beat = "beat1"
+	ToObserverOutput()(&cfg)
+	require.NoError(t, Configure(cfg))
+
+	NewLogger("tester").Infow("message", "service.version", "other")
+	logs := ObserverLogs().TakeAll()
+	require.Len(t, logs, 1)
+	assert.Equal(t, map[string]interface{}{
+		"service.name":    "beat1",
+		"service.version": "8.5.0-SNAPSHOT",
+		"build.commit":    "abc123",
+	}, logs[0].ContextMap())
+}
+
+func TestBuildInfoWithoutCommit(t *testing.T) {
+	resetBuildInfo()
+	defer resetBuildInfo()
+
+	require.NoError(t, SetBuildInfo(version.MustNew("8.5.0"), ""))
+
+	cfg := DefaultConfig(DefaultEnvironment)
+	ToObserverOutput()(&cfg)
+	require.NoError(t, Configure(cfg))
+
+	NewLogger("tester").Info("message")
+	logs := ObserverLogs().TakeAll()
+	require.Len(t, logs, 1)
+	assert.Equal(t, map[string]interface{}{"service.version": "8.5.0"}, logs[0].ContextMap())
+}
@@ -28,15 +28,35 @@ type Config struct {
 	Level     Level    `config:"level"`     // Logging level (error, warning, info, debug).
 	Selectors []string `config:"selectors"` // Selectors for debug level logging.
 
+	// DisabledSelectors are the loggers whose logs are dropped regardless of
+	// their level, they can be globs as supported by path.Match.
+	DisabledSelectors []string `config:"disabled_selectors" yaml:"disabled_selectors"`
+
 	toObserver  bool
 	toIODiscard bool
 	ToStderr    bool `config:"to_stderr" yaml:"to_stderr"`
 	ToSyslog    bool `config:"to_syslog" yaml:"to_syslog"`
 	ToFiles     bool `config:"to_files" yaml:"to_files"`
 	ToEventLog  bool `config:"to_eventlog" yaml:"to_eventlog"`
+	ToGELF      bool `config:"to_gelf" yaml:"to_gelf"`
+	ToJournald  bool `config:"to_journald" yaml:"to_journald"`
+
+	Files    FileConfig     `config:"files"`
+	Metrics  MetricsConfig  `config:"metrics"`
+	Metadata MetadataConfig `config:"metadata"`
+	Console  ConsoleConfig  `config:"console"`
+	GELF     GELFConfig     `config:"gelf"`
+	Journald JournaldConfig `config:"journald"`
+	Hooks    HooksConfig    `config:"hooks"`
+	Keys     KeysConfig     `config:"keys"`
+
+	// Routes send the logs of some selectors to their own files.
+	Routes []RouteConfig `config:"routes"`
 
-	Files   FileConfig    `config:"files"`
-	Metrics MetricsConfig `config:"metrics"`
+	// OriginFields encodes the caller as the separate log.origin.function,
+	// log.origin.file.name and log.origin.file.line fields instead of a
+	// single caller value.
+	OriginFields bool `config:"origin_fields" yaml:"origin_fields"`
 
 	environment Environment
 	addCaller   bool // Adds package and line number info to messages.
@@ -53,6 +73,19 @@ type FileConfig struct {
 	Interval        time.Duration `config:"interval"`
 	RotateOnStartup bool          `config:"rotateonstartup"`
 	RedirectStderr  bool          `config:"redirect_stderr" yaml:"redirect_stderr"`
+
+	// Async writes the logs from a background goroutine.
+	Async AsyncConfig `config:"async" yaml:"async"`
+}
+
+// AsyncConfig configures the asynchronous writes to the log files. The log
+// records are kept in a bounded buffer and written in the background, they
+// are dropped when the buffer is full instead of blocking the caller. The
+// number of dropped records is reported by AsyncDropped.
+type AsyncConfig struct {
+	Enabled      bool          `config:"enabled" yaml:"enabled"`
+	BufferSize   int           `config:"buffer_size" yaml:"buffer_size" validate:"min=1"` // Number of buffered records.
+	FlushTimeout time.Duration `config:"flush_timeout" yaml:"flush_timeout"`              // Maximum time Sync waits for the buffer to be drained.
 }
 
 // MetricsConfig contains configuration used by the monitor to output metrics into the logstream.
@@ -63,6 +96,59 @@ type MetricsConfig struct {
 	Period  time.Duration `config:"period"`
 }
 
+// MetadataConfig selects the static fields added to every log line. The
+// values are computed once, when the logger is configured.
+type MetadataConfig struct {
+	Host    bool  `config:"host" yaml:"host"`       // Adds host.name.
+	Process bool  `config:"process" yaml:"process"` // Adds process.pid.
+	Service *bool `config:"service" yaml:"service"` // Adds service.name, set to Beat. Enabled by default.
+
+	// AllowOverride allows fields with the same key as the explicitly enabled
+	// metadata fields to be added to the log lines. They are dropped by
+	// default. The fields of the loggers are always kept if no metadata
+	// field is explicitly enabled, service.name is only protected if
+	// Service is set.
+	AllowOverride bool `config:"allow_override" yaml:"allow_override"`
+}
+
+// GELFConfig configures the output sending logs to Graylog in the GELF
+// format.
+type GELFConfig struct {
+	Network   string `config:"network" yaml:"network"`       // udp (default) or tcp.
+	Address   string `config:"address" yaml:"address"`       // host:port of the GELF input.
+	ChunkSize int    `config:"chunk_size" yaml:"chunk_size"` // Maximum size of UDP datagrams.
+}
+
+// JournaldConfig configures the output sending logs to the systemd journal
+// with its native protocol.
+type JournaldConfig struct {
+	Socket     string `config:"socket" yaml:"socket"`         // Path of the journal socket.
+	Identifier string `config:"identifier" yaml:"identifier"` // SYSLOG_IDENTIFIER, the Beat name by default.
+}
+
+// KeysConfig overrides the keys of the message, level and timestamp in the
+// encoded log records. The ECS keys message, log.level and @timestamp are
+// used by default.
+type KeysConfig struct {
+	Message   string `config:"message" yaml:"message"`
+	Level     string `config:"level" yaml:"level"`
+	Timestamp string `config:"timestamp" yaml:"timestamp"`
+}
+
+// ConsoleConfig configures the output written to stderr. The level and
+// color settings only apply to the human-readable text format.
+type ConsoleConfig struct {
+	Format    string    `config:"format" yaml:"format"`         // json (default) or text.
+	LevelCase string    `config:"level_case" yaml:"level_case"` // upper (default) or lower.
+	Color     ColorMode `config:"color" yaml:"color"`           // auto (default), always or never.
+	Multiline string    `config:"multiline" yaml:"multiline"`   // indent (default), escape or keep.
+
+	// LevelColors overrides the color of the levels, e.g. `warning: cyan`.
+	// Available colors are black, red, green, yellow, blue, magenta, cyan
+	// and white.
+	LevelColors map[string]string `config:"level_colors" yaml:"level_colors"`
+}
+
 const (
 	defaultLevel = InfoLevel
 )
@@ -78,11 +164,22 @@ func DefaultConfig(environment Environment) Config {
 			Permissions:     0600,
 			Interval:        0,
 			RotateOnStartup: true,
+			Async: AsyncConfig{
+				BufferSize:   defaultAsyncBufferSize,
+				FlushTimeout: defaultAsyncFlushTimeout,
+			},
 		},
 		Metrics: MetricsConfig{
 			Enabled: true,
 			Period:  30 * time.Second,
 		},
+		GELF: GELFConfig{
+			Network:   "udp",
+			ChunkSize: defaultGELFChunkSize,
+		},
+		Journald: JournaldConfig{
+			Socket: defaultJournaldSocket,
+		},
 		environment: environment,
 		addCaller:   true,
 	}
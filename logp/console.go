@@ -0,0 +1,247 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"go.elastic.co/ecszap"
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// Console output formats.
+const (
+	consoleFormatJSON = "json"
+	consoleFormatText = "text"
+)
+
+// Newline handling of the console text output.
+const (
+	multilineIndent = "indent" // continuation lines are indented with a tab
+	multilineEscape = "escape" // newlines are replaced by `\n`
+	multilineKeep   = "keep"   // newlines are written as is
+)
+
+// ColorMode controls the use of colors in the console text output.
+type ColorMode int8
+
+// Color modes.
+const (
+	ColorAuto   ColorMode = iota // Colors are used if stderr is a terminal.
+	ColorAlways                  // Colors are always used.
+	ColorNever                   // Colors are never used.
+)
+
+var colorModeStrings = map[ColorMode]string{
+	ColorAuto:   "auto",
+	ColorAlways: "always",
+	ColorNever:  "never",
+}
+
+// String returns the name of the color mode.
+func (m ColorMode) String() string {
+	if s, found := colorModeStrings[m]; found {
+		return s
+	}
+	return fmt.Sprintf("ColorMode(%d)", m)
+}
+
+// Unpack unmarshals a color mode string to a ColorMode. This implements
+// ucfg.StringUnpacker.
+func (m *ColorMode) Unpack(str string) error {
+	str = strings.ToLower(str)
+	for mode, name := range colorModeStrings {
+		if name == str {
+			*m = mode
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid color mode '%v'", str)
+}
+
+// ansiColors maps the supported color names to their ANSI foreground codes.
+var ansiColors = map[string]int{
+	"black":   30,
+	"red":     31,
+	"green":   32,
+	"yellow":  33,
+	"blue":    34,
+	"magenta": 35,
+	"cyan":    36,
+	"white":   37,
+}
+
+// defaultLevelColors matches the colors of zap's color level encoders.
+var defaultLevelColors = map[zapcore.Level]int{
+	zapTraceLevel:       ansiColors["magenta"],
+	zapcore.DebugLevel:  ansiColors["magenta"],
+	zapcore.InfoLevel:   ansiColors["blue"],
+	zapcore.WarnLevel:   ansiColors["yellow"],
+	zapcore.ErrorLevel:  ansiColors["red"],
+	zapcore.DPanicLevel: ansiColors["red"],
+	zapcore.PanicLevel:  ansiColors["red"],
+	zapcore.FatalLevel:  ansiColors["red"],
+}
+
+// Validate checks the console settings.
+func (c *ConsoleConfig) Validate() error {
+	switch c.Format {
+	case "", consoleFormatJSON, consoleFormatText:
+	default:
+		return fmt.Errorf("invalid console format '%v', must be json or text", c.Format)
+	}
+	switch c.LevelCase {
+	case "", "upper", "lower":
+	default:
+		return fmt.Errorf("invalid console level_case '%v', must be upper or lower", c.LevelCase)
+	}
+	switch c.Multiline {
+	case "", multilineIndent, multilineEscape, multilineKeep:
+	default:
+		return fmt.Errorf("invalid console multiline '%v', must be indent, escape or keep", c.Multiline)
+	}
+	_, err := c.levelColors()
+	return err
+}
+
+// levelColors returns the ANSI color code of every zap level.
+func (c *ConsoleConfig) levelColors() (map[zapcore.Level]int, error) {
+	colors := make(map[zapcore.Level]int, len(defaultLevelColors))
+	for level, color := range defaultLevelColors {
+		colors[level] = color
+	}
+
+	for name, colorName := range c.LevelColors {
+		var level Level
+		if err := level.Unpack(name); err != nil {
+			return nil, fmt.Errorf("invalid console level_colors: %w", err)
+		}
+		color, found := ansiColors[strings.ToLower(colorName)]
+		if !found {
+			return nil, fmt.Errorf("invalid console color '%v' for level %v", colorName, name)
+		}
+
+		colors[level.ZapLevel()] = color
+		if level >= ErrorLevel {
+			colors[zapcore.DPanicLevel] = color
+			colors[zapcore.PanicLevel] = color
+			colors[zapcore.FatalLevel] = color
+		}
+	}
+	return colors, nil
+}
+
+// buildConsoleEncoder returns the human-readable encoder configured by cfg,
+// using keys for the entry fields. Colors are enabled in auto mode when tty is
+// true.
+func buildConsoleEncoder(cfg ConsoleConfig, keys KeysConfig, tty bool) (zapcore.Encoder, error) {
+	encCfg := ConsoleEncoderConfig()
+	if cfg.LevelCase == "lower" {
+		encCfg.EncodeLevel = lowercaseLevelEncoder
+	}
+
+	if cfg.Color == ColorAlways || (cfg.Color == ColorAuto && tty) {
+		colors, err := cfg.levelColors()
+		if err != nil {
+			return nil, err
+		}
+		encCfg.EncodeLevel = colorLevelEncoder(encCfg.EncodeLevel, colors)
+	}
+
+	enc := zapcore.NewConsoleEncoder(keys.apply(ecszap.ECSCompatibleEncoderConfig(encCfg)))
+	return withMultiline(enc, cfg.Multiline), nil
+}
+
+// withMultiline makes enc keep messages and stack traces spanning multiple
+// lines in a single record. In indent mode the continuation lines are
+// indented, in escape mode the record is written on a single line.
+func withMultiline(enc zapcore.Encoder, mode string) zapcore.Encoder {
+	switch mode {
+	case "", multilineIndent, multilineEscape:
+		return &multilineEncoder{Encoder: enc, escape: mode == multilineEscape}
+	default:
+		return enc
+	}
+}
+
+var (
+	indentReplacer = strings.NewReplacer("\r\n", "\n\t", "\n", "\n\t")
+	escapeReplacer = strings.NewReplacer("\r", `\r`, "\n", `\n`)
+)
+
+type multilineEncoder struct {
+	zapcore.Encoder
+	escape bool
+}
+
+func (e *multilineEncoder) Clone() zapcore.Encoder {
+	return &multilineEncoder{Encoder: e.Encoder.Clone(), escape: e.escape}
+}
+
+func (e *multilineEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	if !e.escape {
+		entry.Message = indentReplacer.Replace(entry.Message)
+		if entry.Stack != "" {
+			entry.Stack = "\t" + indentReplacer.Replace(entry.Stack)
+		}
+		return e.Encoder.EncodeEntry(entry, fields)
+	}
+
+	entry.Message = escapeReplacer.Replace(entry.Message)
+	if entry.Stack != "" {
+		// The console encoder writes the stack trace on its own line, add it
+		// to the JSON encoded fields instead.
+		fields = append(fields[:len(fields):len(fields)], zap.String("stacktrace", entry.Stack))
+		entry.Stack = ""
+	}
+	return e.Encoder.EncodeEntry(entry, fields)
+}
+
+// colorLevelEncoder wraps the level names produced by encode in the ANSI
+// escape sequences of their colors.
+func colorLevelEncoder(encode zapcore.LevelEncoder, colors map[zapcore.Level]int) zapcore.LevelEncoder {
+	return func(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+		color, found := colors[level]
+		if !found {
+			encode(level, enc)
+			return
+		}
+
+		name := levelName{}
+		encode(level, &name)
+		enc.AppendString(fmt.Sprintf("\x1b[%dm%s\x1b[0m", color, name.value))
+	}
+}
+
+// levelName captures the level name appended by a zapcore.LevelEncoder.
+type levelName struct {
+	zapcore.PrimitiveArrayEncoder
+	value string
+}
+
+func (n *levelName) AppendString(s string) { n.value = s }
+
+// isTerminal returns true if f is a character device such as a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,186 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+
+	ucfg "github.com/elastic/go-ucfg"
+)
+
+// mustNewConfig creates the settings with go-ucfg, the config package logs
+// through logp and cannot be imported by its tests.
+func mustNewConfig(fields map[string]interface{}) *ucfg.Config {
+	c, err := ucfg.NewFrom(fields, ucfg.PathSep("."))
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func encodeConsole(t *testing.T, cfg ConsoleConfig, tty bool, level zapcore.Level) string {
+	t.Helper()
+	enc, err := buildConsoleEncoder(cfg, KeysConfig{}, tty)
+	require.NoError(t, err)
+
+	buf, err := enc.EncodeEntry(zapcore.Entry{
+		Level:   level,
+		Time:    time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC),
+		Message: "hello",
+	}, nil)
+	require.NoError(t, err)
+	return buf.String()
+}
+
+func TestConsoleEncoderColors(t *testing.T) {
+	const escape = "\x1b["
+
+	tests := map[string]struct {
+		color ColorMode
+		tty   bool
+		want  bool
+	}{
+		"auto on terminal":     {color: ColorAuto, tty: true, want: true},
+		"auto not on terminal": {color: ColorAuto, tty: false, want: false},
+		"always":               {color: ColorAlways, tty: false, want: true},
+		"never on terminal":    {color: ColorNever, tty: true, want: false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			line := encodeConsole(t, ConsoleConfig{Color: test.color}, test.tty, zapcore.InfoLevel)
+			assert.Equal(t, test.want, strings.Contains(line, escape), line)
+			assert.Contains(t, line, "INFO")
+			assert.Contains(t, line, "hello")
+		})
+	}
+}
+
+func TestConsoleEncoderLevelColorsAndCase(t *testing.T) {
+	cfg := ConsoleConfig{
+		Color:       ColorAlways,
+		LevelCase:   "lower",
+		LevelColors: map[string]string{"warning": "cyan", "error": "magenta"},
+	}
+
+	assert.Contains(t, encodeConsole(t, cfg, false, zapcore.WarnLevel), "\x1b[36mwarn\x1b[0m")
+	assert.Contains(t, encodeConsole(t, cfg, false, zapcore.ErrorLevel), "\x1b[35merror\x1b[0m")
+	assert.Contains(t, encodeConsole(t, cfg, false, zapcore.FatalLevel), "\x1b[35mfatal\x1b[0m")
+	// levels without override keep the default color
+	assert.Contains(t, encodeConsole(t, cfg, false, zapcore.InfoLevel), "\x1b[34minfo\x1b[0m")
+
+	cfg.Color = ColorNever
+	line := encodeConsole(t, cfg, false, zapcore.WarnLevel)
+	assert.Contains(t, line, "\twarn\t")
+	assert.NotContains(t, line, "\x1b[")
+}
+
+func TestConsoleConfigUnpack(t *testing.T) {
+	cfg := DefaultConfig(DefaultEnvironment)
+	err := mustNewConfig(map[string]interface{}{
+		"console.format":               "text",
+		"console.color":                "Never",
+		"console.level_case":           "lower",
+		"console.level_colors.warning": "cyan",
+	}).Unpack(&cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "text", cfg.Console.Format)
+	assert.Equal(t, ColorNever, cfg.Console.Color)
+	assert.Equal(t, map[string]string{"warning": "cyan"}, cfg.Console.LevelColors)
+
+	invalid := []map[string]interface{}{
+		{"console.format": "xml"},
+		{"console.color": "sometimes"},
+		{"console.level_case": "title"},
+		{"console.level_colors.warning": "orange"},
+		{"console.level_colors.verbose": "red"},
+		{"console.multiline": "join"},
+	}
+	for _, settings := range invalid {
+		cfg := DefaultConfig(DefaultEnvironment)
+		assert.Error(t, mustNewConfig(settings).Unpack(&cfg), settings)
+	}
+}
+
+func TestConsoleTextOnlyAffectsStderr(t *testing.T) {
+	cfg := DefaultConfig(DefaultEnvironment)
+	cfg.Console = ConsoleConfig{Format: consoleFormatText, Color: ColorAlways}
+
+	buf, err := buildEncoder(cfg).EncodeEntry(zapcore.Entry{Level: zapcore.InfoLevel, Message: "hello"}, nil)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(buf.String(), "{"), buf.String())
+	assert.NotContains(t, buf.String(), "\x1b[")
+}
+
+func TestConsoleEncoderMultiline(t *testing.T) {
+	entry := zapcore.Entry{
+		Level:   zapcore.ErrorLevel,
+		Time:    time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC),
+		Message: "panic: boom\r\ngoroutine 1 [running]",
+		Stack:   "main.main()\n\tmain.go:10",
+	}
+	encode := func(mode string) string {
+		enc, err := buildConsoleEncoder(ConsoleConfig{Color: ColorNever, Multiline: mode}, KeysConfig{}, false)
+		require.NoError(t, err)
+		buf, err := enc.Clone().EncodeEntry(entry, nil)
+		require.NoError(t, err)
+		return buf.String()
+	}
+
+	t.Run("indent", func(t *testing.T) {
+		lines := strings.Split(strings.TrimSuffix(encode(""), "\n"), "\n")
+		require.Len(t, lines, 4)
+		assert.Contains(t, lines[0], "panic: boom")
+		for _, line := range lines[1:] {
+			assert.True(t, strings.HasPrefix(line, "\t"), line)
+		}
+	})
+
+	t.Run("escape", func(t *testing.T) {
+		line := encode(multilineEscape)
+		assert.Equal(t, 1, strings.Count(line, "\n"), line)
+		assert.Contains(t, line, `panic: boom\r\ngoroutine 1 [running]`)
+		assert.Contains(t, line, `"stacktrace": "main.main()\n\tmain.go:10"`)
+	})
+
+	t.Run("keep", func(t *testing.T) {
+		assert.Equal(t, 4, strings.Count(encode(multilineKeep), "\n"))
+	})
+}
+
+func TestJSONEncoderMultiline(t *testing.T) {
+	cfg := DefaultConfig(DefaultEnvironment)
+	buf, err := buildEncoder(cfg).EncodeEntry(zapcore.Entry{
+		Level:   zapcore.ErrorLevel,
+		Message: "panic: boom\ngoroutine 1 [running]",
+		Stack:   "main.main()\n\tmain.go:10",
+	}, nil)
+	require.NoError(t, err)
+
+	line := strings.TrimSuffix(buf.String(), "\n")
+	require.NotContains(t, line, "\n")
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(line), &record))
+	assert.Equal(t, "panic: boom\ngoroutine 1 [running]", record["message"])
+}
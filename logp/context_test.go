@@ -0,0 +1,47 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggerContext(t *testing.T) {
+	require.NoError(t, DevelopmentSetup(ToObserverOutput()))
+
+	t.Run("stored logger", func(t *testing.T) {
+		logger := NewLogger("request").With("request.id", "abc")
+		ctx := IntoContext(context.Background(), logger)
+		assert.Same(t, logger, FromContext(ctx))
+
+		FromContext(context.WithValue(ctx, struct{}{}, "x")).Info("handled")
+		logs := ObserverLogs().TakeAll()
+		require.Len(t, logs, 1)
+		assert.Equal(t, "request", logs[0].LoggerName)
+		assert.Equal(t, "abc", logs[0].ContextMap()["request.id"])
+	})
+
+	t.Run("default logger", func(t *testing.T) {
+		assert.Same(t, L(), FromContext(context.Background()))
+		assert.Same(t, L(), FromContext(IntoContext(context.Background(), nil)))
+	})
+}
@@ -20,12 +20,14 @@ package logp
 import (
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	golog "log"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync/atomic"
+	"time"
 	"unsafe"
 
 	"github.com/hashicorp/go-multierror"
@@ -48,6 +50,7 @@ var (
 func init() {
 	storeLogger(&coreLogger{
 		selectors:    map[string]struct{}{},
+		disabled:     &disabledSelectors{},
 		rootLogger:   zap.NewNop(),
 		globalLogger: zap.NewNop(),
 		logger:       newLogger(zap.NewNop(), ""),
@@ -56,13 +59,18 @@ func init() {
 
 type coreLogger struct {
 	selectors    map[string]struct{}    // Set of enabled debug selectors.
+	disabled     *disabledSelectors     // Selectors whose logs are dropped.
 	rootLogger   *zap.Logger            // Root logger without any options configured.
 	globalLogger *zap.Logger            // Logger used by legacy global functions (e.g. logp.Info).
 	logger       *Logger                // Logger that is the basis for all logp.Loggers.
 	observedLogs *observer.ObservedLogs // Contains events generated while in observation mode (a testing mode).
+	closers      outputClosers          // Writers closed when the logger is replaced.
 }
 
-// Configure configures the logp package.
+// Configure configures the logp package. The configured level and selectors
+// are overridden by the LevelEnvVar and SelectorsEnvVar environment
+// variables if they are set. An invalid level in LevelEnvVar is ignored and
+// reported with a warning.
 func Configure(cfg Config) error {
 	return ConfigureWithOutputs(cfg)
 }
@@ -70,22 +78,39 @@ func Configure(cfg Config) error {
 // ConfigureWithOutputs XXX: is used by elastic-agent only (See file: x-pack/elastic-agent/pkg/core/logger/logger.go).
 // The agent requires that the output specified in the config object is configured and merged with the
 // logging outputs given.
+//
+// The environment variables override the configuration as in Configure.
 func ConfigureWithOutputs(cfg Config, outputs ...zapcore.Core) error {
+	// An invalid level in the environment must not prevent logging, it is
+	// reported once the logger is configured.
+	envErr := applyEnvOverrides(&cfg)
+	disabled, err := newDisabledSelectors(cfg.DisabledSelectors)
+	if err != nil {
+		return err
+	}
+
 	var (
 		sink         zapcore.Core
 		observedLogs *observer.ObservedLogs
-		err          error
+		closers      outputClosers
 	)
 
 	// Build a single output (stderr has priority if more than one are enabled).
 	if cfg.toObserver {
 		sink, observedLogs = observer.New(cfg.Level.ZapLevel())
 	} else {
-		sink, err = createLogOutput(cfg)
+		sink, err = createLogOutput(cfg, &closers)
+	}
+	if err != nil {
+		closers.close()
+		return fmt.Errorf("failed to build log output: %w", err)
 	}
+	sink, err = withRoutes(sink, cfg, &closers)
 	if err != nil {
+		closers.close()
 		return fmt.Errorf("failed to build log output: %w", err)
 	}
+	sink = newMultiCore(sink, newHookCore(defaultHooks, cfg.Hooks.Level))
 
 	// Default logger is always discard, debug level below will
 	// possibly re-enable it.
@@ -114,19 +139,26 @@ func ConfigureWithOutputs(cfg Config, outputs ...zapcore.Core) error {
 		sink = selectiveWrapper(sink, selectors)
 	}
 
-	sink = newMultiCore(append(outputs, sink)...)
-	root := zap.New(sink, makeOptions(cfg)...)
+	sink = &disabledCore{Core: newMultiCore(append(outputs, sink)...), selectors: disabled}
+	root := zap.New(withMetadata(sink, cfg), makeOptions(cfg)...)
 	storeLogger(&coreLogger{
 		selectors:    selectors,
+		disabled:     disabled,
 		rootLogger:   root,
 		globalLogger: root.WithOptions(zap.AddCallerSkip(1)),
 		logger:       newLogger(root, ""),
 		observedLogs: observedLogs,
+		closers:      closers,
 	})
+	if envErr != nil {
+		newLogger(root, "logp").Warnf("Ignoring %v, the logging level is %v.", envErr, cfg.Level)
+	}
 	return nil
 }
 
-func createLogOutput(cfg Config) (zapcore.Core, error) {
+// createLogOutput creates the output selected in cfg, the writers to close
+// when the logger is replaced are added to closers.
+func createLogOutput(cfg Config, closers *outputClosers) (zapcore.Core, error) {
 	switch {
 	case cfg.toIODiscard:
 		return makeDiscardOutput(cfg)
@@ -136,8 +168,12 @@ func createLogOutput(cfg Config) (zapcore.Core, error) {
 		return makeSyslogOutput(cfg)
 	case cfg.ToEventLog:
 		return makeEventLogOutput(cfg)
+	case cfg.ToGELF:
+		return makeGELFOutput(cfg)
+	case cfg.ToJournald:
+		return makeJournaldOutput(cfg)
 	case cfg.ToFiles:
-		return makeFileOutput(cfg)
+		return makeFileOutput(cfg, closers)
 	}
 
 	switch cfg.environment {
@@ -146,12 +182,14 @@ func createLogOutput(cfg Config) (zapcore.Core, error) {
 	case MacOSServiceEnvironment, WindowsServiceEnvironment:
 		fallthrough
 	default:
-		return makeFileOutput(cfg)
+		return makeFileOutput(cfg, closers)
 	}
 }
 
 // DevelopmentSetup configures the logger in development mode at debug level.
-// By default the output goes to stderr.
+// By default the output goes to stderr. As with Configure, the LevelEnvVar
+// and SelectorsEnvVar environment variables of the shell override the
+// level and selectors.
 func DevelopmentSetup(options ...Option) error {
 	cfg := Config{
 		Level:       DebugLevel,
@@ -166,7 +204,9 @@ func DevelopmentSetup(options ...Option) error {
 }
 
 // TestingSetup configures logging by calling DevelopmentSetup if and only if
-// verbose testing is enabled (as in 'go test -v').
+// verbose testing is enabled (as in 'go test -v'). The LevelEnvVar and
+// SelectorsEnvVar environment variables of the shell override the level and
+// selectors of the tests.
 func TestingSetup(options ...Option) error {
 	// Use the flag to avoid a dependency on the testing package.
 	f := flag.Lookup("test.v")
@@ -183,7 +223,9 @@ func ObserverLogs() *observer.ObservedLogs {
 }
 
 // Sync flushes any buffered log entries. Applications should take care to call
-// Sync before exiting.
+// Sync before exiting. The errors reported by each of the configured outputs
+// are aggregated in the returned error; syncing a console is not considered
+// an error.
 func Sync() error {
 	return loadLogger().rootLogger.Sync()
 }
@@ -196,18 +238,20 @@ func makeOptions(cfg Config) []zap.Option {
 	if cfg.development {
 		options = append(options, zap.Development())
 	}
-	if cfg.Beat != "" {
-		fields := []zap.Field{
-			zap.String("service.name", cfg.Beat),
-		}
-		options = append(options, zap.Fields(fields...))
-	}
 	return options
 }
 
 func makeStderrOutput(cfg Config) (zapcore.Core, error) {
-	stderr := zapcore.Lock(os.Stderr)
-	return newCore(buildEncoder(cfg), stderr, cfg.Level.ZapLevel()), nil
+	stderr := consoleWriteSyncer{zapcore.Lock(os.Stderr)}
+	if cfg.Console.Format != consoleFormatText {
+		return newOutputCore("stderr", buildEncoder(cfg), stderr, cfg.Level.ZapLevel()), nil
+	}
+
+	enc, err := buildConsoleEncoder(cfg.Console, cfg.Keys, isTerminal(os.Stderr))
+	if err != nil {
+		return nil, err
+	}
+	return newOutputCore("stderr", withOriginFields(enc, cfg), stderr, cfg.Level.ZapLevel()), nil
 }
 
 func makeDiscardOutput(cfg Config) (zapcore.Core, error) {
@@ -220,7 +264,7 @@ func makeSyslogOutput(cfg Config) (zapcore.Core, error) {
 	if err != nil {
 		return nil, err
 	}
-	return wrappedCore(core), nil
+	return wrappedCore(withStats("syslog", core)), nil
 }
 
 func makeEventLogOutput(cfg Config) (zapcore.Core, error) {
@@ -229,30 +273,94 @@ func makeEventLogOutput(cfg Config) (zapcore.Core, error) {
 	if err != nil {
 		return nil, err
 	}
-	return wrappedCore(core), nil
+	return wrappedCore(withStats("eventlog", core)), nil
 }
 
-func makeFileOutput(cfg Config) (zapcore.Core, error) {
-	filename := paths.Resolve(paths.Logs, filepath.Join(cfg.Files.Path, cfg.LogFilename()))
+func makeGELFOutput(cfg Config) (zapcore.Core, error) {
+	core, err := newGELF(cfg.GELF, cfg.Level.ZapLevel())
+	if err != nil {
+		return nil, err
+	}
+	return wrappedCore(withStats("gelf", core)), nil
+}
+
+// makeJournaldOutput falls back to stderr if the journal socket does not
+// exist, e.g. when the process does not run on a systemd host. The fallback
+// is reported in the stderr output.
+func makeJournaldOutput(cfg Config) (zapcore.Core, error) {
+	if !journaldAvailable(cfg.Journald.Socket) {
+		core, err := makeStderrOutput(cfg)
+		if err != nil {
+			return nil, err
+		}
+		_ = core.Write(zapcore.Entry{
+			Level:   zapcore.WarnLevel,
+			Time:    time.Now(),
+			Message: fmt.Sprintf("systemd journal socket %v not available, logging to stderr", cfg.Journald.Socket),
+		}, nil)
+		return core, nil
+	}
+
+	core, err := newJournald(cfg.Journald, cfg.Beat, cfg.Level.ZapLevel())
+	if err != nil {
+		return nil, err
+	}
+	return wrappedCore(withStats("journald", core)), nil
+}
+
+func makeFileOutput(cfg Config, closers *outputClosers) (zapcore.Core, error) {
+	out, err := makeFileWriter("file", cfg.Files, cfg.LogFilename(), closers)
+	if err != nil {
+		return nil, err
+	}
+
+	return newOutputCore("file", buildEncoder(cfg), out, cfg.Level.ZapLevel()), nil
+}
+
+// makeFileWriter creates the file rotator, wrapped in an asynchronous
+// writer if enabled. The asynchronous writer reports its errors and drops in
+// the stats of output. The writer is added to closers.
+func makeFileWriter(output string, cfg FileConfig, name string, closers *outputClosers) (zapcore.WriteSyncer, error) {
+	rotator, err := makeFileRotator(cfg, name)
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.Async.Enabled {
+		closers.add(rotator)
+		return rotator, nil
+	}
+	w := newAsyncWriter(rotator, cfg.Async.BufferSize, cfg.Async.FlushTimeout, statsFor(output))
+	closers.add(w)
+	return w, nil
+}
+
+func makeFileRotator(cfg FileConfig, name string) (*file.Rotator, error) {
+	filename := paths.Resolve(paths.Logs, filepath.Join(cfg.Path, name))
 
 	rotator, err := file.NewFileRotator(filename,
-		file.MaxSizeBytes(cfg.Files.MaxSize),
-		file.MaxBackups(cfg.Files.MaxBackups),
-		file.Permissions(os.FileMode(cfg.Files.Permissions)),
-		file.Interval(cfg.Files.Interval),
-		file.RotateOnStartup(cfg.Files.RotateOnStartup),
-		file.RedirectStderr(cfg.Files.RedirectStderr),
+		file.MaxSizeBytes(cfg.MaxSize),
+		file.MaxBackups(cfg.MaxBackups),
+		file.Permissions(os.FileMode(cfg.Permissions)),
+		file.Interval(cfg.Interval),
+		file.RotateOnStartup(cfg.RotateOnStartup),
+		file.RedirectStderr(cfg.RedirectStderr),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create file rotator: %w", err)
 	}
-
-	return newCore(buildEncoder(cfg), rotator, cfg.Level.ZapLevel()), nil
+	return rotator, nil
 }
 
 func newCore(enc zapcore.Encoder, ws zapcore.WriteSyncer, enab zapcore.LevelEnabler) zapcore.Core {
 	return wrappedCore(zapcore.NewCore(enc, ws, enab))
 }
+
+// newOutputCore creates the core of an output, counting its write errors in
+// the stats of output.
+func newOutputCore(output string, enc zapcore.Encoder, ws zapcore.WriteSyncer, enab zapcore.LevelEnabler) zapcore.Core {
+	return wrappedCore(withStats(output, zapcore.NewCore(enc, ws, enab)))
+}
+
 func wrappedCore(core zapcore.Core) zapcore.Core {
 	return ecszap.WrapCore(core)
 }
@@ -266,11 +374,33 @@ func loadLogger() *coreLogger {
 	return (*coreLogger)(p)
 }
 
+// storeLogger replaces the logger, the writers of the previous one are
+// closed once it is replaced.
 func storeLogger(l *coreLogger) {
-	if old := loadLogger(); old != nil {
+	old := loadLogger()
+	if old != nil {
 		_ = old.rootLogger.Sync()
 	}
 	atomic.StorePointer(&_log, unsafe.Pointer(l))
+	if old != nil {
+		old.closers.close()
+	}
+}
+
+// outputClosers are the writers created for a logger. The asynchronous
+// writers are drained and their goroutine stopped when they are closed.
+type outputClosers []io.Closer
+
+func (c *outputClosers) add(closer io.Closer) {
+	if c != nil {
+		*c = append(*c, closer)
+	}
+}
+
+func (c outputClosers) close() {
+	for _, closer := range c {
+		_ = closer.Close()
+	}
 }
 
 // newMultiCore creates a sink that sends to multiple cores.
@@ -310,10 +440,13 @@ func (m multiCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *za
 	return checked
 }
 
-// Write writes the entry to each core.
+// Write writes the entry to each core enabled for its level.
 func (m multiCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
 	var errs error
 	for _, core := range m.cores {
+		if !core.Enabled(entry.Level) {
+			continue
+		}
 		if err := core.Write(entry, fields); err != nil {
 			errs = multierror.Append(errs, err)
 		}
@@ -18,13 +18,17 @@
 package logp
 
 import (
+	"errors"
 	"io/ioutil"
 	golog "log"
+	"os"
+	"syscall"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 func TestLogger(t *testing.T) {
@@ -182,3 +186,36 @@ func TestLoggingECSFields(t *testing.T) {
 		}
 	}
 }
+
+type syncErrorWriter struct {
+	err error
+}
+
+func (w syncErrorWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w syncErrorWriter) Sync() error                 { return w.err }
+
+func TestSyncReportsOutputErrors(t *testing.T) {
+	errDiskFull := errors.New("disk full")
+	failing := zapcore.NewCore(zapcore.NewJSONEncoder(JSONEncoderConfig()), syncErrorWriter{errDiskFull}, zapcore.DebugLevel)
+
+	cfg := DefaultConfig(DefaultEnvironment)
+	ToDiscardOutput()(&cfg)
+	require.NoError(t, ConfigureWithOutputs(cfg, failing))
+	defer func() {
+		require.NoError(t, DevelopmentSetup(ToDiscardOutput()))
+	}()
+
+	NewLogger("sync").Info("message")
+	err := Sync()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, errDiskFull), err)
+}
+
+func TestConsoleSyncIgnoresUnsupported(t *testing.T) {
+	unsupported := &os.PathError{Op: "sync", Path: "/dev/stderr", Err: syscall.EINVAL}
+	assert.NoError(t, consoleWriteSyncer{syncErrorWriter{unsupported}}.Sync())
+	assert.NoError(t, consoleWriteSyncer{syncErrorWriter{nil}}.Sync())
+
+	errDiskFull := errors.New("disk full")
+	assert.Equal(t, errDiskFull, consoleWriteSyncer{syncErrorWriter{errDiskFull}}.Sync())
+}
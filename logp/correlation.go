@@ -0,0 +1,47 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// CorrelationIDKey is the field used to store the correlation id of a logger.
+const CorrelationIDKey = "labels.correlation_id"
+
+// correlationIDSize is the number of random bytes in a generated correlation id.
+const correlationIDSize = 8
+
+// WithCorrelationID returns a child of logger that annotates every message
+// with the given correlation id. Loggers derived from the returned logger
+// inherit the id.
+func WithCorrelationID(logger *Logger, id string) *Logger {
+	return logger.With(String(CorrelationIDKey, id))
+}
+
+// NewCorrelationID generates a short random id suitable to be used with
+// WithCorrelationID.
+func NewCorrelationID() string {
+	buf := make([]byte, correlationIDSize)
+	if _, err := rand.Read(buf); err != nil {
+		// crypto/rand should never fail, but don't leave the id empty if it does.
+		return "00000000"
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,48 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCorrelationID(t *testing.T) {
+	id := NewCorrelationID()
+	assert.Len(t, id, 2*correlationIDSize)
+	assert.NotEqual(t, id, NewCorrelationID())
+}
+
+func TestWithCorrelationID(t *testing.T) {
+	require.NoError(t, DevelopmentSetup(ToObserverOutput()))
+
+	id := NewCorrelationID()
+	logger := WithCorrelationID(NewLogger("correlation"), id)
+
+	logger.Info("parent")
+	logger.With("x", 1).Named("child").Info("child")
+
+	logs := ObserverLogs().TakeAll()
+	require.Len(t, logs, 2)
+	for _, entry := range logs {
+		assert.Equal(t, id, entry.ContextMap()[CorrelationIDKey], entry.Message)
+	}
+	assert.EqualValues(t, 1, logs[1].ContextMap()["x"])
+}
@@ -0,0 +1,102 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// disabledSelectors is the set of selectors whose logs are dropped. It is
+// shared by all the cores derived from the root logger to be updated at
+// runtime.
+type disabledSelectors struct {
+	patterns atomic.Value // []string
+}
+
+func newDisabledSelectors(selectors []string) (*disabledSelectors, error) {
+	d := &disabledSelectors{}
+	if err := d.set(selectors); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *disabledSelectors) set(selectors []string) error {
+	patterns := make([]string, 0, len(selectors))
+	for _, sel := range selectors {
+		sel = strings.TrimSpace(sel)
+		if sel == "" {
+			continue
+		}
+		if _, err := path.Match(sel, ""); err != nil {
+			return fmt.Errorf("invalid disabled selector '%v': %w", sel, err)
+		}
+		patterns = append(patterns, sel)
+	}
+	d.patterns.Store(patterns)
+	return nil
+}
+
+func (d *disabledSelectors) disabled(name string) bool {
+	patterns, _ := d.patterns.Load().([]string)
+	for _, sel := range patterns {
+		if ok, _ := path.Match(sel, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// SetDisabledSelectors replaces the disabled selectors of the configured
+// logger, the logs of these selectors are dropped regardless of their level.
+// The selectors can be globs as supported by path.Match. The change applies
+// to the existing loggers, until the next call to Configure.
+func SetDisabledSelectors(selectors ...string) error {
+	return loadLogger().disabled.set(selectors)
+}
+
+// disabledCore drops the logs of the disabled selectors.
+type disabledCore struct {
+	zapcore.Core
+	selectors *disabledSelectors
+}
+
+func (c *disabledCore) With(fields []zapcore.Field) zapcore.Core {
+	return &disabledCore{Core: c.Core.With(fields), selectors: c.selectors}
+}
+
+func (c *disabledCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.selectors.disabled(ent.LoggerName) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// Write checks the selectors again, the core is called directly if it is
+// wrapped, e.g. by the metadata fields.
+func (c *disabledCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if c.selectors.disabled(ent.LoggerName) {
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
@@ -0,0 +1,60 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisabledSelectors(t *testing.T) {
+	err := DevelopmentSetup(ToObserverOutput(), WithDisabledSelectors("noisy", "vendor.*"))
+	require.NoError(t, err)
+
+	noisy := NewLogger("noisy")
+	noisy.Debug("debug")
+	noisy.Error("error")
+	NewLogger("vendor").Named("client").Info("info")
+	assert.Empty(t, ObserverLogs().TakeAll())
+	assert.False(t, IsDebug("noisy"))
+
+	NewLogger("other").Debug("debug")
+	assert.Len(t, ObserverLogs().TakeAll(), 1)
+
+	t.Run("reconfigured at runtime", func(t *testing.T) {
+		logger := noisy.With("key", "value")
+		require.NoError(t, SetDisabledSelectors("other"))
+		logger.Debug("debug")
+		NewLogger("other").Error("error")
+		logs := ObserverLogs().TakeAll()
+		if assert.Len(t, logs, 1) {
+			assert.Equal(t, "noisy", logs[0].LoggerName)
+		}
+
+		require.NoError(t, SetDisabledSelectors())
+		NewLogger("other").Debug("debug")
+		assert.Len(t, ObserverLogs().TakeAll(), 1)
+	})
+
+	t.Run("invalid selector", func(t *testing.T) {
+		assert.Error(t, SetDisabledSelectors("["))
+		assert.Error(t, DevelopmentSetup(ToObserverOutput(), WithDisabledSelectors("[")))
+	})
+}
@@ -18,6 +18,8 @@
 package logp
 
 import (
+	"fmt"
+
 	"go.uber.org/zap/zapcore"
 
 	"go.elastic.co/ecszap"
@@ -31,7 +33,7 @@ var baseEncodingConfig = zapcore.EncoderConfig{
 	MessageKey:     "message",
 	StacktraceKey:  "stacktrace",
 	LineEnding:     zapcore.DefaultLineEnding,
-	EncodeLevel:    zapcore.LowercaseLevelEncoder,
+	EncodeLevel:    lowercaseLevelEncoder,
 	EncodeTime:     zapcore.ISO8601TimeEncoder,
 	EncodeDuration: zapcore.NanosDurationEncoder,
 	EncodeCaller:   zapcore.ShortCallerEncoder,
@@ -51,8 +53,42 @@ func buildEncoder(cfg Config) zapcore.Encoder {
 		encCreator = zapcore.NewJSONEncoder
 	}
 
-	encCfg = ecszap.ECSCompatibleEncoderConfig(encCfg)
-	return encCreator(encCfg)
+	encCfg = cfg.Keys.apply(ecszap.ECSCompatibleEncoderConfig(encCfg))
+	return withOriginFields(encCreator(encCfg), cfg)
+}
+
+// Validate checks the keys are not used twice.
+func (k *KeysConfig) Validate() error {
+	encCfg := k.apply(ecszap.ECSCompatibleEncoderConfig(baseEncodingConfig))
+	keys := map[string]string{}
+	for _, key := range [][2]string{
+		{"message", encCfg.MessageKey},
+		{"level", encCfg.LevelKey},
+		{"timestamp", encCfg.TimeKey},
+		{"logger", encCfg.NameKey},
+		{"caller", encCfg.CallerKey},
+		{"stacktrace", encCfg.StacktraceKey},
+	} {
+		if other, exists := keys[key[1]]; exists {
+			return fmt.Errorf("log %v key '%v' is already used by the %v", key[0], key[1], other)
+		}
+		keys[key[1]] = key[0]
+	}
+	return nil
+}
+
+// apply overrides the keys of encCfg that are set.
+func (k KeysConfig) apply(encCfg zapcore.EncoderConfig) zapcore.EncoderConfig {
+	if k.Message != "" {
+		encCfg.MessageKey = k.Message
+	}
+	if k.Level != "" {
+		encCfg.LevelKey = k.Level
+	}
+	if k.Timestamp != "" {
+		encCfg.TimeKey = k.Timestamp
+	}
+	return encCfg
 }
 
 func JSONEncoderConfig() zapcore.EncoderConfig {
@@ -61,7 +97,7 @@ func JSONEncoderConfig() zapcore.EncoderConfig {
 
 func ConsoleEncoderConfig() zapcore.EncoderConfig {
 	c := baseEncodingConfig
-	c.EncodeLevel = zapcore.CapitalLevelEncoder
+	c.EncodeLevel = capitalLevelEncoder
 	c.EncodeName = bracketedNameEncoder
 	return c
 }
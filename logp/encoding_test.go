@@ -0,0 +1,101 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func encodeJSON(t *testing.T, cfg Config) map[string]interface{} {
+	t.Helper()
+	buf, err := buildEncoder(cfg).EncodeEntry(zapcore.Entry{
+		Level:   zapcore.WarnLevel,
+		Time:    time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC),
+		Message: "hello",
+	}, nil)
+	require.NoError(t, err)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	return record
+}
+
+func TestEncoderKeys(t *testing.T) {
+	t.Run("ECS by default", func(t *testing.T) {
+		record := encodeJSON(t, DefaultConfig(DefaultEnvironment))
+		assert.Equal(t, "hello", record["message"])
+		assert.Equal(t, "warn", record["log.level"])
+		assert.Equal(t, "2022-03-01T10:00:00.000Z", record["@timestamp"])
+	})
+
+	t.Run("custom keys", func(t *testing.T) {
+		cfg := DefaultConfig(DefaultEnvironment)
+		cfg.Keys = KeysConfig{Message: "msg", Level: "lvl", Timestamp: "ts"}
+		record := encodeJSON(t, cfg)
+		assert.Equal(t, "hello", record["msg"])
+		assert.Equal(t, "warn", record["lvl"])
+		assert.Equal(t, "2022-03-01T10:00:00.000Z", record["ts"])
+		assert.NotContains(t, record, "message")
+		assert.NotContains(t, record, "log.level")
+		assert.NotContains(t, record, "@timestamp")
+	})
+
+	t.Run("partial override", func(t *testing.T) {
+		cfg := DefaultConfig(DefaultEnvironment)
+		cfg.Keys = KeysConfig{Message: "msg"}
+		record := encodeJSON(t, cfg)
+		assert.Equal(t, "hello", record["msg"])
+		assert.Equal(t, "warn", record["log.level"])
+	})
+
+	t.Run("console", func(t *testing.T) {
+		enc, err := buildConsoleEncoder(ConsoleConfig{Color: ColorNever}, KeysConfig{Message: "msg"}, false)
+		require.NoError(t, err)
+		buf, err := enc.EncodeEntry(zapcore.Entry{Level: zapcore.InfoLevel, Message: "hello"}, nil)
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "hello")
+	})
+}
+
+func TestKeysConfigUnpack(t *testing.T) {
+	cfg := DefaultConfig(DefaultEnvironment)
+	c := mustNewConfig(map[string]interface{}{
+		"keys": map[string]interface{}{"message": "msg", "level": "lvl"},
+	})
+	require.NoError(t, c.Unpack(&cfg))
+	assert.Equal(t, KeysConfig{Message: "msg", Level: "lvl"}, cfg.Keys)
+
+	for name, keys := range map[string]map[string]interface{}{
+		"duplicate keys":    {"message": "msg", "level": "msg"},
+		"ECS key conflict":  {"message": "log.level"},
+		"logger key in use": {"timestamp": "log.logger"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := DefaultConfig(DefaultEnvironment)
+			err := mustNewConfig(map[string]interface{}{"keys": keys}).Unpack(&cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "is already used by the")
+		})
+	}
+}
@@ -0,0 +1,64 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// LevelEnvVar is the environment variable overriding the configured
+	// logging level, e.g. `ELASTIC_AGENT_LOG_LEVEL=debug`. It allows to debug
+	// a process in the field without editing its configuration.
+	LevelEnvVar = "ELASTIC_AGENT_LOG_LEVEL"
+
+	// SelectorsEnvVar is the environment variable overriding the configured
+	// debug selectors with a comma separated list, e.g.
+	// `ELASTIC_AGENT_LOG_SELECTORS=publisher,http`. The selectors only apply
+	// at debug level.
+	SelectorsEnvVar = "ELASTIC_AGENT_LOG_SELECTORS"
+)
+
+// applyEnvOverrides replaces the level and selectors of cfg with the values
+// of LevelEnvVar and SelectorsEnvVar when they are set and not empty. The
+// environment takes precedence over the configuration. An invalid level is
+// ignored, the configured level is kept and the returned error reports it.
+func applyEnvOverrides(cfg *Config) error {
+	var err error
+	if value := strings.TrimSpace(os.Getenv(LevelEnvVar)); value != "" {
+		var level Level
+		if uerr := level.Unpack(value); uerr != nil {
+			err = fmt.Errorf("invalid %v: %w", LevelEnvVar, uerr)
+		} else {
+			cfg.Level = level
+		}
+	}
+
+	if value := strings.TrimSpace(os.Getenv(SelectorsEnvVar)); value != "" {
+		var selectors []string
+		for _, sel := range strings.Split(value, ",") {
+			if sel = strings.TrimSpace(sel); sel != "" {
+				selectors = append(selectors, sel)
+			}
+		}
+		cfg.Selectors = selectors
+	}
+	return err
+}
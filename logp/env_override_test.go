@@ -0,0 +1,84 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestEnvOverrides(t *testing.T) {
+	configure := func(t *testing.T) {
+		t.Helper()
+		cfg := DefaultConfig(DefaultEnvironment)
+		cfg.Level = InfoLevel
+		cfg.Selectors = []string{"config"}
+		ToObserverOutput()(&cfg)
+		require.NoError(t, Configure(cfg))
+		t.Cleanup(func() { _ = DevelopmentSetup(ToObserverOutput()) })
+	}
+
+	t.Run("config only", func(t *testing.T) {
+		configure(t)
+		NewLogger("http").Debug("not logged")
+		NewLogger("http").Info("logged")
+		assert.Len(t, ObserverLogs().TakeAll(), 1)
+	})
+
+	t.Run("level and selectors", func(t *testing.T) {
+		t.Setenv(LevelEnvVar, "DEBUG")
+		t.Setenv(SelectorsEnvVar, " http, publisher ,")
+		configure(t)
+
+		NewLogger("http").Debug("http")
+		NewLogger("publisher").Debug("publisher")
+		NewLogger("config").Debug("config")
+		logs := ObserverLogs().TakeAll()
+		require.Len(t, logs, 2)
+		assert.Equal(t, "http", logs[0].Message)
+		assert.Equal(t, "publisher", logs[1].Message)
+	})
+
+	t.Run("level only", func(t *testing.T) {
+		t.Setenv(LevelEnvVar, "error")
+		configure(t)
+
+		NewLogger("config").Warn("not logged")
+		NewLogger("config").Error("logged")
+		assert.Len(t, ObserverLogs().TakeAll(), 1)
+	})
+
+	t.Run("invalid level", func(t *testing.T) {
+		t.Setenv(LevelEnvVar, "verbose")
+		t.Setenv(SelectorsEnvVar, "http")
+		configure(t)
+
+		logs := ObserverLogs().TakeAll()
+		require.Len(t, logs, 1)
+		assert.Equal(t, "logp", logs[0].LoggerName)
+		assert.Equal(t, zapcore.WarnLevel, logs[0].Level)
+		assert.Contains(t, logs[0].Message, "Ignoring invalid "+LevelEnvVar+": invalid level 'verbose', the logging level is info.")
+
+		NewLogger("config").Debug("not logged")
+		NewLogger("config").Info("logged")
+		assert.Len(t, ObserverLogs().TakeAll(), 1)
+	})
+}
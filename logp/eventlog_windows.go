@@ -34,13 +34,24 @@ const (
 
 const alreadyExistsMsg = "registry key already exists"
 
+// eventLogWriter is implemented by *eventlog.Log.
+type eventLogWriter interface {
+	Info(eid uint32, msg string) error
+	Warning(eid uint32, msg string) error
+	Error(eid uint32, msg string) error
+}
+
 type eventLogCore struct {
 	zapcore.LevelEnabler
 	encoder zapcore.Encoder
-	fields  []zapcore.Field
-	log     *eventlog.Log
+	log     eventLogWriter
 }
 
+// newEventLog creates a core writing to the Application event log using
+// appName as the event source. The source is registered on first use, which
+// requires administrator rights. Processes running without them need the
+// source to be registered beforehand, e.g. by the installer or with the
+// PowerShell New-EventLog cmdlet.
 func newEventLog(appName string, encoder zapcore.Encoder, enab zapcore.LevelEnabler) (zapcore.Core, error) {
 	if appName == "" {
 		return nil, errors.New("appName cannot be empty")
@@ -58,16 +69,22 @@ func newEventLog(appName string, encoder zapcore.Encoder, enab zapcore.LevelEnab
 		return nil, fmt.Errorf("failed to open eventlog: %w", err)
 	}
 
+	return newEventLogCore(log, encoder, enab), nil
+}
+
+func newEventLogCore(log eventLogWriter, encoder zapcore.Encoder, enab zapcore.LevelEnabler) *eventLogCore {
 	return &eventLogCore{
 		LevelEnabler: enab,
 		encoder:      encoder,
 		log:          log,
-	}, nil
+	}
 }
 
 func (c *eventLogCore) With(fields []zapcore.Field) zapcore.Core {
 	clone := c.Clone()
-	clone.fields = append(clone.fields, fields...)
+	for _, field := range fields {
+		field.AddTo(clone.encoder)
+	}
 	return clone
 }
 
@@ -86,7 +103,7 @@ func (c *eventLogCore) Write(entry zapcore.Entry, fields []zapcore.Field) error
 
 	msg := buffer.String()
 	switch entry.Level {
-	case zapcore.DebugLevel, zapcore.InfoLevel:
+	case zapTraceLevel, zapcore.DebugLevel, zapcore.InfoLevel:
 		return c.log.Info(eventID, msg)
 	case zapcore.WarnLevel:
 		return c.log.Warning(eventID, msg)
@@ -104,7 +121,5 @@ func (c *eventLogCore) Sync() error {
 func (c *eventLogCore) Clone() *eventLogCore {
 	clone := *c
 	clone.encoder = c.encoder.Clone()
-	clone.fields = make([]zapcore.Field, len(c.fields), len(c.fields)+10)
-	copy(clone.fields, c.fields)
 	return &clone
 }
@@ -0,0 +1,67 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type eventLogRecord struct {
+	kind string
+	msg  string
+}
+
+type fakeEventLog struct {
+	records []eventLogRecord
+}
+
+func (l *fakeEventLog) Info(_ uint32, msg string) error    { return l.add("info", msg) }
+func (l *fakeEventLog) Warning(_ uint32, msg string) error { return l.add("warning", msg) }
+func (l *fakeEventLog) Error(_ uint32, msg string) error   { return l.add("error", msg) }
+
+func (l *fakeEventLog) add(kind, msg string) error {
+	l.records = append(l.records, eventLogRecord{kind: kind, msg: msg})
+	return nil
+}
+
+func TestEventLogCore(t *testing.T) {
+	log := &fakeEventLog{}
+	core := newEventLogCore(log, zapcore.NewJSONEncoder(JSONEncoderConfig()), zapTraceLevel)
+	logger := newLogger(zap.New(core), "eventlog").With("service.id", "abc")
+
+	logger.Trace("trace message")
+	logger.Debug("debug message")
+	logger.Infow("info message", "key", "value")
+	logger.Warn("warn message")
+	logger.Error("error message")
+
+	require.Len(t, log.records, 5)
+	kinds := make([]string, 0, len(log.records))
+	for _, record := range log.records {
+		kinds = append(kinds, record.kind)
+		assert.Contains(t, record.msg, `"service.id":"abc"`)
+	}
+	assert.Equal(t, []string{"info", "info", "info", "warning", "error"}, kinds)
+	assert.Contains(t, log.records[2].msg, `"message":"info message"`)
+	assert.Contains(t, log.records[2].msg, `"key":"value"`)
+}
@@ -18,6 +18,8 @@
 package logp
 
 import (
+	"time"
+
 	"go.uber.org/zap"
 )
 
@@ -74,3 +76,17 @@ var (
 	Uintptr     = zap.Uintptr
 	Uintptrs    = zap.Uintptrs
 )
+
+// DurationMillis constructs a field holding d in milliseconds, as a float,
+// e.g. a duration of 1.5ms is written as `1.5`. The key is used as is, it
+// should name the unit, e.g. `took.ms`. Note that the ECS `event.duration`
+// field is in nanoseconds, use Duration for it.
+func DurationMillis(key string, d time.Duration) zap.Field {
+	return zap.Float64(key, float64(d)/float64(time.Millisecond))
+}
+
+// Bytes constructs a field holding a size in bytes. The key is used as is, it
+// should name the unit as in ECS fields like `http.response.body.bytes`.
+func Bytes(key string, n int64) zap.Field {
+	return zap.Int64(key, n)
+}
@@ -0,0 +1,60 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestUnitFields(t *testing.T) {
+	tests := map[string]struct {
+		field zapcore.Field
+		key   string
+		value interface{}
+	}{
+		"milliseconds":      {field: DurationMillis("took.ms", 1500*time.Microsecond), key: "took.ms", value: 1.5},
+		"key is kept":       {field: DurationMillis("took", 2*time.Second), key: "took", value: float64(2000)},
+		"sub-millisecond":   {field: DurationMillis("took.ms", 250*time.Nanosecond), key: "took.ms", value: 0.00025},
+		"negative duration": {field: DurationMillis("offset.ms", -time.Millisecond), key: "offset.ms", value: float64(-1)},
+		"bytes":             {field: Bytes("http.response.body.bytes", 1024), key: "http.response.body.bytes", value: int64(1024)},
+		"duration":          {field: Duration("event.duration", 1500*time.Microsecond), key: "event.duration", value: 1500 * time.Microsecond},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			enc := zapcore.NewMapObjectEncoder()
+			test.field.AddTo(enc)
+			assert.Equal(t, map[string]interface{}{test.key: test.value}, enc.Fields)
+		})
+	}
+}
+
+func TestUnitFieldsJSON(t *testing.T) {
+	enc := zapcore.NewJSONEncoder(JSONEncoderConfig())
+	buf, err := enc.EncodeEntry(zapcore.Entry{Message: "request"}, []zapcore.Field{
+		Duration("event.duration", 12345678*time.Nanosecond),
+		DurationMillis("took.ms", 12345678*time.Nanosecond),
+		Bytes("http.request.body.bytes", 512),
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), `"event.duration":12345678,"took.ms":12.345678,"http.request.body.bytes":512`)
+}
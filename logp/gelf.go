@@ -0,0 +1,283 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	gelfVersion          = "1.1"
+	defaultGELFChunkSize = 1420
+	gelfChunkHeaderSize  = 12
+	gelfMaxChunks        = 128
+	gelfDialTimeout      = 5 * time.Second
+)
+
+var gelfChunkMagic = []byte{0x1e, 0x0f}
+
+// gelfCore sends log entries in the GELF 1.1 format. Fields are sent as
+// additional fields, prefixed with `_` and with nested objects flattened to
+// dotted keys, e.g. `_service.name`.
+type gelfCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	host    string
+	writer  *gelfWriter
+}
+
+type gelfWriter struct {
+	network   string
+	address   string
+	chunkSize int
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// newGELF returns a new Core sending logs to a GELF input over UDP or TCP.
+// Messages larger than the chunk size are split into GELF chunks when sent
+// over UDP. TCP connections are established on first use and reestablished
+// after write errors.
+func newGELF(cfg GELFConfig, enab zapcore.LevelEnabler) (zapcore.Core, error) {
+	if cfg.Address == "" {
+		return nil, errors.New("gelf address must be set")
+	}
+
+	network := cfg.Network
+	switch network {
+	case "":
+		network = "udp"
+	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6":
+	default:
+		return nil, fmt.Errorf("unsupported gelf network '%v'", network)
+	}
+
+	chunkSize := cfg.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = defaultGELFChunkSize
+	}
+	if chunkSize <= gelfChunkHeaderSize {
+		return nil, fmt.Errorf("gelf chunk_size must be larger than %d", gelfChunkHeaderSize)
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+
+	// The encoder only encodes the fields, the entry itself is mapped to the
+	// GELF fields in Write.
+	encCfg := JSONEncoderConfig()
+	encCfg.TimeKey = ""
+	encCfg.LevelKey = ""
+	encCfg.NameKey = ""
+	encCfg.CallerKey = ""
+	encCfg.MessageKey = ""
+	encCfg.StacktraceKey = ""
+
+	return &gelfCore{
+		LevelEnabler: enab,
+		encoder:      zapcore.NewJSONEncoder(encCfg),
+		host:         host,
+		writer:       &gelfWriter{network: network, address: cfg.Address, chunkSize: chunkSize},
+	}, nil
+}
+
+func (c *gelfCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := c.Clone()
+	for _, field := range fields {
+		field.AddTo(clone.encoder)
+	}
+	return clone
+}
+
+func (c *gelfCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *gelfCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buffer, err := c.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return fmt.Errorf("failed to encode entry: %w", err)
+	}
+	defer buffer.Free()
+
+	var extra map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(buffer.Bytes()))
+	dec.UseNumber()
+	if err := dec.Decode(&extra); err != nil {
+		return fmt.Errorf("failed to decode fields: %w", err)
+	}
+
+	msg := map[string]interface{}{
+		"version":       gelfVersion,
+		"host":          c.host,
+		"short_message": entry.Message,
+		"timestamp":     float64(entry.Time.UnixNano()) / float64(time.Second),
+		"level":         syslogSeverity(entry.Level),
+	}
+	if entry.Stack != "" {
+		msg["full_message"] = entry.Message + "\n" + entry.Stack
+	}
+	if entry.LoggerName != "" {
+		msg["_log.logger"] = entry.LoggerName
+	}
+	if entry.Caller.Defined {
+		msg["_log.origin.file.name"] = entry.Caller.TrimmedPath()
+		msg["_log.origin.file.line"] = entry.Caller.Line
+	}
+	addGELFFields(msg, "", extra)
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode gelf message: %w", err)
+	}
+	return c.writer.write(data)
+}
+
+func (c *gelfCore) Sync() error {
+	return nil
+}
+
+func (c *gelfCore) Clone() *gelfCore {
+	clone := *c
+	clone.encoder = c.encoder.Clone()
+	return &clone
+}
+
+// addGELFFields adds fields as GELF additional fields to msg. GELF only
+// supports strings and numbers, so nested objects are flattened, booleans
+// are converted to strings and arrays are encoded as JSON strings. The
+// reserved `_id` field is sent as `_id_`.
+func addGELFFields(msg map[string]interface{}, prefix string, fields map[string]interface{}) {
+	for k, v := range fields {
+		key := prefix + k
+		switch val := v.(type) {
+		case nil:
+		case map[string]interface{}:
+			addGELFFields(msg, key+".", val)
+		case bool:
+			msg[gelfFieldName(key)] = fmt.Sprint(val)
+		case []interface{}:
+			data, _ := json.Marshal(val)
+			msg[gelfFieldName(key)] = string(data)
+		default:
+			msg[gelfFieldName(key)] = val
+		}
+	}
+}
+
+func gelfFieldName(key string) string {
+	if key == "id" {
+		return "_id_"
+	}
+	return "_" + key
+}
+
+// syslogSeverity maps log levels to syslog severities, like the syslog
+// output. It is used by the GELF and journald outputs.
+func syslogSeverity(l zapcore.Level) int {
+	switch l {
+	case zapcore.InfoLevel:
+		return 6
+	case zapcore.WarnLevel:
+		return 4
+	case zapcore.ErrorLevel:
+		return 3
+	case zapcore.DPanicLevel, zapcore.PanicLevel, zapcore.FatalLevel:
+		return 2
+	default:
+		return 7
+	}
+}
+
+func (w *gelfWriter) write(data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		conn, err := net.DialTimeout(w.network, w.address, gelfDialTimeout)
+		if err != nil {
+			return fmt.Errorf("failed to connect to gelf input: %w", err)
+		}
+		w.conn = conn
+	}
+
+	var err error
+	if _, ok := w.conn.(*net.UDPConn); ok {
+		err = w.writeUDP(data)
+	} else {
+		// Messages sent over TCP are delimited by a null byte.
+		_, err = w.conn.Write(append(data, 0))
+	}
+	if err != nil {
+		w.conn.Close()
+		w.conn = nil
+		return fmt.Errorf("failed to send gelf message: %w", err)
+	}
+	return nil
+}
+
+func (w *gelfWriter) writeUDP(data []byte) error {
+	if len(data) <= w.chunkSize {
+		_, err := w.conn.Write(data)
+		return err
+	}
+
+	payloadSize := w.chunkSize - gelfChunkHeaderSize
+	count := (len(data) + payloadSize - 1) / payloadSize
+	if count > gelfMaxChunks {
+		return fmt.Errorf("message of %d bytes needs more than %d chunks", len(data), gelfMaxChunks)
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+
+	chunk := make([]byte, 0, w.chunkSize)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * payloadSize
+		if end > len(data) {
+			end = len(data)
+		}
+
+		chunk = append(chunk[:0], gelfChunkMagic...)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, data[i*payloadSize:end]...)
+		if _, err := w.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
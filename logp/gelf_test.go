@@ -0,0 +1,164 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// readGELFUDP reads datagrams from conn until a complete, possibly chunked,
+// GELF message is received.
+func readGELFUDP(t *testing.T, conn net.PacketConn) (map[string]interface{}, int) {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	var chunks [][]byte
+	buf := make([]byte, 65536)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		data := append([]byte{}, buf[:n]...)
+
+		if !bytes.HasPrefix(data, gelfChunkMagic) {
+			return decodeGELF(t, data), 0
+		}
+
+		seq, count := int(data[10]), int(data[11])
+		if chunks == nil {
+			chunks = make([][]byte, count)
+		}
+		chunks[seq] = data[gelfChunkHeaderSize:]
+
+		complete := true
+		for _, c := range chunks {
+			complete = complete && c != nil
+		}
+		if complete {
+			return decodeGELF(t, bytes.Join(chunks, nil)), count
+		}
+	}
+}
+
+func decodeGELF(t *testing.T, data []byte) map[string]interface{} {
+	t.Helper()
+	var msg map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &msg), string(data))
+	return msg
+}
+
+func TestGELFOutputUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	core, err := newGELF(GELFConfig{Address: conn.LocalAddr().String(), ChunkSize: 100}, DebugLevel.ZapLevel())
+	require.NoError(t, err)
+	logger := newLogger(zap.New(core), "gelf").With("service.name", "beat1")
+
+	logger.Warnw("short message", "count", 3, "event", map[string]interface{}{"dataset": "test"}, "ok", true, "id", "abc")
+	msg, chunks := readGELFUDP(t, conn)
+	assert.Greater(t, chunks, 1, "message is expected to be chunked")
+	assert.Equal(t, "1.1", msg["version"])
+	assert.Equal(t, "short message", msg["short_message"])
+	assert.Equal(t, float64(4), msg["level"])
+	assert.NotEmpty(t, msg["host"])
+	assert.InDelta(t, float64(time.Now().Unix()), msg["timestamp"], 60)
+	assert.Equal(t, "gelf", msg["_log.logger"])
+	assert.Equal(t, "beat1", msg["_service.name"])
+	assert.Equal(t, float64(3), msg["_count"])
+	assert.Equal(t, "test", msg["_event.dataset"])
+	assert.Equal(t, "true", msg["_ok"])
+	assert.Equal(t, "abc", msg["_id_"])
+	assert.NotContains(t, msg, "_id")
+
+	core, err = newGELF(GELFConfig{Address: conn.LocalAddr().String()}, DebugLevel.ZapLevel())
+	require.NoError(t, err)
+	newLogger(zap.New(core), "").Error("unchunked")
+	msg, chunks = readGELFUDP(t, conn)
+	assert.Zero(t, chunks)
+	assert.Equal(t, "unchunked", msg["short_message"])
+	assert.Equal(t, float64(3), msg["level"])
+
+	core, err = newGELF(GELFConfig{Address: conn.LocalAddr().String(), ChunkSize: 20}, DebugLevel.ZapLevel())
+	require.NoError(t, err)
+	entry := zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), Message: strings.Repeat("x", 2000)}
+	assert.Error(t, core.Write(entry, nil))
+}
+
+func TestGELFOutputTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		var msgs []string
+		for len(msgs) < 2 {
+			msg, err := r.ReadString(0)
+			if err != nil {
+				break
+			}
+			msgs = append(msgs, strings.TrimSuffix(msg, "\x00"))
+		}
+		received <- msgs
+	}()
+
+	core, err := newGELF(GELFConfig{Network: "tcp", Address: ln.Addr().String()}, DebugLevel.ZapLevel())
+	require.NoError(t, err)
+	logger := newLogger(zap.New(core), "tcp")
+	logger.Debug("first")
+	logger.Info("second " + strings.Repeat("x", 4000))
+
+	select {
+	case msgs := <-received:
+		require.Len(t, msgs, 2)
+		first := decodeGELF(t, []byte(msgs[0]))
+		assert.Equal(t, "first", first["short_message"])
+		assert.Equal(t, float64(7), first["level"])
+		second := decodeGELF(t, []byte(msgs[1]))
+		assert.Equal(t, float64(6), second["level"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("no messages received")
+	}
+}
+
+func TestGELFConfigErrors(t *testing.T) {
+	_, err := newGELF(GELFConfig{}, InfoLevel.ZapLevel())
+	assert.Error(t, err)
+	_, err = newGELF(GELFConfig{Address: "localhost:12201", Network: "unix"}, InfoLevel.ZapLevel())
+	assert.Error(t, err)
+	_, err = newGELF(GELFConfig{Address: "localhost:12201", ChunkSize: 12}, InfoLevel.ZapLevel())
+	assert.Error(t, err)
+}
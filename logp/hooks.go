@@ -0,0 +1,177 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// hookQueueSize is the number of records buffered for the hooks. Records are
+// dropped when the hooks can not keep up.
+const hookQueueSize = 1024
+
+// Entry is a log record passed to the hooks registered with RegisterHook.
+// Fields holds a copy of the fields of the record and of its logger.
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Logger  string
+	Message string
+	Caller  string // file:line of the log call, empty if unknown.
+	Fields  map[string]interface{}
+}
+
+// HooksConfig configures the records passed to the hooks.
+type HooksConfig struct {
+	// Level is the minimum level of the records passed to the hooks. The
+	// debug selectors apply to the hooks too.
+	Level Level `config:"level" yaml:"level"`
+}
+
+// RegisterHook registers fn to be called for every record logged at the
+// level configured in the `hooks.level` setting or above, info by default.
+// The hooks are called in registration order from a single background
+// goroutine, they should not block. Records are dropped, and counted in the
+// BufferDrops stats of the `hooks` output, when the hooks can not keep up. A
+// panicking hook is recovered and counted in the WriteErrors stats.
+func RegisterHook(fn func(entry Entry)) {
+	defaultHooks.add(fn)
+}
+
+var defaultHooks = newHookRunner(hookQueueSize)
+
+type hookRunner struct {
+	mu    sync.RWMutex
+	fns   []func(Entry)
+	queue chan Entry
+	start sync.Once
+	stats *outputCounters
+}
+
+func newHookRunner(size int) *hookRunner {
+	return &hookRunner{queue: make(chan Entry, size), stats: statsFor("hooks")}
+}
+
+func (h *hookRunner) add(fn func(Entry)) {
+	h.mu.Lock()
+	h.fns = append(h.fns, fn)
+	h.mu.Unlock()
+
+	h.start.Do(func() { go h.run() })
+}
+
+func (h *hookRunner) enabled() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.fns) > 0
+}
+
+func (h *hookRunner) send(e Entry) {
+	select {
+	case h.queue <- e:
+	default:
+		atomic.AddUint64(&h.stats.bufferDrops, 1)
+	}
+}
+
+func (h *hookRunner) run() {
+	for e := range h.queue {
+		h.mu.RLock()
+		fns := h.fns
+		h.mu.RUnlock()
+
+		for _, fn := range fns {
+			h.call(fn, e)
+		}
+	}
+}
+
+// call calls fn, recovering from panics. The panic is not logged, the
+// record would be passed to the panicking hook again.
+func (h *hookRunner) call(fn func(Entry), e Entry) {
+	defer func() {
+		if r := recover(); r != nil {
+			atomic.AddUint64(&h.stats.writeErrors, 1)
+		}
+	}()
+	fn(e)
+}
+
+// hookCore passes the records to the hooks.
+type hookCore struct {
+	hooks  *hookRunner
+	level  zapcore.LevelEnabler
+	fields []zapcore.Field
+}
+
+func newHookCore(hooks *hookRunner, level Level) zapcore.Core {
+	return &hookCore{hooks: hooks, level: level.ZapLevel()}
+}
+
+func (c *hookCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level) && c.hooks.enabled()
+}
+
+func (c *hookCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	clone.fields = append(append(clone.fields, c.fields...), fields...)
+	return &clone
+}
+
+func (c *hookCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write checks the level again, the core is called directly if it is
+// wrapped, e.g. by the debug selectors.
+func (c *hookCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if !c.Enabled(entry.Level) {
+		return nil
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	e := Entry{
+		Time:    entry.Time,
+		Level:   FromZapLevel(entry.Level),
+		Logger:  entry.LoggerName,
+		Message: entry.Message,
+		Fields:  enc.Fields,
+	}
+	if entry.Caller.Defined {
+		e.Caller = entry.Caller.TrimmedPath()
+	}
+	c.hooks.send(e)
+	return nil
+}
+
+func (c *hookCore) Sync() error { return nil }
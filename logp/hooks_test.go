@@ -0,0 +1,124 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func receiveEntry(t *testing.T, entries <-chan Entry) Entry {
+	t.Helper()
+	select {
+	case e := <-entries:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("no record received by the hook")
+		return Entry{}
+	}
+}
+
+func TestHookCore(t *testing.T) {
+	hooks := newHookRunner(10)
+	before := hooks.stats.snapshot()
+	entries := make(chan Entry, 10)
+	hooks.add(func(Entry) { panic("broken hook") })
+	hooks.add(func(e Entry) {
+		select {
+		case entries <- e:
+		default:
+		}
+	})
+	logger := newLogger(zap.New(newHookCore(hooks, InfoLevel), zap.AddCaller()), "hooked")
+
+	logger.Debug("not passed to the hooks")
+	logger.With("component", "test").Infow("passed to the hooks", "count", 2)
+
+	e := receiveEntry(t, entries)
+	assert.Equal(t, InfoLevel, e.Level)
+	assert.Equal(t, "hooked", e.Logger)
+	assert.Equal(t, "passed to the hooks", e.Message)
+	assert.Contains(t, e.Caller, "hooks_test.go")
+	assert.Equal(t, map[string]interface{}{"component": "test", "count": int64(2)}, e.Fields)
+
+	logger.Error("after the panic")
+	assert.Equal(t, ErrorLevel, receiveEntry(t, entries).Level)
+	assert.Equal(t, uint64(2), hooks.stats.snapshot().WriteErrors-before.WriteErrors)
+}
+
+func TestRegisterHook(t *testing.T) {
+	cfg := DefaultConfig(DefaultEnvironment)
+	cfg.Hooks.Level = WarnLevel
+	ToDiscardOutput()(&cfg)
+	require.NoError(t, Configure(cfg))
+	defer func() {
+		require.NoError(t, DevelopmentSetup(ToDiscardOutput()))
+	}()
+
+	entries := make(chan Entry, 10)
+	RegisterHook(func(e Entry) {
+		if e.Logger != "register-hook" {
+			return
+		}
+		select {
+		case entries <- e:
+		default:
+		}
+	})
+
+	logger := NewLogger("register-hook")
+	logger.Info("below the hooks level")
+	logger.Warnw("shipped", "key", "value")
+
+	e := receiveEntry(t, entries)
+	assert.Equal(t, "shipped", e.Message)
+	assert.Equal(t, WarnLevel, e.Level)
+	assert.Equal(t, "value", e.Fields["key"])
+}
+
+func TestHookCoreWithDebugSelectors(t *testing.T) {
+	hooks := newHookRunner(10)
+	entries := make(chan Entry, 10)
+	hooks.add(func(e Entry) { entries <- e })
+	core := selectiveWrapper(newHookCore(hooks, ErrorLevel), map[string]struct{}{"*": {}})
+	logger := newLogger(zap.New(core), "hooked")
+
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Error("error")
+	e := receiveEntry(t, entries)
+	assert.Equal(t, "error", e.Message)
+	assert.Equal(t, ErrorLevel, e.Level)
+	assert.Empty(t, entries)
+
+	t.Run("without hooks", func(t *testing.T) {
+		hooks := newHookRunner(1)
+		before := hooks.stats.snapshot()
+		core := selectiveWrapper(newHookCore(hooks, DebugLevel), map[string]struct{}{"*": {}})
+		logger := newLogger(zap.New(core), "hooked")
+		for i := 0; i < 3; i++ {
+			logger.Info("not queued")
+		}
+		assert.Empty(t, hooks.queue)
+		assert.Equal(t, before.BufferDrops, hooks.stats.snapshot().BufferDrops)
+	})
+}
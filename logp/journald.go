@@ -0,0 +1,207 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultJournaldSocket = "/run/systemd/journal/socket"
+
+// journaldMaxFieldName is the maximum length of journal field names.
+const journaldMaxFieldName = 64
+
+// journaldCore sends log entries to the systemd journal with its native
+// protocol. Fields are sent as journal fields, named after their flattened
+// key in upper case, e.g. SERVICE_NAME for `service.name`.
+type journaldCore struct {
+	zapcore.LevelEnabler
+	encoder    zapcore.Encoder
+	identifier string
+	writer     journaldWriter
+}
+
+type journaldWriter interface {
+	write(data []byte) error
+}
+
+// newJournald returns a new Core sending logs to the journal socket set in
+// cfg. The identifier defaults to the Beat name, or the name of the
+// executable.
+func newJournald(cfg JournaldConfig, beat string, enab zapcore.LevelEnabler) (zapcore.Core, error) {
+	writer, err := newJournaldWriter(cfg.Socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the systemd journal: %w", err)
+	}
+
+	identifier := cfg.Identifier
+	if identifier == "" {
+		identifier = beat
+	}
+	if identifier == "" {
+		identifier = filepath.Base(os.Args[0])
+	}
+
+	// The encoder only encodes the fields, the entry itself is mapped to the
+	// journal fields in Write.
+	encCfg := JSONEncoderConfig()
+	encCfg.TimeKey = ""
+	encCfg.LevelKey = ""
+	encCfg.NameKey = ""
+	encCfg.CallerKey = ""
+	encCfg.MessageKey = ""
+	encCfg.StacktraceKey = ""
+
+	return &journaldCore{
+		LevelEnabler: enab,
+		encoder:      zapcore.NewJSONEncoder(encCfg),
+		identifier:   identifier,
+		writer:       writer,
+	}, nil
+}
+
+func (c *journaldCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := c.Clone()
+	for _, field := range fields {
+		field.AddTo(clone.encoder)
+	}
+	return clone
+}
+
+func (c *journaldCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *journaldCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buffer, err := c.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return fmt.Errorf("failed to encode entry: %w", err)
+	}
+	defer buffer.Free()
+
+	var extra map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(buffer.Bytes()))
+	dec.UseNumber()
+	if err := dec.Decode(&extra); err != nil {
+		return fmt.Errorf("failed to decode fields: %w", err)
+	}
+
+	var msg bytes.Buffer
+	appendJournaldField(&msg, "MESSAGE", entry.Message)
+	appendJournaldField(&msg, "PRIORITY", strconv.Itoa(syslogSeverity(entry.Level)))
+	appendJournaldField(&msg, "SYSLOG_IDENTIFIER", c.identifier)
+	if entry.LoggerName != "" {
+		appendJournaldField(&msg, "LOG_LOGGER", entry.LoggerName)
+	}
+	if entry.Caller.Defined {
+		appendJournaldField(&msg, "CODE_FILE", entry.Caller.TrimmedPath())
+		appendJournaldField(&msg, "CODE_LINE", strconv.Itoa(entry.Caller.Line))
+		if entry.Caller.Function != "" {
+			appendJournaldField(&msg, "CODE_FUNC", entry.Caller.Function)
+		}
+	}
+	if entry.Stack != "" {
+		appendJournaldField(&msg, "ERROR_STACK_TRACE", entry.Stack)
+	}
+	addJournaldFields(&msg, "", extra)
+
+	return c.writer.write(msg.Bytes())
+}
+
+func (c *journaldCore) Sync() error {
+	return nil
+}
+
+func (c *journaldCore) Clone() *journaldCore {
+	clone := *c
+	clone.encoder = c.encoder.Clone()
+	return &clone
+}
+
+// addJournaldFields adds fields as journal fields to msg. Nested objects are
+// flattened, arrays are encoded as JSON.
+func addJournaldFields(msg *bytes.Buffer, prefix string, fields map[string]interface{}) {
+	for k, v := range fields {
+		key := prefix + k
+		switch val := v.(type) {
+		case nil:
+		case map[string]interface{}:
+			addJournaldFields(msg, key+".", val)
+		case []interface{}:
+			data, _ := json.Marshal(val)
+			appendJournaldField(msg, journaldFieldName(key), string(data))
+		default:
+			appendJournaldField(msg, journaldFieldName(key), fmt.Sprint(val))
+		}
+	}
+}
+
+// journaldFieldName converts key to a valid journal field name: upper case
+// letters, digits and underscores, not starting with an underscore or a digit.
+func journaldFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+
+	name = strings.TrimLeft(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "F_" + name
+	}
+	if len(name) > journaldMaxFieldName {
+		name = name[:journaldMaxFieldName]
+	}
+	return name
+}
+
+// appendJournaldField appends a field in the journal native format. Values
+// containing newlines are prefixed with their length.
+func appendJournaldField(msg *bytes.Buffer, name, value string) {
+	msg.WriteString(name)
+	if !strings.ContainsRune(value, '\n') {
+		msg.WriteByte('=')
+		msg.WriteString(value)
+		msg.WriteByte('\n')
+		return
+	}
+
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+	msg.WriteByte('\n')
+	msg.Write(size[:])
+	msg.WriteString(value)
+	msg.WriteByte('\n')
+}
@@ -0,0 +1,106 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package logp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// journaldSocketWriter sends the entries as datagrams to the journal socket.
+// Entries too large for a datagram are passed in a sealed memfd, like
+// sd_journal_send does.
+type journaldSocketWriter struct {
+	conn *net.UnixConn
+}
+
+func newJournaldWriter(socket string) (journaldWriter, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &journaldSocketWriter{conn: conn}, nil
+}
+
+// journaldAvailable returns true if socket exists and is a unix socket.
+func journaldAvailable(socket string) bool {
+	fi, err := os.Stat(socket)
+	return err == nil && fi.Mode()&os.ModeSocket != 0
+}
+
+func (w *journaldSocketWriter) write(data []byte) error {
+	_, err := w.conn.Write(data)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, syscall.EMSGSIZE) && !errors.Is(err, syscall.ENOBUFS) {
+		return fmt.Errorf("failed to send journal entry: %w", err)
+	}
+
+	if err := w.writeMemfd(data); err != nil {
+		return fmt.Errorf("failed to send journal entry of %d bytes: %w", len(data), err)
+	}
+	return nil
+}
+
+func (w *journaldSocketWriter) writeMemfd(data []byte) error {
+	fd, err := unix.MemfdCreate("logp-journal", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return err
+	}
+	f := os.NewFile(uintptr(fd), "logp-journal")
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	// journald only accepts sealed memfds.
+	seals := unix.F_SEAL_SHRINK | unix.F_SEAL_GROW | unix.F_SEAL_WRITE | unix.F_SEAL_SEAL
+	if _, err := unix.FcntlInt(f.Fd(), unix.F_ADD_SEALS, seals); err != nil {
+		return err
+	}
+
+	return w.sendRights(unix.UnixRights(int(f.Fd())))
+}
+
+// sendRights sends a datagram without payload holding the file descriptors
+// in rights.
+func (w *journaldSocketWriter) sendRights(rights []byte) error {
+	raw, err := w.conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var sendErr error
+	err = raw.Write(func(fd uintptr) bool {
+		sendErr = unix.Sendmsg(int(fd), nil, rights, nil, 0)
+		return !errors.Is(sendErr, unix.EAGAIN)
+	})
+	if err != nil {
+		return err
+	}
+	return sendErr
+}
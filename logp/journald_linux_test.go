@@ -0,0 +1,170 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package logp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeJournal listens on a unix datagram socket like journald.
+func fakeJournal(t *testing.T) (string, *net.UnixConn) {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "journal.socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return socket, conn
+}
+
+// readJournalEntry reads one entry and decodes its fields. Entries passed in
+// a memfd are read from the file descriptor.
+func readJournalEntry(t *testing.T, conn *net.UnixConn) map[string]string {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	buf := make([]byte, 64*1024)
+	oob := make([]byte, 1024)
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	require.NoError(t, err)
+	data := buf[:n]
+
+	if oobn > 0 {
+		msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+		require.NoError(t, err)
+		require.Len(t, msgs, 1)
+		fds, err := syscall.ParseUnixRights(&msgs[0])
+		require.NoError(t, err)
+		require.Len(t, fds, 1)
+
+		f := os.NewFile(uintptr(fds[0]), "memfd")
+		defer f.Close()
+		var content bytes.Buffer
+		_, err = f.Seek(0, 0)
+		require.NoError(t, err)
+		_, err = content.ReadFrom(f)
+		require.NoError(t, err)
+		data = content.Bytes()
+	}
+	return decodeJournalFields(t, data)
+}
+
+func decodeJournalFields(t *testing.T, data []byte) map[string]string {
+	fields := map[string]string{}
+	for len(data) > 0 {
+		i := bytes.IndexAny(data, "=\n")
+		require.True(t, i > 0, "invalid entry: %q", data)
+		name := string(data[:i])
+		if data[i] == '=' {
+			end := bytes.IndexByte(data, '\n')
+			fields[name] = string(data[i+1 : end])
+			data = data[end+1:]
+			continue
+		}
+
+		size := binary.LittleEndian.Uint64(data[i+1 : i+9])
+		fields[name] = string(data[i+9 : i+9+int(size)])
+		data = data[i+9+int(size)+1:]
+	}
+	return fields
+}
+
+func TestJournaldOutput(t *testing.T) {
+	socket, conn := fakeJournal(t)
+
+	cfg := DefaultConfig(DefaultEnvironment)
+	cfg.Beat = "testbeat"
+	cfg.ToJournald = true
+	cfg.Journald.Socket = socket
+	require.NoError(t, Configure(cfg))
+	defer func() {
+		require.NoError(t, DevelopmentSetup(ToDiscardOutput()))
+	}()
+
+	NewLogger("tester").Warnw("disk almost full", "disk.free", 10, "tags", []string{"a", "b"})
+	fields := readJournalEntry(t, conn)
+	assert.Equal(t, "disk almost full", fields["MESSAGE"])
+	assert.Equal(t, "4", fields["PRIORITY"])
+	assert.Equal(t, "testbeat", fields["SYSLOG_IDENTIFIER"])
+	assert.Equal(t, "testbeat", fields["SERVICE_NAME"])
+	assert.Equal(t, "tester", fields["LOG_LOGGER"])
+	assert.Equal(t, "10", fields["DISK_FREE"])
+	assert.Equal(t, `["a","b"]`, fields["TAGS"])
+	assert.Contains(t, fields["CODE_FILE"], "journald_linux_test.go")
+	assert.NotEmpty(t, fields["CODE_LINE"])
+
+	NewLogger("tester").Error("panic: boom\ngoroutine 1 [running]")
+	fields = readJournalEntry(t, conn)
+	assert.Equal(t, "panic: boom\ngoroutine 1 [running]", fields["MESSAGE"])
+	assert.Equal(t, "3", fields["PRIORITY"])
+}
+
+func TestJournaldLargeEntry(t *testing.T) {
+	socket, conn := fakeJournal(t)
+	require.NoError(t, conn.SetReadBuffer(1024))
+
+	core, err := newJournald(JournaldConfig{Socket: socket}, "testbeat", DebugLevel.ZapLevel())
+	require.NoError(t, err)
+
+	// Larger than the maximum datagram size, sent through a memfd.
+	message := strings.Repeat("x", 1024*1024)
+	logger := newLogger(zap.New(core), "")
+	logger.Info(message)
+
+	fields := readJournalEntry(t, conn)
+	assert.Equal(t, message, fields["MESSAGE"])
+}
+
+func TestJournaldFallback(t *testing.T) {
+	cfg := DefaultConfig(DefaultEnvironment)
+	cfg.ToJournald = true
+	cfg.Journald.Socket = filepath.Join(t.TempDir(), "missing.socket")
+	assert.False(t, journaldAvailable(cfg.Journald.Socket))
+
+	core, err := createLogOutput(cfg, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, core)
+}
+
+func TestJournaldFieldName(t *testing.T) {
+	tests := map[string]string{
+		"service.name":          "SERVICE_NAME",
+		"http.request.id":       "HTTP_REQUEST_ID",
+		"_private":              "PRIVATE",
+		"1st":                   "F_1ST",
+		"ünicode-key":           "NICODE_KEY",
+		strings.Repeat("a", 70): strings.Repeat("A", 64),
+	}
+	for key, want := range tests {
+		assert.Equal(t, want, journaldFieldName(key), key)
+	}
+}
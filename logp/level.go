@@ -29,7 +29,8 @@ type Level int8
 
 // Logging levels.
 const (
-	DebugLevel Level = iota - 1
+	TraceLevel Level = iota - 2 // Trace is only enabled when explicitly configured, not by debug.
+	DebugLevel
 	InfoLevel
 	WarnLevel
 	ErrorLevel
@@ -37,6 +38,7 @@ const (
 )
 
 var levelStrings = map[Level]string{
+	TraceLevel:    "trace",
 	DebugLevel:    "debug",
 	InfoLevel:     "info",
 	WarnLevel:     "warning",
@@ -44,7 +46,12 @@ var levelStrings = map[Level]string{
 	CriticalLevel: "critical",
 }
 
+// zapTraceLevel is the zap level used for TraceLevel, zap has no level below
+// debug.
+const zapTraceLevel = zapcore.DebugLevel - 1
+
 var zapLevels = map[Level]zapcore.Level{
+	TraceLevel:    zapTraceLevel,
 	DebugLevel:    zapcore.DebugLevel,
 	InfoLevel:     zapcore.InfoLevel,
 	WarnLevel:     zapcore.WarnLevel,
@@ -108,3 +115,41 @@ func (l Level) ZapLevel() zapcore.Level {
 	}
 	return zapcore.InfoLevel
 }
+
+// lowercaseLevelEncoder is zapcore.LowercaseLevelEncoder naming the trace
+// level.
+// FromZapLevel returns the Level of a zap level. The zap levels above error
+// are returned as CriticalLevel.
+func FromZapLevel(level zapcore.Level) Level {
+	switch {
+	case level < zapcore.DebugLevel:
+		return TraceLevel
+	case level == zapcore.DebugLevel:
+		return DebugLevel
+	case level == zapcore.InfoLevel:
+		return InfoLevel
+	case level == zapcore.WarnLevel:
+		return WarnLevel
+	case level == zapcore.ErrorLevel:
+		return ErrorLevel
+	default:
+		return CriticalLevel
+	}
+}
+
+func lowercaseLevelEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	if l == zapTraceLevel {
+		enc.AppendString("trace")
+		return
+	}
+	zapcore.LowercaseLevelEncoder(l, enc)
+}
+
+// capitalLevelEncoder is zapcore.CapitalLevelEncoder naming the trace level.
+func capitalLevelEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	if l == zapTraceLevel {
+		enc.AppendString("TRACE")
+		return
+	}
+	zapcore.CapitalLevelEncoder(l, enc)
+}
@@ -0,0 +1,124 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/elastic/go-ucfg/yaml"
+)
+
+func TestLevelOrdering(t *testing.T) {
+	assert.Less(t, int(TraceLevel), int(DebugLevel))
+	assert.Less(t, TraceLevel.ZapLevel(), DebugLevel.ZapLevel())
+
+	assert.False(t, DebugLevel.Enabled(TraceLevel))
+	assert.True(t, TraceLevel.Enabled(TraceLevel))
+	assert.True(t, TraceLevel.Enabled(DebugLevel))
+
+	// The existing levels keep their values.
+	assert.Equal(t, Level(-1), DebugLevel)
+	assert.Equal(t, Level(0), InfoLevel)
+}
+
+func TestLevelUnpack(t *testing.T) {
+	for _, name := range []string{"trace", "TRACE", "debug", "info"} {
+		var level Level
+		require.NoError(t, level.Unpack(name), name)
+	}
+
+	var level Level
+	require.NoError(t, level.Unpack("trace"))
+	assert.Equal(t, TraceLevel, level)
+	assert.Equal(t, "trace", level.String())
+
+	cfg, err := yaml.NewConfig([]byte("level: debug"))
+	require.NoError(t, err)
+	var out struct {
+		Level Level `config:"level"`
+	}
+	require.NoError(t, cfg.Unpack(&out))
+	assert.Equal(t, DebugLevel, out.Level)
+}
+
+func TestTraceLevelLogging(t *testing.T) {
+	require.NoError(t, DevelopmentSetup(ToObserverOutput()))
+	logger := NewLogger("tester")
+
+	assert.False(t, logger.IsTrace())
+	logger.Tracef("not logged %d", 1)
+	logger.Debug("debug")
+	logs := ObserverLogs().TakeAll()
+	if assert.Len(t, logs, 1) {
+		assert.Equal(t, zapcore.DebugLevel, logs[0].Level)
+	}
+
+	require.NoError(t, DevelopmentSetup(ToObserverOutput(), WithLevel(TraceLevel)))
+	logger = NewLogger("tester")
+
+	assert.True(t, logger.IsTrace())
+	logger.Trace("trace")
+	logger.Tracef("trace %d", 1)
+	logger.Tracew("trace", "x", 1)
+	logs = ObserverLogs().TakeAll()
+	if assert.Len(t, logs, 3) {
+		assert.Equal(t, TraceLevel.ZapLevel(), logs[0].Level)
+		assert.Equal(t, "trace", logs[0].Message)
+		assert.Equal(t, "trace 1", logs[1].Message)
+		assert.Equal(t, "tester", logs[1].LoggerName)
+		assert.Contains(t, logs[2].ContextMap(), "x")
+	}
+}
+
+func TestTraceLevelSelectors(t *testing.T) {
+	require.NoError(t, DevelopmentSetup(ToObserverOutput(), WithLevel(TraceLevel), WithSelectors("good")))
+
+	NewLogger("good").Trace("is logged")
+	NewLogger("bad").Trace("not logged")
+	logs := ObserverLogs().TakeAll()
+	if assert.Len(t, logs, 1) {
+		assert.Equal(t, "is logged", logs[0].Message)
+	}
+}
+
+func TestTraceLevelEncoding(t *testing.T) {
+	enc := zapcore.NewMapObjectEncoder()
+	require.NoError(t, enc.AddArray("levels", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		lowercaseLevelEncoder(TraceLevel.ZapLevel(), arr)
+		capitalLevelEncoder(TraceLevel.ZapLevel(), arr)
+		lowercaseLevelEncoder(zapcore.DebugLevel, arr)
+		return nil
+	})))
+	assert.Equal(t, []interface{}{"trace", "TRACE", "debug"}, enc.Fields["levels"])
+}
+
+func TestFromZapLevel(t *testing.T) {
+	for level := range levelStrings {
+		if level == CriticalLevel {
+			continue
+		}
+		assert.Equal(t, level, FromZapLevel(level.ZapLevel()))
+	}
+	assert.Equal(t, CriticalLevel, FromZapLevel(zapcore.DPanicLevel))
+	assert.Equal(t, CriticalLevel, FromZapLevel(zapcore.PanicLevel))
+	assert.Equal(t, CriticalLevel, FromZapLevel(zapcore.FatalLevel))
+}
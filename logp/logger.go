@@ -72,6 +72,16 @@ func (l *Logger) Named(name string) *Logger {
 
 // Sprint
 
+// Trace uses fmt.Sprint to construct and log a message at trace level.
+func (l *Logger) Trace(args ...interface{}) {
+	if !l.logger.Core().Enabled(zapTraceLevel) {
+		return
+	}
+	if ce := l.logger.Check(zapTraceLevel, fmt.Sprint(args...)); ce != nil {
+		ce.Write()
+	}
+}
+
 // Debug uses fmt.Sprint to construct and log a message.
 func (l *Logger) Debug(args ...interface{}) {
 	l.sugar.Debug(args...)
@@ -108,6 +118,11 @@ func (l *Logger) DPanic(args ...interface{}) {
 	l.sugar.DPanic(args...)
 }
 
+// IsTrace checks to see if the given logger is Trace enabled.
+func (l *Logger) IsTrace() bool {
+	return l.logger.Check(zapTraceLevel, "") != nil
+}
+
 // IsDebug checks to see if the given logger is Debug enabled.
 func (l *Logger) IsDebug() bool {
 	return l.logger.Check(zapcore.DebugLevel, "") != nil
@@ -115,6 +130,16 @@ func (l *Logger) IsDebug() bool {
 
 // Sprintf
 
+// Tracef uses fmt.Sprintf to construct and log a message at trace level.
+func (l *Logger) Tracef(format string, args ...interface{}) {
+	if !l.logger.Core().Enabled(zapTraceLevel) {
+		return
+	}
+	if ce := l.logger.Check(zapTraceLevel, fmt.Sprintf(format, args...)); ce != nil {
+		ce.Write()
+	}
+}
+
 // Debugf uses fmt.Sprintf to construct and log a message.
 func (l *Logger) Debugf(format string, args ...interface{}) {
 	l.sugar.Debugf(format, args...)
@@ -153,6 +178,19 @@ func (l *Logger) DPanicf(format string, args ...interface{}) {
 
 // With context (reflection based)
 
+// Tracew logs a message at trace level with some additional context. The
+// additional context is added in the form of key-value pairs. The optimal way
+// to write the value to the log message will be inferred by the value's type.
+// To explicitly specify a type you can pass a Field such as logp.Stringer.
+func (l *Logger) Tracew(msg string, keysAndValues ...interface{}) {
+	if !l.logger.Core().Enabled(zapTraceLevel) {
+		return
+	}
+	if ce := l.sugar.With(keysAndValues...).Desugar().Check(zapTraceLevel, msg); ce != nil {
+		ce.Write()
+	}
+}
+
 // Debugw logs a message with some additional context. The additional context
 // is added in the form of key-value pairs. The optimal way to write the value
 // to the log message will be inferred by the value's type. To explicitly
@@ -0,0 +1,154 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package logpmetrics provides loggers that count the warnings and errors
+// they log in a monitoring registry.
+package logpmetrics
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+// EventCodeKey is the field identifying the event a log record is counted
+// as.
+const EventCodeKey = "event.code"
+
+// LoggerWithMetrics returns a logger named after selector that, in addition
+// to logging, counts the records logged at warning level and above in r. See
+// WithMetrics.
+func LoggerWithMetrics(r *monitoring.Registry, selector string, options ...logp.LogOption) *logp.Logger {
+	return logp.NewLogger(selector, append(options, WithMetrics(r))...)
+}
+
+// WithMetrics returns an option counting the records logged at warning level
+// and above by their `event.code` field. The field can be passed with the
+// log call or added to the logger with With. Records without an event code
+// are not counted. A record is counted even if its level is not enabled in
+// the logging configuration.
+//
+// The counter of an event code is an Int in r, named after the code with
+// dots replaced by underscores so all counters stay at the root of r. If r is
+// nil the default registry is used.
+func WithMetrics(r *monitoring.Registry) logp.LogOption {
+	if r == nil {
+		r = monitoring.Default
+	}
+	counters := &eventCounters{registry: r, counters: map[string]*monitoring.Int{}}
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &countingCore{Core: core, counters: counters}
+	})
+}
+
+type eventCounters struct {
+	registry *monitoring.Registry
+
+	mu       sync.Mutex
+	counters map[string]*monitoring.Int
+}
+
+func (c *eventCounters) inc(code string) {
+	name := strings.ReplaceAll(code, ".", "_")
+
+	c.mu.Lock()
+	counter, ok := c.counters[name]
+	if !ok {
+		if existing, isInt := c.registry.Get(name).(*monitoring.Int); isInt {
+			counter = existing
+		} else {
+			counter = monitoring.NewInt(c.registry, name)
+		}
+		c.counters[name] = counter
+	}
+	c.mu.Unlock()
+
+	counter.Inc()
+}
+
+// countingCore wraps the logger core, adding a counter core to the records
+// logged at warning level and above.
+type countingCore struct {
+	zapcore.Core
+	counters *eventCounters
+
+	// code is the event code added to the logger with With.
+	code string
+}
+
+func (c *countingCore) With(fields []zapcore.Field) zapcore.Core {
+	code := c.code
+	if fieldCode, ok := eventCode(fields); ok {
+		code = fieldCode
+	}
+	return &countingCore{Core: c.Core.With(fields), counters: c.counters, code: code}
+}
+
+func (c *countingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	checked = c.Core.Check(entry, checked)
+	if entry.Level >= zapcore.WarnLevel {
+		checked = checked.AddCore(entry, counterCore{c})
+	}
+	return checked
+}
+
+// counterCore counts the records written to it instead of logging them.
+type counterCore struct {
+	*countingCore
+}
+
+func (c counterCore) Enabled(zapcore.Level) bool        { return true }
+func (c counterCore) With([]zapcore.Field) zapcore.Core { return c }
+func (c counterCore) Sync() error                       { return nil }
+func (c counterCore) Check(_ zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checked
+}
+
+func (c counterCore) Write(_ zapcore.Entry, fields []zapcore.Field) error {
+	code := c.code
+	if fieldCode, ok := eventCode(fields); ok {
+		code = fieldCode
+	}
+	if code != "" {
+		c.counters.inc(code)
+	}
+	return nil
+}
+
+func eventCode(fields []zapcore.Field) (string, bool) {
+	for i := len(fields) - 1; i >= 0; i-- {
+		f := fields[i]
+		if f.Key != EventCodeKey {
+			continue
+		}
+		switch f.Type {
+		case zapcore.StringType:
+			return f.String, true
+		case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type:
+			return strconv.FormatInt(f.Integer, 10), true
+		case zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type:
+			return strconv.FormatUint(uint64(f.Integer), 10), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,83 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logpmetrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+func TestWithMetrics(t *testing.T) {
+	base, logs := logptest.NewLogger(t, "test")
+	r := monitoring.NewRegistry()
+	log := base.WithOptions(WithMetrics(r))
+
+	log.Errorw("failed to publish", EventCodeKey, "output.publish_failed")
+	log.Warnw("retrying publish", EventCodeKey, "output.publish_failed")
+	log.Errorw("invalid config", EventCodeKey, "config_invalid")
+	log.Infow("not counted", EventCodeKey, "output.publish_failed")
+	log.Error("no event code")
+
+	entries := logs.FilterMessage("failed to publish").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, logp.ErrorLevel, entries[0].Level)
+	assert.Equal(t, "output.publish_failed", entries[0].Fields[EventCodeKey])
+	assert.Equal(t, 5, logs.Len())
+
+	assert.Equal(t, int64(2), counter(t, r, "output_publish_failed"))
+	assert.Equal(t, int64(1), counter(t, r, "config_invalid"))
+	assert.Len(t, monitoring.CollectFlatSnapshot(r, monitoring.Full, false).Ints, 2)
+}
+
+func TestWithMetricsCodeFromContext(t *testing.T) {
+	base, logs := logptest.NewLogger(t, "test")
+	r := monitoring.NewRegistry()
+	log := base.WithOptions(WithMetrics(r)).With(EventCodeKey, "connection_lost")
+
+	log.Warn("connection lost")
+	log.Errorw("connection lost again", EventCodeKey, "connection_reset")
+	log.Named("child").Error("connection lost in child")
+
+	assert.Equal(t, 3, logs.Len())
+	assert.Equal(t, int64(2), counter(t, r, "connection_lost"))
+	assert.Equal(t, int64(1), counter(t, r, "connection_reset"))
+}
+
+func TestWithMetricsSharedRegistry(t *testing.T) {
+	r := monitoring.NewRegistry()
+	first, _ := logptest.NewLogger(t, "first")
+	second, _ := logptest.NewLogger(t, "second")
+
+	first.WithOptions(WithMetrics(r)).Errorw("failure", EventCodeKey, 42)
+	second.WithOptions(WithMetrics(r)).Errorw("failure", EventCodeKey, 42)
+
+	assert.Equal(t, int64(2), counter(t, r, "42"))
+}
+
+func counter(t *testing.T, r *monitoring.Registry, name string) int64 {
+	t.Helper()
+	v, ok := r.Get(name).(*monitoring.Int)
+	require.True(t, ok, "counter %v not found", name)
+	return v.Get()
+}
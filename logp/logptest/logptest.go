@@ -0,0 +1,130 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package logptest provides helpers to assert on the messages logged through
+// a logp.Logger in tests.
+package logptest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+// Entry is a log record captured by Logs.
+type Entry struct {
+	Time    time.Time
+	Level   logp.Level
+	Logger  string
+	Message string
+	Fields  map[string]interface{}
+}
+
+// String formats the entry the way it is dumped on test failures.
+func (e Entry) String() string {
+	return fmt.Sprintf("%s\t%s\t%s\t%s\t%v", e.Time.Format(time.RFC3339Nano), e.Level, e.Logger, e.Message, e.Fields)
+}
+
+// Logs captures the log records of the loggers created by NewLogger.
+type Logs struct {
+	observed *observer.ObservedLogs
+}
+
+// NewLogger returns a logger named after selector that records everything
+// logged at trace level and above. The global logp configuration is not
+// modified. If the test fails, the captured records are written to the test
+// log during cleanup.
+func NewLogger(t testing.TB, selector string) (*logp.Logger, *Logs) {
+	t.Helper()
+
+	core, observed := observer.New(logp.TraceLevel.ZapLevel())
+	logger := logp.NewLogger(selector, zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		return core
+	}))
+
+	logs := &Logs{observed: observed}
+	t.Cleanup(func() {
+		if !t.Failed() {
+			return
+		}
+		for _, entry := range logs.All() {
+			t.Log(entry)
+		}
+	})
+	return logger, logs
+}
+
+// All returns all the captured records in the order they were logged.
+func (l *Logs) All() []Entry {
+	return convertEntries(l.observed.All())
+}
+
+// TakeAll returns all the captured records and resets the captured logs.
+func (l *Logs) TakeAll() []Entry {
+	return convertEntries(l.observed.TakeAll())
+}
+
+// Len returns the number of captured records.
+func (l *Logs) Len() int {
+	return l.observed.Len()
+}
+
+// FilterMessage returns the records whose message is exactly msg.
+func (l *Logs) FilterMessage(msg string) *Logs {
+	return &Logs{l.observed.FilterMessage(msg)}
+}
+
+// FilterMessageSnippet returns the records whose message contains snippet.
+func (l *Logs) FilterMessageSnippet(snippet string) *Logs {
+	return &Logs{l.observed.FilterMessageSnippet(snippet)}
+}
+
+// FilterFieldKey returns the records having a structured field named key.
+func (l *Logs) FilterFieldKey(key string) *Logs {
+	return &Logs{l.observed.FilterFieldKey(key)}
+}
+
+// FilterLevel returns the records logged at exactly the given level.
+func (l *Logs) FilterLevel(level logp.Level) *Logs {
+	return &Logs{l.observed.FilterLevelExact(level.ZapLevel())}
+}
+
+// Contains reports whether a record at the given level contains snippet in
+// its message.
+func (l *Logs) Contains(level logp.Level, snippet string) bool {
+	return l.FilterLevel(level).FilterMessageSnippet(snippet).Len() > 0
+}
+
+func convertEntries(entries []observer.LoggedEntry) []Entry {
+	converted := make([]Entry, len(entries))
+	for i, e := range entries {
+		converted[i] = Entry{
+			Time:    e.Time,
+			Level:   logp.FromZapLevel(e.Level),
+			Logger:  e.LoggerName,
+			Message: e.Message,
+			Fields:  e.ContextMap(),
+		}
+	}
+	return converted
+}
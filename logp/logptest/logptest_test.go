@@ -0,0 +1,62 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logptest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+func TestLogsMessage(t *testing.T) {
+	logger, logs := NewLogger(t, "test")
+
+	logger.Debug("starting")
+	logger.Warnf("disk usage at %d%%", 95)
+	logger.Info("done")
+
+	assert.True(t, logs.Contains(logp.WarnLevel, "disk usage"))
+	assert.False(t, logs.Contains(logp.ErrorLevel, "disk usage"))
+
+	warnings := logs.FilterLevel(logp.WarnLevel).All()
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "disk usage at 95%", warnings[0].Message)
+	assert.Equal(t, "test", warnings[0].Logger)
+
+	assert.Equal(t, 1, logs.FilterMessage("done").Len())
+	assert.Len(t, logs.TakeAll(), 3)
+	assert.Equal(t, 0, logs.Len())
+}
+
+func TestLogsFields(t *testing.T) {
+	logger, logs := NewLogger(t, "test")
+
+	logger.With("client.id", "abc").Errorw("request failed", "error", errors.New("boom"), "attempt", 3)
+	logger.Info("unrelated")
+
+	entries := logs.FilterFieldKey("client.id").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, logp.ErrorLevel, entries[0].Level)
+	assert.Equal(t, "abc", entries[0].Fields["client.id"])
+	assert.Equal(t, "boom", entries[0].Fields["error"])
+	assert.EqualValues(t, 3, entries[0].Fields["attempt"])
+}
@@ -0,0 +1,114 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"os"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// metadataFields returns the static fields selected by cfg.Metadata, and the
+// keys of the fields which are explicitly enabled.
+func metadataFields(cfg Config) (fields []zapcore.Field, enabled []string) {
+	if cfg.Metadata.Host {
+		if hostname, err := os.Hostname(); err == nil {
+			fields = append(fields, zap.String("host.name", hostname))
+			enabled = append(enabled, "host.name")
+		}
+	}
+	if cfg.Metadata.Process {
+		fields = append(fields, zap.Int("process.pid", os.Getpid()))
+		enabled = append(enabled, "process.pid")
+	}
+	if cfg.Beat != "" && (cfg.Metadata.Service == nil || *cfg.Metadata.Service) {
+		fields = append(fields, zap.String("service.name", cfg.Beat))
+		if cfg.Metadata.Service != nil {
+			enabled = append(enabled, "service.name")
+		}
+	}
+	// The build info is explicitly set by the application.
+	for _, f := range buildInfoFields() {
+		fields = append(fields, f)
+		enabled = append(enabled, f.Key)
+	}
+	return fields, enabled
+}
+
+// withMetadata adds the metadata fields to core. Unless overriding them is
+// allowed, fields added later with the same key as an explicitly enabled
+// metadata field, or of the build info, are dropped. The fields added by
+// default, like service.name, do not prevent the loggers from adding their
+// own.
+func withMetadata(core zapcore.Core, cfg Config) zapcore.Core {
+	fields, enabled := metadataFields(cfg)
+	if len(fields) == 0 {
+		return core
+	}
+
+	core = core.With(fields)
+	if cfg.Metadata.AllowOverride || len(enabled) == 0 {
+		return core
+	}
+
+	keys := make(map[string]struct{}, len(enabled))
+	for _, key := range enabled {
+		keys[key] = struct{}{}
+	}
+	return &metadataCore{Core: core, keys: keys}
+}
+
+// metadataCore drops the fields using the key of a metadata field.
+type metadataCore struct {
+	zapcore.Core
+	keys map[string]struct{}
+}
+
+func (c *metadataCore) With(fields []zapcore.Field) zapcore.Core {
+	return &metadataCore{Core: c.Core.With(c.filter(fields)), keys: c.keys}
+}
+
+func (c *metadataCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *metadataCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, c.filter(fields))
+}
+
+func (c *metadataCore) filter(fields []zapcore.Field) []zapcore.Field {
+	for i, f := range fields {
+		if _, found := c.keys[f.Key]; !found {
+			continue
+		}
+
+		// Copy the fields to not modify the slice of the caller.
+		filtered := append(make([]zapcore.Field, 0, len(fields)-1), fields[:i]...)
+		for _, f := range fields[i+1:] {
+			if _, found := c.keys[f.Key]; !found {
+				filtered = append(filtered, f)
+			}
+		}
+		return filtered
+	}
+	return fields
+}
@@ -0,0 +1,151 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestMetadataFields(t *testing.T) {
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+	disabled := false
+
+	tests := map[string]struct {
+		metadata MetadataConfig
+		want     map[string]interface{}
+	}{
+		"default": {
+			want: map[string]interface{}{"service.name": "beat1"},
+		},
+		"all": {
+			metadata: MetadataConfig{Host: true, Process: true},
+			want: map[string]interface{}{
+				"host.name":    hostname,
+				"process.pid":  int64(os.Getpid()),
+				"service.name": "beat1",
+			},
+		},
+		"without service": {
+			metadata: MetadataConfig{Process: true, Service: &disabled},
+			want:     map[string]interface{}{"process.pid": int64(os.Getpid())},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := DefaultConfig(DefaultEnvironment)
+			cfg.Beat = "beat1"
+			cfg.Metadata = test.metadata
+			ToObserverOutput()(&cfg)
+			require.NoError(t, Configure(cfg))
+
+			NewLogger("tester").Info("message")
+			logs := ObserverLogs().TakeAll()
+			require.Len(t, logs, 1)
+			assert.Equal(t, test.want, logs[0].ContextMap())
+		})
+	}
+}
+
+func TestMetadataFieldsOverride(t *testing.T) {
+	cfg := DefaultConfig(DefaultEnvironment)
+	cfg.Beat = "beat1"
+	cfg.Level = DebugLevel
+	cfg.Selectors = []string{"tester"}
+	cfg.Metadata = MetadataConfig{Process: true}
+	ToObserverOutput()(&cfg)
+	require.NoError(t, Configure(cfg))
+
+	serviceNames := func(entry observer.LoggedEntry) []string {
+		var names []string
+		for _, f := range entry.Context {
+			if f.Key == "service.name" {
+				names = append(names, f.String)
+			}
+		}
+		return names
+	}
+
+	logger := NewLogger("tester").With("process.pid", 1, "x", 1)
+	logger.Infow("message", "process.pid", 2, "y", 2)
+	logs := ObserverLogs().TakeAll()
+	require.Len(t, logs, 1)
+	assert.Equal(t, map[string]interface{}{
+		"process.pid":  int64(os.Getpid()),
+		"service.name": "beat1",
+		"x":            int64(1),
+		"y":            int64(2),
+	}, logs[0].ContextMap())
+
+	// Only the explicitly enabled fields are protected.
+	NewLogger("tester").Infow("message", "service.name", "other")
+	logs = ObserverLogs().TakeAll()
+	require.Len(t, logs, 1)
+	assert.Equal(t, []string{"beat1", "other"}, serviceNames(logs[0]))
+
+	// Debug selectors are still applied.
+	NewLogger("other").Debug("message")
+	NewLogger("tester").Debug("message")
+	logs = ObserverLogs().TakeAll()
+	require.Len(t, logs, 1)
+	assert.Equal(t, "tester", logs[0].LoggerName)
+
+	// And the disabled selectors.
+	require.NoError(t, SetDisabledSelectors("tester"))
+	NewLogger("tester").Info("message")
+	assert.Empty(t, ObserverLogs().TakeAll())
+	require.NoError(t, SetDisabledSelectors())
+
+	service := true
+	cfg.Metadata.Service = &service
+	require.NoError(t, Configure(cfg))
+	NewLogger("tester").Infow("message", "service.name", "other")
+	logs = ObserverLogs().TakeAll()
+	require.Len(t, logs, 1)
+	assert.Equal(t, []string{"beat1"}, serviceNames(logs[0]))
+
+	cfg.Metadata.AllowOverride = true
+	require.NoError(t, Configure(cfg))
+	NewLogger("tester").Infow("message", "service.name", "other")
+	logs = ObserverLogs().TakeAll()
+	require.Len(t, logs, 1)
+	assert.Equal(t, []string{"beat1", "other"}, serviceNames(logs[0]))
+}
+
+func TestMetadataCoreWriteErrors(t *testing.T) {
+	errWrite := errors.New("disk full")
+	cfg := Config{Metadata: MetadataConfig{Process: true}}
+	core := withMetadata(zapcore.NewCore(
+		zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "message"}),
+		failingWriter{errWrite},
+		zapcore.InfoLevel,
+	), cfg)
+
+	entry := zapcore.Entry{Level: zapcore.InfoLevel, Message: "message"}
+	checked := core.Check(entry, nil)
+	require.NotNil(t, checked)
+	assert.ErrorIs(t, core.Write(entry, nil), errWrite)
+}
@@ -35,6 +35,14 @@ func WithSelectors(selectors ...string) Option {
 	}
 }
 
+// WithDisabledSelectors specifies the selectors whose logs are dropped
+// regardless of their level.
+func WithDisabledSelectors(selectors ...string) Option {
+	return func(cfg *Config) {
+		cfg.DisabledSelectors = append(cfg.DisabledSelectors, selectors...)
+	}
+}
+
 // ToObserverOutput specifies that the output should be collected in memory so
 // that they can be read by an observer by calling ObserverLogs().
 func ToObserverOutput() Option {
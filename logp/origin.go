@@ -0,0 +1,74 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// ECS keys of the structured caller fields.
+const (
+	originFunctionKey = "log.origin.function"
+	originFileNameKey = "log.origin.file.name"
+	originFileLineKey = "log.origin.file.line"
+)
+
+// withOriginFields wraps enc so that the caller of an entry is encoded as the
+// separate log.origin.* fields.
+func withOriginFields(enc zapcore.Encoder, cfg Config) zapcore.Encoder {
+	if !cfg.OriginFields {
+		return enc
+	}
+	return originEncoder{enc}
+}
+
+// originEncoder replaces the caller of the entries with the log.origin.*
+// fields. The caller is the one resolved by zap, so it honors AddCallerSkip.
+type originEncoder struct {
+	zapcore.Encoder
+}
+
+func (e originEncoder) Clone() zapcore.Encoder {
+	return originEncoder{e.Encoder.Clone()}
+}
+
+func (e originEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	if !ent.Caller.Defined {
+		return e.Encoder.EncodeEntry(ent, fields)
+	}
+
+	file := ent.Caller.TrimmedPath()
+	if idx := strings.LastIndexByte(file, ':'); idx >= 0 {
+		file = file[:idx]
+	}
+	origin := make([]zapcore.Field, 0, len(fields)+3)
+	if ent.Caller.Function != "" {
+		origin = append(origin, zap.String(originFunctionKey, ent.Caller.Function))
+	}
+	origin = append(origin,
+		zap.String(originFileNameKey, file),
+		zap.Int(originFileLineKey, ent.Caller.Line),
+	)
+
+	ent.Caller = zapcore.EntryCaller{}
+	return e.Encoder.EncodeEntry(ent, append(origin, fields...))
+}
@@ -0,0 +1,69 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestOriginFields(t *testing.T) {
+	for name, originFields := range map[string]bool{"disabled": false, "enabled": true} {
+		originFields := originFields
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc := buildEncoder(Config{OriginFields: originFields})
+			root := zap.New(newCore(enc, zapcore.AddSync(&buf), zapcore.DebugLevel), zap.AddCaller())
+
+			// The logger adds a caller skip, the caller must still be this function.
+			_, _, line, _ := runtime.Caller(0)
+			newLogger(root, "").Info("message")
+
+			var entry map[string]interface{}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+
+			if !originFields {
+				assert.NotContains(t, entry, originFunctionKey)
+				assert.Equal(t, map[string]interface{}{
+					"file.name": "logp/origin_test.go",
+					"file.line": float64(line + 1),
+				}, entry["log.origin"])
+				return
+			}
+
+			assert.NotContains(t, entry, "log.origin")
+			assert.Equal(t, "github.com/elastic/elastic-agent-libs/logp.TestOriginFields.func1", entry[originFunctionKey])
+			assert.Equal(t, "logp/origin_test.go", entry[originFileNameKey])
+			assert.Equal(t, float64(line+1), entry[originFileLineKey])
+		})
+	}
+}
+
+func TestOriginFieldsWithoutCaller(t *testing.T) {
+	enc := withOriginFields(zapcore.NewJSONEncoder(JSONEncoderConfig()), Config{OriginFields: true})
+	buf, err := enc.EncodeEntry(zapcore.Entry{Message: "message"}, nil)
+	require.NoError(t, err)
+	assert.NotContains(t, buf.String(), "log.origin")
+}
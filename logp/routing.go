@@ -0,0 +1,172 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/hashicorp/go-multierror"
+	"go.uber.org/zap/zapcore"
+)
+
+// RouteConfig sends the logs of the matching selectors to a file of their
+// own instead of the main output. The file is configured like the files
+// output, under a different name.
+type RouteConfig struct {
+	// Selectors are the loggers whose logs are routed, they can be globs as
+	// supported by path.Match, e.g. `metrics.*`.
+	Selectors []string `config:"selectors" yaml:"selectors"`
+	// Name is the name of the log file.
+	Name string `config:"name" yaml:"name"`
+}
+
+// Validate checks the route settings.
+func (r *RouteConfig) Validate() error {
+	if len(r.Selectors) == 0 {
+		return errors.New("a log route requires at least one selector")
+	}
+	if r.Name == "" {
+		return errors.New("a log route requires a file name")
+	}
+	for _, sel := range r.Selectors {
+		if _, err := path.Match(sel, ""); err != nil {
+			return fmt.Errorf("invalid log route selector '%v': %w", sel, err)
+		}
+	}
+	return nil
+}
+
+func (r *RouteConfig) matches(name string) bool {
+	for _, sel := range r.Selectors {
+		if ok, _ := path.Match(sel, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// withRoutes creates the file outputs of the routes of cfg and returns a core
+// writing the logs to them, the logs which don't match any route are
+// written to def. The writers of the routes are added to closers.
+func withRoutes(def zapcore.Core, cfg Config, closers *outputClosers) (zapcore.Core, error) {
+	if len(cfg.Routes) == 0 {
+		return def, nil
+	}
+
+	routes := make([]route, len(cfg.Routes))
+	for i, rc := range cfg.Routes {
+		files := cfg.Files
+		files.Name = rc.Name
+		// Only the main output receives stderr.
+		files.RedirectStderr = false
+
+		output := "file:" + rc.Name
+		out, err := makeFileWriter(output, files, rc.Name, closers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create log route %v: %w", rc.Name, err)
+		}
+		routes[i] = route{config: rc, core: newOutputCore(output, buildEncoder(cfg), out, cfg.Level.ZapLevel())}
+	}
+	return &routingCore{def: def, routes: routes}, nil
+}
+
+type route struct {
+	config RouteConfig
+	core   zapcore.Core
+}
+
+// routingCore writes the entries to the cores of all the routes matching the
+// logger name, or to the default core if there is none.
+type routingCore struct {
+	def    zapcore.Core
+	routes []route
+}
+
+func (c *routingCore) Enabled(level zapcore.Level) bool {
+	if c.def.Enabled(level) {
+		return true
+	}
+	for _, r := range c.routes {
+		if r.core.Enabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *routingCore) With(fields []zapcore.Field) zapcore.Core {
+	routes := make([]route, len(c.routes))
+	for i, r := range c.routes {
+		routes[i] = route{config: r.config, core: r.core.With(fields)}
+	}
+	return &routingCore{def: c.def.With(fields), routes: routes}
+}
+
+func (c *routingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	matched := false
+	for _, r := range c.routes {
+		if r.config.matches(entry.LoggerName) {
+			matched = true
+			checked = r.core.Check(entry, checked)
+		}
+	}
+	if !matched {
+		checked = c.def.Check(entry, checked)
+	}
+	return checked
+}
+
+// Write is only called if the core is wrapped, e.g. by the debug selectors,
+// otherwise Check adds the cores of the matching routes.
+func (c *routingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	var errs error
+	matched := false
+	for _, r := range c.routes {
+		if !r.config.matches(entry.LoggerName) {
+			continue
+		}
+		matched = true
+		if !r.core.Enabled(entry.Level) {
+			continue
+		}
+		if err := r.core.Write(entry, fields); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	if !matched && c.def.Enabled(entry.Level) {
+		if err := c.def.Write(entry, fields); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	return errs
+}
+
+func (c *routingCore) Sync() error {
+	var errs error
+	if err := c.def.Sync(); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	for _, r := range c.routes {
+		if err := r.core.Sync(); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,109 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoutes(t *testing.T) {
+	cfg := DefaultConfig(DefaultEnvironment)
+	ToObserverOutput()(&cfg)
+	cfg.Files.Path = t.TempDir()
+	cfg.Routes = []RouteConfig{
+		{Selectors: []string{"metrics"}, Name: "metrics"},
+		{Selectors: []string{"metrics", "monitoring.*"}, Name: "monitoring"},
+	}
+	require.NoError(t, Configure(cfg))
+	defer func() {
+		require.NoError(t, DevelopmentSetup(ToObserverOutput()))
+	}()
+
+	NewLogger("metrics").Info("metrics message")
+	NewLogger("monitoring").Named("http").Info("monitoring message")
+	NewLogger("publisher").Info("publisher message")
+	NewLogger("metrics").Debug("filtered by level")
+	require.NoError(t, Sync())
+
+	// The default output only gets the unmatched selectors.
+	logs := ObserverLogs().TakeAll()
+	require.Len(t, logs, 1)
+	assert.Equal(t, "publisher message", logs[0].Message)
+
+	assert.Equal(t, []string{"metrics message"}, readRouteMessages(t, cfg.Files.Path, "metrics"))
+	assert.Equal(t, []string{"metrics message", "monitoring message"}, readRouteMessages(t, cfg.Files.Path, "monitoring"))
+}
+
+func TestRoutesWithDebugSelectors(t *testing.T) {
+	cfg := DefaultConfig(DefaultEnvironment)
+	ToObserverOutput()(&cfg)
+	cfg.Level = DebugLevel
+	cfg.Selectors = []string{"metrics", "publisher"}
+	cfg.Files.Path = t.TempDir()
+	cfg.Routes = []RouteConfig{{Selectors: []string{"metrics"}, Name: "metrics"}}
+	require.NoError(t, Configure(cfg))
+	defer func() {
+		require.NoError(t, DevelopmentSetup(ToObserverOutput()))
+	}()
+
+	NewLogger("metrics").Debug("metrics debug")
+	NewLogger("metrics").Info("metrics info")
+	NewLogger("publisher").Debug("publisher debug")
+	NewLogger("other").Debug("filtered by selectors")
+	NewLogger("other").Info("other info")
+	require.NoError(t, Sync())
+
+	var messages []string
+	for _, entry := range ObserverLogs().TakeAll() {
+		messages = append(messages, entry.Message)
+	}
+	assert.Equal(t, []string{"publisher debug", "other info"}, messages)
+	assert.Equal(t, []string{"metrics debug", "metrics info"}, readRouteMessages(t, cfg.Files.Path, "metrics"))
+}
+
+func TestRouteConfigValidate(t *testing.T) {
+	assert.NoError(t, (&RouteConfig{Selectors: []string{"metrics.*"}, Name: "metrics"}).Validate())
+	assert.Error(t, (&RouteConfig{Name: "metrics"}).Validate())
+	assert.Error(t, (&RouteConfig{Selectors: []string{"metrics"}}).Validate())
+	assert.Error(t, (&RouteConfig{Selectors: []string{"metrics["}, Name: "metrics"}).Validate())
+}
+
+func readRouteMessages(t *testing.T, dir, name string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, name+"-*.ndjson"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	content, err := ioutil.ReadFile(files[0])
+	require.NoError(t, err)
+
+	var messages []string
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		messages = append(messages, record["message"].(string))
+	}
+	return messages
+}
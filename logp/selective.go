@@ -59,16 +59,7 @@ func (c *selectiveCore) With(fields []zapcore.Field) zapcore.Core {
 //
 // Callers must use Check before calling Write.
 func (c *selectiveCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
-	if c.Enabled(ent.Level) {
-		if ent.Level == zapcore.DebugLevel {
-			if c.allSelectors {
-				return ce.AddCore(ent, c)
-			} else if _, enabled := c.selectors[ent.LoggerName]; enabled {
-				return ce.AddCore(ent, c)
-			}
-			return ce
-		}
-
+	if c.accepts(ent) {
 		return ce.AddCore(ent, c)
 	}
 	return ce
@@ -77,12 +68,28 @@ func (c *selectiveCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapc
 // Write serializes the Entry and any Fields supplied at the log site and
 // writes them to their destination.
 //
-// If called, Write should always log the Entry and Fields; it should not
-// replicate the logic of Check.
+// The selectors are checked again, the core is called directly if it is
+// wrapped, e.g. by the metadata fields.
 func (c *selectiveCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if !c.accepts(ent) {
+		return nil
+	}
 	return c.core.Write(ent, fields)
 }
 
+// accepts returns true if the level of the entry is enabled and, for debug
+// entries, if the logger is selected.
+func (c *selectiveCore) accepts(ent zapcore.Entry) bool {
+	if !c.Enabled(ent.Level) {
+		return false
+	}
+	if ent.Level > zapcore.DebugLevel || c.allSelectors {
+		return true
+	}
+	_, enabled := c.selectors[ent.LoggerName]
+	return enabled
+}
+
 // Sync flushes buffered logs (if any).
 func (c *selectiveCore) Sync() error {
 	return c.core.Sync()
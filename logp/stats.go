@@ -0,0 +1,127 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// OutputStats counts the log records lost by an output.
+type OutputStats struct {
+	WriteErrors  uint64 // Records the output failed to write, e.g. disk full or broken pipe.
+	SampledDrops uint64 // Records dropped by a sampler, see CountSampledDrops.
+	BufferDrops  uint64 // Records dropped because the buffer of the asynchronous file output was full.
+}
+
+// LogStats reports the health of the log outputs.
+type LogStats struct {
+	// Outputs holds the stats by output: stderr, file, syslog, eventlog,
+	// gelf and file:<name> for the file of a route.
+	Outputs map[string]OutputStats
+}
+
+// Stats returns the counters of the records lost by the log outputs since
+// the process started. The counters are kept when the logger is
+// reconfigured. They can be reported with monitoring.NewFunc.
+func Stats() LogStats {
+	outputStats.mu.Lock()
+	defer outputStats.mu.Unlock()
+
+	stats := LogStats{Outputs: make(map[string]OutputStats, len(outputStats.outputs))}
+	for name, c := range outputStats.outputs {
+		stats.Outputs[name] = c.snapshot()
+	}
+	return stats
+}
+
+// CountSampledDrops returns a sampler option counting the records dropped
+// by the sampler in the SampledDrops stats of output. Use it with
+// zapcore.NewSamplerWithOptions.
+func CountSampledDrops(output string) zapcore.SamplerOption {
+	counters := statsFor(output)
+	return zapcore.SamplerHook(func(_ zapcore.Entry, dec zapcore.SamplingDecision) {
+		if dec&zapcore.LogDropped != 0 {
+			atomic.AddUint64(&counters.sampledDrops, 1)
+		}
+	})
+}
+
+var outputStats = struct {
+	mu      sync.Mutex
+	outputs map[string]*outputCounters
+}{outputs: map[string]*outputCounters{}}
+
+type outputCounters struct {
+	writeErrors  uint64
+	sampledDrops uint64
+	bufferDrops  uint64
+}
+
+// statsFor returns the counters of the output name.
+func statsFor(name string) *outputCounters {
+	outputStats.mu.Lock()
+	defer outputStats.mu.Unlock()
+
+	c, ok := outputStats.outputs[name]
+	if !ok {
+		c = &outputCounters{}
+		outputStats.outputs[name] = c
+	}
+	return c
+}
+
+func (c *outputCounters) snapshot() OutputStats {
+	return OutputStats{
+		WriteErrors:  atomic.LoadUint64(&c.writeErrors),
+		SampledDrops: atomic.LoadUint64(&c.sampledDrops),
+		BufferDrops:  atomic.LoadUint64(&c.bufferDrops),
+	}
+}
+
+// statsCore counts the write errors of an output core.
+type statsCore struct {
+	zapcore.Core
+	counters *outputCounters
+}
+
+// withStats counts the write errors of core in the stats of output name.
+func withStats(name string, core zapcore.Core) zapcore.Core {
+	return &statsCore{Core: core, counters: statsFor(name)}
+}
+
+func (c *statsCore) With(fields []zapcore.Field) zapcore.Core {
+	return &statsCore{Core: c.Core.With(fields), counters: c.counters}
+}
+
+func (c *statsCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *statsCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	err := c.Core.Write(entry, fields)
+	if err != nil {
+		atomic.AddUint64(&c.counters.writeErrors, 1)
+	}
+	return err
+}
@@ -0,0 +1,81 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logp
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type failingWriter struct{ err error }
+
+func (w failingWriter) Write([]byte) (int, error) { return 0, w.err }
+func (w failingWriter) Sync() error               { return nil }
+
+func TestStatsWriteErrors(t *testing.T) {
+	errBrokenPipe := errors.New("broken pipe")
+	core := newOutputCore("test-write-errors", zapcore.NewJSONEncoder(JSONEncoderConfig()), failingWriter{errBrokenPipe}, zapcore.DebugLevel)
+	logger := zap.New(core).With(zap.String("key", "value"))
+	before := Stats().Outputs["test-write-errors"]
+
+	logger.Info("first")
+	logger.Debug("second")
+	after := Stats().Outputs["test-write-errors"]
+	assert.Equal(t, uint64(2), after.WriteErrors-before.WriteErrors)
+	assert.Equal(t, before.SampledDrops, after.SampledDrops)
+	assert.Equal(t, before.BufferDrops, after.BufferDrops)
+}
+
+func TestStatsSampledDrops(t *testing.T) {
+	core := zapcore.NewSamplerWithOptions(
+		newOutputCore("test-sampled", zapcore.NewJSONEncoder(JSONEncoderConfig()), zapcore.AddSync(failingWriter{}), zapcore.DebugLevel),
+		time.Minute, 1, 0,
+		CountSampledDrops("test-sampled"),
+	)
+	logger := zap.New(core)
+	before := Stats().Outputs["test-sampled"]
+
+	for i := 0; i < 5; i++ {
+		logger.Info("repeated")
+	}
+	after := Stats().Outputs["test-sampled"]
+	assert.Equal(t, uint64(4), after.SampledDrops-before.SampledDrops)
+	assert.Equal(t, before.WriteErrors, after.WriteErrors)
+}
+
+func TestStatsBufferDrops(t *testing.T) {
+	out := &blockingWriter{release: make(chan struct{})}
+	defer close(out.release)
+	w := newAsyncWriter(out, 1, time.Second, statsFor("test-buffer"))
+	before := Stats().Outputs["test-buffer"]
+
+	for i := 0; i < 5; i++ {
+		_, err := w.Write([]byte("x"))
+		require.NoError(t, err)
+	}
+	stats := Stats().Outputs["test-buffer"]
+	assert.Equal(t, w.Dropped(), stats.BufferDrops-before.BufferDrops)
+	assert.NotZero(t, w.Dropped())
+	assert.Equal(t, before.WriteErrors, stats.WriteErrors)
+}
@@ -75,7 +75,7 @@ func (c *syslogCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
 
 	msg := buffer.String()
 	switch entry.Level {
-	case zapcore.DebugLevel:
+	case zapTraceLevel, zapcore.DebugLevel:
 		return c.writer.Debug(msg)
 	case zapcore.InfoLevel:
 		return c.writer.Info(msg)
@@ -0,0 +1,113 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package mapstr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+// FromJSONUsingNumber decodes a JSON object into M, keeping numbers as
+// json.Number instead of converting them to float64. A float64 only holds
+// integers up to 2^53 exactly, so decoding with json.Unmarshal silently
+// corrupts larger values such as 64-bit ids. A json.Number keeps the textual
+// form of the number, GetInt64 and GetFloat64 convert it on access. Nested
+// objects are decoded as map[string]interface{}, as with json.Unmarshal.
+func FromJSONUsingNumber(data []byte) (M, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var m M
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("invalid character after top-level value")
+	}
+	return m, nil
+}
+
+// GetInt64 returns the value stored at key as an int64. json.Number values
+// are parsed exactly, other integers are converted if they fit in an int64
+// and floats only if they have no fractional part.
+func (m M) GetInt64(key string) (int64, error) {
+	v, err := m.GetValue(key)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n := v.(type) {
+	case json.Number:
+		i, err := n.Int64()
+		if err != nil {
+			return 0, fmt.Errorf("key '%s': %w", key, err)
+		}
+		return i, nil
+	case float32, float64:
+		f := reflect.ValueOf(n).Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, fmt.Errorf("key '%s': %v cannot be represented as int64", key, f)
+		}
+		return int64(f), nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if rv.Uint() > math.MaxInt64 {
+			return 0, fmt.Errorf("key '%s': %v overflows int64", key, rv.Uint())
+		}
+		return int64(rv.Uint()), nil
+	}
+	return 0, fmt.Errorf("key '%s': expected a number but type is %T", key, v)
+}
+
+// GetFloat64 returns the value stored at key as a float64. Integers and
+// json.Number values beyond 2^53 are rounded to the nearest float64.
+func (m M) GetFloat64(key string) (float64, error) {
+	v, err := m.GetValue(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if n, ok := v.(json.Number); ok {
+		f, err := n.Float64()
+		if err != nil {
+			return 0, fmt.Errorf("key '%s': %w", key, err)
+		}
+		return f, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), nil
+	}
+	return 0, fmt.Errorf("key '%s': expected a number but type is %T", key, v)
+}
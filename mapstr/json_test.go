@@ -0,0 +1,96 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+// +build !integration
+
+package mapstr
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromJSONUsingNumber(t *testing.T) {
+	// 2^53 + 1 is the first integer a float64 cannot represent.
+	const id = int64(9007199254740993)
+
+	m, err := FromJSONUsingNumber([]byte(`{"id": 9007199254740993, "ratio": 0.5, "nested": {"count": 3}}`))
+	require.NoError(t, err)
+	assert.Equal(t, json.Number("9007199254740993"), m["id"])
+
+	i, err := m.GetInt64("id")
+	require.NoError(t, err)
+	assert.Equal(t, id, i)
+
+	i, err = m.GetInt64("nested.count")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), i)
+
+	f, err := m.GetFloat64("ratio")
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, f)
+
+	_, err = m.GetInt64("ratio")
+	assert.Error(t, err)
+
+	// Plain decoding loses the id.
+	var lossy M
+	require.NoError(t, json.Unmarshal([]byte(`{"id": 9007199254740993}`), &lossy))
+	i, err = lossy.GetInt64("id")
+	require.NoError(t, err)
+	assert.NotEqual(t, id, i)
+}
+
+func TestFromJSONUsingNumberInvalid(t *testing.T) {
+	for _, data := range []string{``, `[1, 2]`, `{"a": }`, `{"a": 1} {"b": 2}`} {
+		_, err := FromJSONUsingNumber([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestGetNumber(t *testing.T) {
+	m := M{
+		"int":    42,
+		"uint64": uint64(1) << 63,
+		"float":  2.0,
+		"string": "42",
+	}
+
+	i, err := m.GetInt64("int")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), i)
+
+	i, err = m.GetInt64("float")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), i)
+
+	_, err = m.GetInt64("uint64")
+	assert.Error(t, err)
+
+	f, err := m.GetFloat64("int")
+	require.NoError(t, err)
+	assert.Equal(t, 42.0, f)
+
+	_, err = m.GetInt64("string")
+	assert.Error(t, err)
+	_, err = m.GetFloat64("missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
@@ -41,6 +41,10 @@ const (
 var (
 	// ErrKeyNotFound indicates that the specified key was not found.
 	ErrKeyNotFound = errors.New("key not found")
+
+	// ErrKeyConflict indicates that a key can not be set without
+	// overwriting an existing value.
+	ErrKeyConflict = errors.New("key conflict")
 )
 
 // EventMetadata contains fields and tags that can be added to an event via
@@ -122,6 +126,67 @@ func deepUpdateValue(old interface{}, val M, overwrite bool) interface{} {
 	}
 }
 
+// MergeFunc recursively merges the key-value pairs from d into this map.
+// Keys only present in one of the maps are kept and maps present in both are
+// merged recursively. For any other key present in both maps, fn is called
+// with the full dotted path of the key and the two values; the value it
+// returns is stored in this map.
+func (m M) MergeFunc(d M, fn func(path string, a, b interface{}) interface{}) {
+	m.mergeFunc("", d, fn)
+}
+
+func (m M) mergeFunc(prefix string, d M, fn func(path string, a, b interface{}) interface{}) {
+	for k, v := range d {
+		old, exists := m[k]
+		if !exists {
+			m[k] = v
+			continue
+		}
+
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+
+		oldMap, oldIsMap := tryToMapStr(old)
+		newMap, newIsMap := tryToMapStr(v)
+		if oldIsMap && newIsMap && oldMap != nil {
+			oldMap.mergeFunc(path, newMap, fn)
+			m[k] = oldMap
+			continue
+		}
+
+		m[k] = fn(path, old, v)
+	}
+}
+
+// ApplyMergePatch applies patch to this map following the JSON merge patch
+// semantics of RFC 7386: keys set to nil in patch are deleted, maps are
+// patched recursively and any other value replaces the existing one. Keys
+// are not interpreted as dotted paths. Maps of patch are copied, not shared.
+func (m M) ApplyMergePatch(patch M) {
+	for k, v := range patch {
+		if v == nil {
+			delete(m, k)
+			continue
+		}
+		m[k] = mergePatchValue(m[k], v)
+	}
+}
+
+func mergePatchValue(target, patch interface{}) interface{} {
+	patchMap, ok := tryToMapStr(patch)
+	if !ok {
+		return patch
+	}
+	targetMap, ok := tryToMapStr(target)
+	if !ok || targetMap == nil {
+		targetMap = M{}
+	}
+	targetMap.ApplyMergePatch(patchMap)
+	return targetMap
+}
+
 // Delete deletes the given key from the map.
 func (m M) Delete(key string) error {
 	k, d, _, found, err := mapFind(key, m, false)
@@ -149,6 +214,79 @@ func (m M) CopyFieldsTo(to M, key string) error {
 	return err
 }
 
+// Rename moves the values found at the dotted keys of mapping to the dotted
+// keys they map to, creating the intermediate maps. Missing keys are
+// ignored. The renames are applied in the sorted order of the source keys.
+//
+// An error wrapping ErrKeyConflict is returned, and the map is left
+// unchanged, if a target key is already set or is a parent of another target.
+// The values of source keys are moved first, so a target can be a renamed
+// source key.
+func (m M) Rename(mapping map[string]string) error {
+	type move struct {
+		from, to string
+		value    interface{}
+
+		// parent and key locate the source value, to restore it.
+		parent M
+		key    string
+	}
+
+	var moves []move
+	targets := make(map[string]string, len(mapping))
+	for _, from := range sortedKeys(mapping) {
+		to := mapping[from]
+		if from == to {
+			continue
+		}
+		key, parent, v, found, err := mapFind(from, m, false)
+		if err != nil && !errors.Is(err, ErrKeyNotFound) {
+			return fmt.Errorf("cannot rename '%v': %w", from, err)
+		}
+		if !found {
+			continue
+		}
+
+		for other, otherFrom := range targets {
+			if other == to || strings.HasPrefix(other, to+".") || strings.HasPrefix(to, other+".") {
+				return fmt.Errorf("cannot rename '%v' to '%v', conflicts with the rename of '%v' to '%v': %w",
+					from, to, otherFrom, other, ErrKeyConflict)
+			}
+		}
+		targets[to] = from
+		moves = append(moves, move{from: from, to: to, value: v, parent: parent, key: key})
+	}
+
+	for _, mv := range moves {
+		delete(mv.parent, mv.key)
+	}
+	for _, mv := range moves {
+		_, _, _, found, err := mapFind(mv.to, m, false)
+		if found || (err != nil && !errors.Is(err, ErrKeyNotFound)) {
+			for _, mv := range moves {
+				mv.parent[mv.key] = mv.value
+			}
+			return fmt.Errorf("cannot rename '%v' to '%v': %w", mv.from, mv.to, ErrKeyConflict)
+		}
+	}
+
+	for _, mv := range moves {
+		if _, err := m.Put(mv.to, mv.value); err != nil {
+			return fmt.Errorf("cannot rename '%v' to '%v': %w", mv.from, mv.to, err)
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // Clone returns a copy of the M. It recursively makes copies of inner
 // maps.
 func (m M) Clone() M {
@@ -164,6 +302,96 @@ func (m M) Clone() M {
 	return result
 }
 
+// Select returns a new M holding only the values found at the given dotted
+// paths. A `*` path element matches all the keys of a map. Missing paths are
+// ignored. The maps of the result are copies, the source M is not modified.
+func (m M) Select(paths []string) M {
+	result := M{}
+	for _, path := range paths {
+		selectPath(result, m, strings.Split(path, "."))
+	}
+	return result
+}
+
+func selectPath(to, from M, keys []string) {
+	key, rest := keys[0], keys[1:]
+	if key != "*" {
+		if v, found := from[key]; found {
+			selectValue(to, key, v, rest)
+		}
+		return
+	}
+
+	for k, v := range from {
+		selectValue(to, k, v, rest)
+	}
+}
+
+func selectValue(to M, key string, v interface{}, rest []string) {
+	if len(rest) == 0 {
+		if innerMap, ok := tryToMapStr(v); ok {
+			v = innerMap.Clone()
+		}
+		to[key] = v
+		return
+	}
+
+	innerMap, ok := tryToMapStr(v)
+	if !ok {
+		return
+	}
+
+	selected, _ := to[key].(M)
+	if selected == nil {
+		selected = M{}
+	}
+	selectPath(selected, innerMap, rest)
+	if len(selected) > 0 {
+		to[key] = selected
+	}
+}
+
+// Redact replaces the values found at the given dotted paths with
+// replacement and returns the number of values replaced. A `*` path element
+// matches all the keys of a map. Unlike Delete, the keys are kept. Missing
+// paths are ignored. The map is modified in place.
+func (m M) Redact(paths []string, replacement string) int {
+	count := 0
+	for _, path := range paths {
+		count += redactPath(m, strings.Split(path, "."), replacement)
+	}
+	return count
+}
+
+func redactPath(m M, keys []string, replacement string) int {
+	key, rest := keys[0], keys[1:]
+	if key != "*" {
+		if _, found := m[key]; !found {
+			return 0
+		}
+		return redactValue(m, key, rest, replacement)
+	}
+
+	count := 0
+	for k := range m {
+		count += redactValue(m, k, rest, replacement)
+	}
+	return count
+}
+
+func redactValue(m M, key string, rest []string, replacement string) int {
+	if len(rest) == 0 {
+		m[key] = replacement
+		return 1
+	}
+
+	innerMap, ok := tryToMapStr(m[key])
+	if !ok {
+		return 0
+	}
+	return redactPath(innerMap, rest, replacement)
+}
+
 // HasKey returns true if the key exist. If an error occurs then false is
 // returned with a non-nil error.
 func (m M) HasKey(key string) (bool, error) {
@@ -202,6 +430,35 @@ func (m M) Put(key string, value interface{}) (interface{}, error) {
 	return old, nil
 }
 
+// PutIfAbsent associates value with key if the key is not present in the
+// map. It returns the value stored for key and reports whether it was already
+// present. Unlike Put, key is used as is and is not split on dots.
+//
+// M is not safe for concurrent use, callers must still synchronize access to
+// the map.
+func (m M) PutIfAbsent(key string, value interface{}) (actual interface{}, loaded bool) {
+	if v, exists := m[key]; exists {
+		return v, true
+	}
+	m[key] = value
+	return value, false
+}
+
+// ComputeIfAbsent stores the value returned by f for key if the key is not
+// present in the map, f is not called otherwise. It returns the value stored
+// for key. Unlike Put, key is used as is and is not split on dots.
+//
+// M is not safe for concurrent use, callers must still synchronize access to
+// the map.
+func (m M) ComputeIfAbsent(key string, f func() interface{}) interface{} {
+	if v, exists := m[key]; exists {
+		return v
+	}
+	v := f()
+	m[key] = v
+	return v
+}
+
 // StringToPrint returns the M as pretty JSON.
 func (m M) StringToPrint() string {
 	json, err := json.MarshalIndent(m, "", "  ")
@@ -230,12 +487,7 @@ func (m M) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 	debugM := m.Clone()
 	config.ApplyLoggingMask(map[string]interface{}(debugM))
 
-	keys := make([]string, 0, len(debugM))
-	for k := range debugM {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-	for _, k := range keys {
+	for _, k := range debugM.Keys() {
 		v := debugM[k]
 		if inner, ok := tryToMapStr(v); ok {
 			err := enc.AddObject(k, inner)
@@ -262,6 +514,33 @@ func (m M) Format(f fmt.State, c rune) {
 	_, _ = io.WriteString(f, debugM.String())
 }
 
+// Keys returns the top-level keys of the map in sorted order. Nested maps
+// are not traversed, use FlattenKeys to get the keys of all levels.
+func (m M) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Entry is a key/value pair of a M.
+type Entry struct {
+	Key   string
+	Value interface{}
+}
+
+// SortedEntries returns the top-level key/value pairs of the map sorted by
+// key. Like Keys, nested maps are not traversed and are returned as values.
+func (m M) SortedEntries() []Entry {
+	entries := make([]Entry, 0, len(m))
+	for _, k := range m.Keys() {
+		entries = append(entries, Entry{Key: k, Value: m[k]})
+	}
+	return entries
+}
+
 // Flatten flattens the given M and returns a flat M.
 //
 // Example:
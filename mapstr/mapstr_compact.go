@@ -0,0 +1,140 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package mapstr
+
+import (
+	"reflect"
+)
+
+// CompactOption configures which values are removed by Compact.
+type CompactOption func(*compactOptions)
+
+type compactOptions struct {
+	emptyStrings bool
+	zeroNumbers  bool
+}
+
+// DropEmptyStrings makes Compact remove empty strings.
+func DropEmptyStrings() CompactOption {
+	return func(o *compactOptions) { o.emptyStrings = true }
+}
+
+// DropZeroNumbers makes Compact remove integers and floats equal to zero.
+func DropZeroNumbers() CompactOption {
+	return func(o *compactOptions) { o.zeroNumbers = true }
+}
+
+// Compact recursively removes nil values and empty maps and slices from m, in
+// place. Maps and slices left empty once their content is removed are removed
+// as well. Nil elements are removed from []interface{} slices, slices of
+// other types are only removed if they are empty. Empty strings and zero
+// numbers are kept unless the corresponding option is given.
+//
+// It returns the number of removed leaves: the removed nil, string and number
+// values and the maps and slices that were already empty. Maps and slices
+// emptied by Compact are not counted.
+func (m M) Compact(opts ...CompactOption) int {
+	var o compactOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return compactMap(m, &o)
+}
+
+func compactMap(m map[string]interface{}, o *compactOptions) int {
+	removed := 0
+	for k, v := range m {
+		v, n, drop := compactValue(v, o)
+		removed += n
+		if drop {
+			delete(m, k)
+		} else {
+			m[k] = v
+		}
+	}
+	return removed
+}
+
+// compactValue compacts v. It returns the compacted value, the number of
+// leaves removed from it and if the value itself must be removed.
+func compactValue(v interface{}, o *compactOptions) (interface{}, int, bool) {
+	switch v := v.(type) {
+	case nil:
+		return nil, 1, true
+	case M:
+		if len(v) == 0 {
+			return v, 1, true
+		}
+		removed := compactMap(v, o)
+		return v, removed, len(v) == 0
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return v, 1, true
+		}
+		removed := compactMap(v, o)
+		return v, removed, len(v) == 0
+	case []interface{}:
+		return compactSlice(v, o)
+	case string:
+		if o.emptyStrings && v == "" {
+			return v, 1, true
+		}
+		return v, 0, false
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return v, 1, true
+		}
+	case reflect.Map, reflect.Slice:
+		if rv.IsNil() || rv.Len() == 0 {
+			return v, 1, true
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		if o.zeroNumbers && rv.IsZero() {
+			return v, 1, true
+		}
+	}
+	return v, 0, false
+}
+
+// compactSlice compacts the elements of s. The kept elements are moved to the
+// front of s and the compacted slice is returned.
+func compactSlice(s []interface{}, o *compactOptions) (interface{}, int, bool) {
+	if len(s) == 0 {
+		return s, 1, true
+	}
+
+	removed, kept := 0, 0
+	for _, elem := range s {
+		elem, n, drop := compactValue(elem, o)
+		removed += n
+		if !drop {
+			s[kept] = elem
+			kept++
+		}
+	}
+	for i := kept; i < len(s); i++ {
+		s[i] = nil
+	}
+	return s[:kept], removed, kept == 0
+}
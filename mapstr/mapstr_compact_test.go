@@ -0,0 +1,91 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+// +build !integration
+
+package mapstr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompact(t *testing.T) {
+	var nilPtr *int
+	m := M{
+		"keep":  "value",
+		"empty": "",
+		"zero":  0,
+		"null":  nil,
+		"ptr":   nilPtr,
+		"a": M{
+			"b": map[string]interface{}{
+				"c": M{},
+				"d": []interface{}{nil, M{"e": nil}},
+			},
+			"f": []string{},
+		},
+		"list": []interface{}{"x", nil, M{}, []interface{}{}, "y"},
+		"nested": M{
+			"keep":  1,
+			"empty": M{"deeper": M{"deepest": nil}},
+		},
+	}
+
+	removed := m.Compact()
+	assert.Equal(t, M{
+		"keep":  "value",
+		"empty": "",
+		"zero":  0,
+		"list":  []interface{}{"x", "y"},
+		"nested": M{
+			"keep": 1,
+		},
+	}, m)
+	// null, ptr, c, d[0], e, f, list[1], list[2], list[3], deepest.
+	assert.Equal(t, 10, removed)
+}
+
+func TestCompactOptions(t *testing.T) {
+	m := M{
+		"empty":  "",
+		"zero":   0,
+		"float":  0.0,
+		"uint":   uint8(0),
+		"one":    1,
+		"string": "s",
+		"nested": M{"empty": "", "list": []interface{}{"", 0.0}},
+	}
+
+	removed := m.Compact(DropEmptyStrings())
+	assert.Equal(t, 3, removed)
+	assert.Equal(t, M{"zero": 0, "float": 0.0, "uint": uint8(0), "one": 1, "string": "s", "nested": M{"list": []interface{}{0.0}}}, m)
+
+	removed = m.Compact(DropEmptyStrings(), DropZeroNumbers())
+	assert.Equal(t, 4, removed)
+	assert.Equal(t, M{"one": 1, "string": "s"}, m)
+}
+
+func TestCompactEmpty(t *testing.T) {
+	m := M{"a": M{"b": M{"c": nil}}}
+	assert.Equal(t, 1, m.Compact())
+	assert.Empty(t, m)
+
+	assert.Equal(t, 0, M{}.Compact())
+}
@@ -0,0 +1,184 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package mapstr
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Type tags written before every value, so values of different kinds never
+// produce the same byte sequence.
+const (
+	hashNil byte = iota
+	hashBool
+	hashInt
+	hashUint
+	hashFloat
+	hashString
+	hashTime
+	hashMap
+	hashSlice
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Hash returns a 64-bit FNV-1a hash of the map content. The hash does not
+// depend on key insertion order: keys are sorted at every level before being
+// hashed. It is not a cryptographic hash and must not be used as one.
+//
+// Numbers are hashed by value, not by Go type: all integer types, and floats
+// with an integral value, hash as the same integer, so 1, int64(1), uint8(1)
+// and 1.0 are equal. Floats with a fractional part, infinities and NaN are
+// hashed as float64 values. Times are hashed as their instant, independent
+// of location.
+//
+// Supported values are nil, bools, numbers, strings, time.Time, maps with
+// string keys, slices and arrays of those, and pointers to them. An error is
+// returned for any other type.
+func (m M) Hash() (uint64, error) {
+	h := fnv.New64a()
+	if err := hashValue(h, reflect.ValueOf(m)); err != nil {
+		return 0, err
+	}
+	return h.Sum64(), nil
+}
+
+func hashValue(h hash.Hash64, v reflect.Value) error {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			break
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Invalid, reflect.Interface, reflect.Ptr:
+		// nil values, the loop above dereferences all others
+		writeTag(h, hashNil)
+	case reflect.Bool:
+		writeTag(h, hashBool)
+		if v.Bool() {
+			writeTag(h, 1)
+		} else {
+			writeTag(h, 0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		hashInteger(h, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(h, hashUint, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		hashFloatValue(h, v.Float())
+	case reflect.String:
+		writeString(h, hashString, v.String())
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("cannot hash map with key type %v", v.Type().Key())
+		}
+		return hashMapValue(h, v)
+	case reflect.Slice, reflect.Array:
+		writeUint(h, hashSlice, uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			if err := hashValue(h, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		if v.Type() != timeType {
+			return fmt.Errorf("cannot hash value of type %v", v.Type())
+		}
+		t, _ := v.Interface().(time.Time)
+		writeUint(h, hashTime, uint64(t.Unix()))
+		writeUint(h, hashTime, uint64(t.Nanosecond()))
+	default:
+		return fmt.Errorf("cannot hash value of type %v", v.Type())
+	}
+	return nil
+}
+
+func hashMapValue(h hash.Hash64, v reflect.Value) error {
+	keys := make([]string, 0, v.Len())
+	values := make(map[string]reflect.Value, v.Len())
+	for iter := v.MapRange(); iter.Next(); {
+		k := iter.Key().String()
+		keys = append(keys, k)
+		values[k] = iter.Value()
+	}
+	sort.Strings(keys)
+
+	writeUint(h, hashMap, uint64(len(keys)))
+	for _, k := range keys {
+		writeString(h, hashString, k)
+		if err := hashValue(h, values[k]); err != nil {
+			return fmt.Errorf("key %q: %w", k, err)
+		}
+	}
+	return nil
+}
+
+// hashInteger writes non-negative integers as unsigned, so signed and
+// unsigned integers of the same value hash equally.
+func hashInteger(h hash.Hash64, i int64) {
+	if i >= 0 {
+		writeUint(h, hashUint, uint64(i))
+		return
+	}
+	writeUint(h, hashInt, uint64(i))
+}
+
+// hashFloatValue writes integral floats as integers, see Hash.
+func hashFloatValue(h hash.Hash64, f float64) {
+	switch {
+	case f != math.Trunc(f) || math.IsInf(f, 0):
+		// fractional part, NaN or infinity
+	case f >= 0 && f < math.MaxUint64:
+		writeUint(h, hashUint, uint64(f))
+		return
+	case f < 0 && f >= math.MinInt64:
+		writeUint(h, hashInt, uint64(int64(f)))
+		return
+	}
+
+	bits := math.Float64bits(f)
+	if math.IsNaN(f) {
+		bits = math.Float64bits(math.NaN())
+	}
+	writeUint(h, hashFloat, bits)
+}
+
+func writeString(h hash.Hash64, tag byte, s string) {
+	writeUint(h, tag, uint64(len(s)))
+	_, _ = h.Write([]byte(s))
+}
+
+func writeTag(h hash.Hash64, tag byte) {
+	_, _ = h.Write([]byte{tag})
+}
+
+func writeUint(h hash.Hash64, tag byte, u uint64) {
+	var buf [9]byte
+	buf[0] = tag
+	binary.LittleEndian.PutUint64(buf[1:], u)
+	_, _ = h.Write(buf[:])
+}
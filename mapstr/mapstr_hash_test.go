@@ -0,0 +1,116 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+// +build !integration
+
+package mapstr
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashIndependentOfInsertionOrder(t *testing.T) {
+	a := M{}
+	a["host"] = M{"name": "a", "ip": []interface{}{"10.0.0.1", "10.0.0.2"}}
+	a["message"] = "hello"
+	a["count"] = 3
+
+	b := M{}
+	b["count"] = 3
+	b["message"] = "hello"
+	b["host"] = map[string]interface{}{"ip": []string{"10.0.0.1", "10.0.0.2"}, "name": "a"}
+
+	ha, err := a.Hash()
+	require.NoError(t, err)
+	hb, err := b.Hash()
+	require.NoError(t, err)
+	assert.Equal(t, ha, hb)
+
+	// Hashing is repeatable.
+	again, err := a.Hash()
+	require.NoError(t, err)
+	assert.Equal(t, ha, again)
+}
+
+func TestHashNumbers(t *testing.T) {
+	equal := []interface{}{1, int8(1), int64(1), uint(1), uint64(1), float32(1), 1.0}
+	for _, v := range equal {
+		assert.Equal(t, mustHash(t, M{"n": 1}), mustHash(t, M{"n": v}), "%T(%v)", v, v)
+	}
+
+	assert.Equal(t, mustHash(t, M{"n": -2}), mustHash(t, M{"n": -2.0}))
+	assert.Equal(t, mustHash(t, M{"n": 0}), mustHash(t, M{"n": math.Copysign(0, -1)}))
+	assert.Equal(t, mustHash(t, M{"n": math.NaN()}), mustHash(t, M{"n": math.NaN()}))
+	assert.NotEqual(t, mustHash(t, M{"n": 1}), mustHash(t, M{"n": 1.5}))
+	assert.NotEqual(t, mustHash(t, M{"n": 1}), mustHash(t, M{"n": -1}))
+	assert.NotEqual(t, mustHash(t, M{"n": math.Inf(1)}), mustHash(t, M{"n": math.Inf(-1)}))
+}
+
+func TestHashDistinguishesContent(t *testing.T) {
+	base := mustHash(t, M{"a": "1"})
+
+	tests := map[string]M{
+		"different value":       {"a": "2"},
+		"different key":         {"b": "1"},
+		"number, not string":    {"a": 1},
+		"nested":                {"a": M{"1": nil}},
+		"list":                  {"a": []string{"1"}},
+		"additional key":        {"a": "1", "b": nil},
+		"bool":                  {"a": true},
+		"nil":                   {"a": nil},
+		"key and value shifted": {"a1": ""},
+	}
+	for name, m := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.NotEqual(t, base, mustHash(t, m))
+		})
+	}
+
+	assert.NotEqual(t, mustHash(t, M{"a": []interface{}{"x", "y"}}), mustHash(t, M{"a": []interface{}{"y", "x"}}))
+	assert.NotEqual(t, mustHash(t, M{"a": M{}, "b": "c"}), mustHash(t, M{"a": M{"b": "c"}}))
+}
+
+func TestHashTime(t *testing.T) {
+	ts := time.Date(2022, 3, 1, 10, 0, 0, 5, time.UTC)
+	other := ts.In(time.FixedZone("UTC+2", 2*60*60))
+	assert.Equal(t, mustHash(t, M{"@timestamp": ts}), mustHash(t, M{"@timestamp": other}))
+	assert.NotEqual(t, mustHash(t, M{"@timestamp": ts}), mustHash(t, M{"@timestamp": ts.Add(1)}))
+}
+
+func TestHashUnsupportedType(t *testing.T) {
+	_, err := M{"a": M{"b": struct{}{}}}.Hash()
+	assert.Error(t, err)
+
+	_, err = M{"a": map[int]string{1: "b"}}.Hash()
+	assert.Error(t, err)
+
+	_, err = M{"a": func() {}}.Hash()
+	assert.Error(t, err)
+}
+
+func mustHash(t *testing.T, m M) uint64 {
+	t.Helper()
+	h, err := m.Hash()
+	require.NoError(t, err)
+	return h
+}
@@ -22,6 +22,7 @@ package mapstr
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -107,6 +108,58 @@ func TestMapStrDeepUpdate(t *testing.T) {
 	}
 }
 
+func TestMapStrMergeFunc(t *testing.T) {
+	t.Run("max", func(t *testing.T) {
+		a := M{
+			"event": M{"count": 3, "sequence": 10},
+			"host":  "a",
+		}
+		b := M{
+			"event": M{"count": 5, "sequence": 7, "kind": "metric"},
+			"user":  "b",
+		}
+
+		var paths []string
+		a.MergeFunc(b, func(path string, x, y interface{}) interface{} {
+			paths = append(paths, path)
+			if x.(int) > y.(int) {
+				return x
+			}
+			return y
+		})
+
+		assert.Equal(t, M{
+			"event": M{"count": 5, "sequence": 10, "kind": "metric"},
+			"host":  "a",
+			"user":  "b",
+		}, a)
+		assert.ElementsMatch(t, []string{"event.count", "event.sequence"}, paths)
+	})
+
+	t.Run("concat", func(t *testing.T) {
+		a := M{"tags": []string{"a", "b"}, "fields": map[string]interface{}{"tags": []string{"x"}}}
+		b := M{"tags": []string{"c"}, "fields": M{"tags": []string{"y"}}}
+
+		a.MergeFunc(b, func(_ string, x, y interface{}) interface{} {
+			return append(x.([]string), y.([]string)...)
+		})
+
+		assert.Equal(t, M{
+			"tags":   []string{"a", "b", "c"},
+			"fields": M{"tags": []string{"x", "y"}},
+		}, a)
+	})
+
+	t.Run("map and value conflict", func(t *testing.T) {
+		a := M{"a": M{"b": 1}}
+		a.MergeFunc(M{"a": 2}, func(path string, x, y interface{}) interface{} {
+			assert.Equal(t, "a", path)
+			return y
+		})
+		assert.Equal(t, M{"a": 2}, a)
+	})
+}
+
 func TestMapStrUnion(t *testing.T) {
 	assert := assert.New(t)
 
@@ -270,6 +323,36 @@ func TestMPut(t *testing.T) {
 	assert.Equal(t, M{"subMap": M{"newMap": M{"a": 1}}}, m)
 }
 
+func TestPutIfAbsent(t *testing.T) {
+	m := M{"a": 1}
+
+	actual, loaded := m.PutIfAbsent("a", 2)
+	assert.True(t, loaded)
+	assert.Equal(t, 1, actual)
+
+	actual, loaded = m.PutIfAbsent("b.c", 3)
+	assert.False(t, loaded)
+	assert.Equal(t, 3, actual)
+
+	assert.Equal(t, M{"a": 1, "b.c": 3}, m)
+}
+
+func TestComputeIfAbsent(t *testing.T) {
+	m := M{"a": 1}
+
+	calls := 0
+	compute := func() interface{} {
+		calls++
+		return calls * 10
+	}
+
+	assert.Equal(t, 1, m.ComputeIfAbsent("a", compute))
+	assert.Equal(t, 10, m.ComputeIfAbsent("b", compute))
+	assert.Equal(t, 10, m.ComputeIfAbsent("b", compute))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, M{"a": 1, "b": 10}, m)
+}
+
 func TestMapStrGetValue(t *testing.T) {
 
 	tests := []struct {
@@ -356,6 +439,84 @@ func TestClone(t *testing.T) {
 	assert.Equal(M{"c31": 1, "c32": 2}, c["c3"])
 }
 
+func TestSelect(t *testing.T) {
+	newSource := func() M {
+		return M{
+			"a": 1,
+			"b": M{
+				"c": 2,
+				"d": map[string]interface{}{
+					"e": 3,
+					"f": 4,
+				},
+			},
+			"hosts": M{
+				"h1": M{"name": "one", "ip": "10.0.0.1"},
+				"h2": M{"name": "two", "ip": "10.0.0.2"},
+				"h3": "not a map",
+			},
+		}
+	}
+	source := newSource()
+
+	tests := map[string]struct {
+		paths []string
+		want  M
+	}{
+		"top level": {
+			paths: []string{"a"},
+			want:  M{"a": 1},
+		},
+		"nested leaves": {
+			paths: []string{"b.c", "b.d.e"},
+			want:  M{"b": M{"c": 2, "d": M{"e": 3}}},
+		},
+		"subtree": {
+			paths: []string{"b.d"},
+			want:  M{"b": M{"d": M{"e": 3, "f": 4}}},
+		},
+		"missing paths": {
+			paths: []string{"x", "b.x", "a.x", "b.d.e.x"},
+			want:  M{},
+		},
+		"wildcard": {
+			paths: []string{"hosts.*.name"},
+			want: M{"hosts": M{
+				"h1": M{"name": "one"},
+				"h2": M{"name": "two"},
+			}},
+		},
+		"wildcard leaves": {
+			paths: []string{"b.d.*"},
+			want:  M{"b": M{"d": M{"e": 3, "f": 4}}},
+		},
+		"wildcard and path": {
+			paths: []string{"a", "hosts.h1.ip", "hosts.*.name"},
+			want: M{
+				"a": 1,
+				"hosts": M{
+					"h1": M{"name": "one", "ip": "10.0.0.1"},
+					"h2": M{"name": "two"},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, source.Select(test.paths))
+			assert.Equal(t, newSource(), source)
+		})
+	}
+
+	t.Run("result does not share maps with the source", func(t *testing.T) {
+		selected := source.Select([]string{"b"})
+		_, err := selected.Put("b.d.e", 5)
+		require.NoError(t, err)
+		assert.Equal(t, newSource(), source)
+	})
+}
+
 func TestString(t *testing.T) {
 	type io struct {
 		Input  M
@@ -868,6 +1029,28 @@ func TestFlattenKeys(t *testing.T) {
 	assert.Equal(t, &expected, result)
 }
 
+func TestKeys(t *testing.T) {
+	input := M{
+		"zeta":  1,
+		"alpha": M{"nested": "value"},
+		"mid":   nil,
+		"Beta":  "upper",
+	}
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, []string{"Beta", "alpha", "mid", "zeta"}, input.Keys())
+		assert.Equal(t, []Entry{
+			{Key: "Beta", Value: "upper"},
+			{Key: "alpha", Value: M{"nested": "value"}},
+			{Key: "mid", Value: nil},
+			{Key: "zeta", Value: 1},
+		}, input.SortedEntries())
+	}
+
+	assert.Empty(t, M{}.Keys())
+	assert.Empty(t, M(nil).SortedEntries())
+}
+
 func BenchmarkMapStrFlatten(b *testing.B) {
 	m := M{
 		"test": 15,
@@ -1049,3 +1232,186 @@ func TestFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestRename(t *testing.T) {
+	t.Run("introduces nesting", func(t *testing.T) {
+		m := M{
+			"src_ip":   "10.0.0.1",
+			"src_port": 5000,
+			"dst":      M{"ip": "10.0.0.2"},
+			"message":  "hello",
+		}
+		err := m.Rename(map[string]string{
+			"src_ip":   "source.ip",
+			"src_port": "source.port",
+			"dst.ip":   "destination.ip",
+			"missing":  "ignored",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, M{
+			"source":      M{"ip": "10.0.0.1", "port": 5000},
+			"destination": M{"ip": "10.0.0.2"},
+			"dst":         M{},
+			"message":     "hello",
+		}, m)
+	})
+
+	t.Run("targets can be renamed sources", func(t *testing.T) {
+		m := M{"a": 1, "b": 2, "host": "localhost"}
+		require.NoError(t, m.Rename(map[string]string{
+			"a":    "b",
+			"b":    "c",
+			"host": "host.name",
+		}))
+		assert.Equal(t, M{"b": 1, "c": 2, "host": M{"name": "localhost"}}, m)
+	})
+
+	conflicts := map[string]struct {
+		input   M
+		mapping map[string]string
+	}{
+		"existing target": {
+			input:   M{"src_ip": "10.0.0.1", "source": M{"ip": "10.0.0.3"}},
+			mapping: map[string]string{"src_ip": "source.ip"},
+		},
+		"target below a value": {
+			input:   M{"src_ip": "10.0.0.1", "source": "host"},
+			mapping: map[string]string{"src_ip": "source.ip"},
+		},
+		"same target": {
+			input:   M{"a": 1, "b": 2},
+			mapping: map[string]string{"a": "c", "b": "c"},
+		},
+		"nested targets": {
+			input:   M{"a": 1, "b": 2},
+			mapping: map[string]string{"a": "c", "b": "c.d"},
+		},
+	}
+	for name, test := range conflicts {
+		t.Run(name, func(t *testing.T) {
+			expected := test.input.Clone()
+			err := test.input.Rename(test.mapping)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrKeyConflict), err)
+			assert.Equal(t, expected, test.input)
+		})
+	}
+
+	t.Run("deterministic", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			err := M{"a": 1, "b": 2, "c": 3}.Rename(map[string]string{"c": "x", "a": "x.y", "b": "x"})
+			require.Error(t, err)
+			assert.Equal(t, "cannot rename 'b' to 'x', conflicts with the rename of 'a' to 'x.y': key conflict", err.Error())
+		}
+	})
+}
+
+func TestRedact(t *testing.T) {
+	t.Run("nested paths", func(t *testing.T) {
+		m := M{
+			"user": M{"name": "alice", "password": "secret"},
+			"http": map[string]interface{}{
+				"request": M{"headers": M{"authorization": "Bearer token", "accept": "*/*"}},
+			},
+			"message": "hello",
+		}
+		n := m.Redact([]string{"user.password", "http.request.headers.authorization", "missing.key", "message.sub"}, "REDACTED")
+		assert.Equal(t, 2, n)
+		assert.Equal(t, M{
+			"user": M{"name": "alice", "password": "REDACTED"},
+			"http": map[string]interface{}{
+				"request": M{"headers": M{"authorization": "REDACTED", "accept": "*/*"}},
+			},
+			"message": "hello",
+		}, m)
+	})
+
+	t.Run("wildcards", func(t *testing.T) {
+		m := M{
+			"outputs": M{
+				"es":     M{"password": "a", "hosts": []string{"localhost"}},
+				"kafka":  M{"password": "b"},
+				"stdout": M{"pretty": true},
+			},
+			"tokens": M{"a": "1", "b": "2"},
+		}
+		n := m.Redact([]string{"outputs.*.password", "tokens.*"}, "***")
+		assert.Equal(t, 4, n)
+		assert.Equal(t, M{
+			"outputs": M{
+				"es":     M{"password": "***", "hosts": []string{"localhost"}},
+				"kafka":  M{"password": "***"},
+				"stdout": M{"pretty": true},
+			},
+			"tokens": M{"a": "***", "b": "***"},
+		}, m)
+	})
+
+	t.Run("keeps the keys", func(t *testing.T) {
+		m := M{"secret": M{"nested": "value"}}
+		assert.Equal(t, 1, m.Redact([]string{"secret"}, ""))
+		assert.Equal(t, M{"secret": ""}, m)
+	})
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	tests := map[string]struct {
+		target, patch, want M
+	}{
+		"delete keys": {
+			target: M{"a": 1, "b": 2},
+			patch:  M{"a": nil, "missing": nil},
+			want:   M{"b": 2},
+		},
+		"nested patch": {
+			target: M{"host": M{"name": "web-1", "ip": "10.0.0.1", "os": map[string]interface{}{"family": "linux"}}},
+			patch:  M{"host": M{"ip": nil, "os": M{"version": "12"}}},
+			want:   M{"host": M{"name": "web-1", "os": M{"family": "linux", "version": "12"}}},
+		},
+		"replace scalar": {
+			target: M{"a": "b", "n": 1},
+			patch:  M{"a": "c", "n": 2.5},
+			want:   M{"a": "c", "n": 2.5},
+		},
+		"map replaces scalar": {
+			target: M{"a": "b"},
+			patch:  M{"a": M{"b": "c", "d": nil}},
+			want:   M{"a": M{"b": "c"}},
+		},
+		"scalar replaces map": {
+			target: M{"a": M{"b": "c"}},
+			patch:  M{"a": "c"},
+			want:   M{"a": "c"},
+		},
+		"arrays replace": {
+			target: M{"tags": []string{"a", "b"}},
+			patch:  M{"tags": []interface{}{"c"}},
+			want:   M{"tags": []interface{}{"c"}},
+		},
+		"new nested keys": {
+			target: M{},
+			patch:  M{"a": map[string]interface{}{"b": M{"c": 1, "d": nil}}},
+			want:   M{"a": M{"b": M{"c": 1}}},
+		},
+		"dotted keys are literal": {
+			target: M{"a": M{"b": 1}},
+			patch:  M{"a.b": nil, "a.c": 2},
+			want:   M{"a": M{"b": 1}, "a.c": 2},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test.target.ApplyMergePatch(test.patch)
+			assert.Equal(t, test.want, test.target)
+		})
+	}
+
+	t.Run("patch is not shared", func(t *testing.T) {
+		patch := M{"a": M{"b": 1}}
+		target := M{}
+		target.ApplyMergePatch(patch)
+		target["a"].(M)["b"] = 2
+		assert.Equal(t, M{"a": M{"b": 1}}, patch)
+	})
+}
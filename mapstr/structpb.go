@@ -0,0 +1,148 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package mapstr
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"time"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// FromStruct converts a protobuf Struct to M. Nested structs are converted
+// to M and lists to []interface{}. All numbers are float64, as they are in
+// the Struct, null values are nil. A nil Struct returns nil.
+func FromStruct(s *structpb.Struct) M {
+	if s == nil {
+		return nil
+	}
+	m := make(M, len(s.GetFields()))
+	for k, v := range s.GetFields() {
+		m[k] = fromStructValue(v)
+	}
+	return m
+}
+
+func fromStructValue(v *structpb.Value) interface{} {
+	switch kind := v.GetKind().(type) {
+	case *structpb.Value_BoolValue:
+		return kind.BoolValue
+	case *structpb.Value_NumberValue:
+		return kind.NumberValue
+	case *structpb.Value_StringValue:
+		return kind.StringValue
+	case *structpb.Value_StructValue:
+		return FromStruct(kind.StructValue)
+	case *structpb.Value_ListValue:
+		values := kind.ListValue.GetValues()
+		list := make([]interface{}, len(values))
+		for i, elem := range values {
+			list[i] = fromStructValue(elem)
+		}
+		return list
+	default:
+		return nil
+	}
+}
+
+// ToStruct converts the map to a protobuf Struct. Nested maps with string
+// keys are converted to structs, slices and arrays to lists, and nil values,
+// maps and slices to null. Times are converted to RFC3339 strings and byte
+// slices to base64 strings.
+//
+// The conversion of numbers is lossy: Struct only holds float64 numbers, so
+// integers are converted to float64 and the ones with an absolute value
+// above 2^53 lose precision. Integers come back as float64 from FromStruct.
+//
+// An error is returned for values of any other type.
+func (m M) ToStruct() (*structpb.Struct, error) {
+	s := &structpb.Struct{Fields: make(map[string]*structpb.Value, len(m))}
+	for k, v := range m {
+		value, err := toStructValue(reflect.ValueOf(v))
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k, err)
+		}
+		s.Fields[k] = value
+	}
+	return s, nil
+}
+
+func toStructValue(v reflect.Value) (*structpb.Value, error) {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return structpb.NewNullValue(), nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Invalid:
+		return structpb.NewNullValue(), nil
+	case reflect.Bool:
+		return structpb.NewBoolValue(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return structpb.NewNumberValue(float64(v.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return structpb.NewNumberValue(float64(v.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return structpb.NewNumberValue(v.Float()), nil
+	case reflect.String:
+		return structpb.NewStringValue(v.String()), nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("cannot convert map with key type %v", v.Type().Key())
+		}
+		if v.IsNil() {
+			return structpb.NewNullValue(), nil
+		}
+		s := &structpb.Struct{Fields: make(map[string]*structpb.Value, v.Len())}
+		for iter := v.MapRange(); iter.Next(); {
+			k := iter.Key().String()
+			value, err := toStructValue(iter.Value())
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", k, err)
+			}
+			s.Fields[k] = value
+		}
+		return structpb.NewStructValue(s), nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return structpb.NewNullValue(), nil
+		}
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return structpb.NewStringValue(base64.StdEncoding.EncodeToString(v.Bytes())), nil
+		}
+		list := &structpb.ListValue{Values: make([]*structpb.Value, v.Len())}
+		for i := 0; i < v.Len(); i++ {
+			value, err := toStructValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			list.Values[i] = value
+		}
+		return structpb.NewListValue(list), nil
+	case reflect.Struct:
+		if v.Type() == timeType {
+			t, _ := v.Interface().(time.Time)
+			return structpb.NewStringValue(t.Format(time.RFC3339Nano)), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot convert value of type %v", v.Type())
+}
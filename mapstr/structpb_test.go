@@ -0,0 +1,110 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+// +build !integration
+
+package mapstr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestStructRoundTrip(t *testing.T) {
+	m := M{
+		"message": "hello",
+		"count":   3.0,
+		"ratio":   0.25,
+		"ok":      true,
+		"none":    nil,
+		"host": M{
+			"name": "a",
+			"ip":   []interface{}{"10.0.0.1", "10.0.0.2"},
+			"os": M{
+				"family": "linux",
+			},
+		},
+		"events": []interface{}{
+			M{"id": 1.0, "tags": []interface{}{"x", nil}},
+			[]interface{}{true, 2.5},
+		},
+	}
+
+	s, err := m.ToStruct()
+	require.NoError(t, err)
+	assert.Equal(t, m, FromStruct(s))
+}
+
+func TestToStructConversions(t *testing.T) {
+	ts := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	m := M{
+		"int":     42,
+		"uint8":   uint8(7),
+		"int64":   int64(1) << 53,
+		"float32": float32(1.5),
+		"strings": []string{"a", "b"},
+		"map":     map[string]interface{}{"nested": M{"n": 1}},
+		"time":    ts,
+		"bytes":   []byte("hi"),
+		"nilList": []string(nil),
+		"nilMap":  M(nil),
+	}
+
+	s, err := m.ToStruct()
+	require.NoError(t, err)
+
+	assert.Equal(t, M{
+		"int":     42.0,
+		"uint8":   7.0,
+		"int64":   float64(int64(1) << 53),
+		"float32": 1.5,
+		"strings": []interface{}{"a", "b"},
+		"map":     M{"nested": M{"n": 1.0}},
+		"time":    "2022-03-01T10:00:00Z",
+		"bytes":   "aGk=",
+		"nilList": nil,
+		"nilMap":  nil,
+	}, FromStruct(s))
+
+	// numbers are all float64 in the Struct
+	assert.Equal(t, 42.0, s.Fields["int"].GetNumberValue())
+	_, isNull := s.Fields["nilMap"].GetKind().(*structpb.Value_NullValue)
+	assert.True(t, isNull)
+}
+
+func TestToStructUnsupportedType(t *testing.T) {
+	_, err := M{"a": M{"b": struct{}{}}}.ToStruct()
+	assert.Error(t, err)
+
+	_, err = M{"a": map[int]string{1: "b"}}.ToStruct()
+	assert.Error(t, err)
+}
+
+func TestFromStruct(t *testing.T) {
+	assert.Nil(t, FromStruct(nil))
+
+	s, err := structpb.NewStruct(map[string]interface{}{
+		"a": map[string]interface{}{"b": []interface{}{1, "c"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, M{"a": M{"b": []interface{}{1.0, "c"}}}, FromStruct(s))
+}
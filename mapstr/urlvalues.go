@@ -0,0 +1,97 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package mapstr
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"time"
+)
+
+// URLKeyStyle selects how ToURLValuesWithStyle names the keys of nested
+// maps.
+type URLKeyStyle int
+
+const (
+	// DottedKeys names nested keys `a.b.c`.
+	DottedKeys URLKeyStyle = iota
+	// BracketedKeys names nested keys `a[b][c]`.
+	BracketedKeys
+)
+
+// ToURLValues converts m to query parameters, nested maps are flattened to
+// dotted keys. See ToURLValuesWithStyle.
+func (m M) ToURLValues() url.Values {
+	return m.ToURLValuesWithStyle(DottedKeys)
+}
+
+// ToURLValuesWithStyle converts m to query parameters, nested maps are
+// flattened to keys named following style. Values are formatted with
+// fmt.Sprint, times in the RFC 3339 format. Every element of an array is
+// added as a value of the same key. Nil values are skipped.
+func (m M) ToURLValuesWithStyle(style URLKeyStyle) url.Values {
+	values := url.Values{}
+	addURLValues(values, "", m, style)
+	return values
+}
+
+func addURLValues(values url.Values, prefix string, m M, style URLKeyStyle) {
+	for k, v := range m {
+		addURLValue(values, urlKey(prefix, k, style), v, style)
+	}
+}
+
+func addURLValue(values url.Values, key string, v interface{}, style URLKeyStyle) {
+	if v == nil {
+		return
+	}
+	if m, ok := tryToMapStr(v); ok {
+		addURLValues(values, key, m, style)
+		return
+	}
+
+	switch val := v.(type) {
+	case string:
+		values.Add(key, val)
+	case []byte:
+		values.Add(key, string(val))
+	case time.Time:
+		values.Add(key, val.Format(time.RFC3339Nano))
+	default:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			values.Add(key, fmt.Sprint(v))
+			return
+		}
+		for i := 0; i < rv.Len(); i++ {
+			addURLValue(values, key, rv.Index(i).Interface(), style)
+		}
+	}
+}
+
+func urlKey(prefix, key string, style URLKeyStyle) string {
+	switch {
+	case prefix == "":
+		return key
+	case style == BracketedKeys:
+		return prefix + "[" + key + "]"
+	default:
+		return prefix + "." + key
+	}
+}
@@ -0,0 +1,69 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+// +build !integration
+
+package mapstr
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToURLValues(t *testing.T) {
+	m := M{
+		"type":     []string{"dashboard", "visualization"},
+		"per_page": 100,
+		"search":   "web*",
+		"filter": M{
+			"enabled": true,
+			"owner":   map[string]interface{}{"name": "alice"},
+			"ids":     []interface{}{1, "two", nil},
+		},
+		"since":   time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC),
+		"missing": nil,
+	}
+
+	assert.Equal(t, url.Values{
+		"type":              {"dashboard", "visualization"},
+		"per_page":          {"100"},
+		"search":            {"web*"},
+		"filter.enabled":    {"true"},
+		"filter.owner.name": {"alice"},
+		"filter.ids":        {"1", "two"},
+		"since":             {"2022-03-01T10:00:00Z"},
+	}, m.ToURLValues())
+
+	assert.Equal(t, url.Values{
+		"type":                {"dashboard", "visualization"},
+		"per_page":            {"100"},
+		"search":              {"web*"},
+		"filter[enabled]":     {"true"},
+		"filter[owner][name]": {"alice"},
+		"filter[ids]":         {"1", "two"},
+		"since":               {"2022-03-01T10:00:00Z"},
+	}, m.ToURLValuesWithStyle(BracketedKeys))
+}
+
+func TestToURLValuesEmpty(t *testing.T) {
+	assert.Equal(t, url.Values{}, M{}.ToURLValues())
+	assert.Equal(t, "", M{"a": M{}}.ToURLValues().Encode())
+}
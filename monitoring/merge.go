@@ -0,0 +1,127 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package monitoring
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Merge makes the metrics of other available in r under prefix, or at the
+// root of r if prefix is empty. The metrics are linked, not copied: updates
+// of the metrics of other are reported by r.
+//
+// If nothing is registered under prefix, other itself is added to r, so the
+// metrics added to other later are reported by r too. Otherwise the entries
+// of other are linked into the existing sub-registry one by one, merging the
+// sub-registries present in both, and the metrics added to other after the
+// merge are not reported by r.
+//
+// An error is returned and r is left unchanged if a name is used by both
+// registries, except for sub-registries, or if prefix is used by a metric.
+func (r *Registry) Merge(other *Registry, prefix string) error {
+	if other == nil || other == r {
+		return errors.New("cannot merge a registry into itself")
+	}
+
+	target := r
+	if prefix != "" {
+		e, err := r.find(prefix)
+		switch {
+		case errors.Is(err, errNotFound) || (err == nil && e.Var == nil):
+			opts := *r.opts
+			opts.mode = other.opts.mode
+			return r.addNames(strings.Split(prefix, "."), other, opts.withoutMeta())
+		case err != nil:
+			return fmt.Errorf("name %v already used", prefix)
+		}
+
+		reg, ok := e.Var.(*Registry)
+		if !ok {
+			return fmt.Errorf("name %v already used", prefix)
+		}
+		if reg == other {
+			return errors.New("cannot merge a registry into itself")
+		}
+		target = reg
+	}
+
+	if err := checkMerge(target, other, prefix); err != nil {
+		return err
+	}
+	linkEntries(target, other)
+	return nil
+}
+
+// checkMerge returns an error if a name of src is already used in dst.
+func checkMerge(dst, src *Registry, path string) error {
+	for name, e := range src.snapshotEntries() {
+		dst.mu.RLock()
+		existing, found := dst.entries[name]
+		dst.mu.RUnlock()
+		if !found {
+			continue
+		}
+
+		fullName := name
+		if path != "" {
+			fullName = path + "." + name
+		}
+		dstReg, dstIsReg := existing.Var.(*Registry)
+		srcReg, srcIsReg := e.Var.(*Registry)
+		if !dstIsReg || !srcIsReg || dstReg == srcReg {
+			return fmt.Errorf("name %v already used", fullName)
+		}
+		if err := checkMerge(dstReg, srcReg, fullName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// linkEntries adds the entries of src to dst, the sub-registries present in
+// both are merged.
+func linkEntries(dst, src *Registry) {
+	for name, e := range src.snapshotEntries() {
+		dst.mu.Lock()
+		existing, found := dst.entries[name]
+		if !found {
+			dst.entries[name] = e
+		}
+		dst.mu.Unlock()
+
+		// checkMerge ensures both are registries.
+		dstReg, dstIsReg := existing.Var.(*Registry)
+		srcReg, srcIsReg := e.Var.(*Registry)
+		if found && dstIsReg && srcIsReg {
+			linkEntries(dstReg, srcReg)
+		}
+	}
+}
+
+func (r *Registry) snapshotEntries() map[string]entry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make(map[string]entry, len(r.entries))
+	for name, e := range r.entries {
+		entries[name] = e
+	}
+	return entries
+}
@@ -0,0 +1,129 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+// +build !integration
+
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryMerge(t *testing.T) {
+	t.Run("new prefix links the registry", func(t *testing.T) {
+		r := NewRegistry()
+		NewInt(r, "own").Set(1)
+
+		sub := NewRegistry()
+		events := NewInt(sub, "events")
+		require.NoError(t, r.Merge(sub, "component.a"))
+
+		events.Add(5)
+		NewString(sub, "state").Set("running")
+		assert.Equal(t, map[string]interface{}{
+			"own":                int64(1),
+			"component.a.events": int64(5),
+			"component.a.state":  "running",
+		}, r.SnapshotFlat())
+	})
+
+	t.Run("existing prefix merges the entries", func(t *testing.T) {
+		r := NewRegistry()
+		NewInt(r, "component.a.own").Set(1)
+		NewInt(r, "component.a.output.acked").Set(2)
+
+		sub := NewRegistry()
+		events := NewInt(sub, "events")
+		NewInt(sub, "output.failed").Set(3)
+		require.NoError(t, r.Merge(sub, "component.a"))
+
+		events.Inc()
+		assert.Equal(t, map[string]interface{}{
+			"component.a.own":           int64(1),
+			"component.a.events":        int64(1),
+			"component.a.output.acked":  int64(2),
+			"component.a.output.failed": int64(3),
+		}, r.SnapshotFlat())
+	})
+
+	t.Run("empty prefix merges into the root", func(t *testing.T) {
+		r := NewRegistry()
+		NewInt(r, "a").Set(1)
+		sub := NewRegistry()
+		NewInt(sub, "b").Set(2)
+
+		require.NoError(t, r.Merge(sub, ""))
+		assert.Equal(t, map[string]interface{}{"a": int64(1), "b": int64(2)}, r.SnapshotFlat())
+	})
+
+	conflicts := map[string]struct {
+		prefix string
+		build  func(r, sub *Registry)
+	}{
+		"same metric": {
+			prefix: "component",
+			build: func(r, sub *Registry) {
+				NewInt(r, "component.events")
+				NewInt(sub, "events")
+			},
+		},
+		"registry and metric": {
+			prefix: "component",
+			build: func(r, sub *Registry) {
+				NewInt(r, "component.output")
+				NewInt(sub, "output.acked")
+			},
+		},
+		"prefix is a metric": {
+			prefix: "component",
+			build: func(r, sub *Registry) {
+				NewInt(r, "component")
+				NewInt(sub, "events")
+			},
+		},
+		"prefix below a metric": {
+			prefix: "component.a",
+			build: func(r, sub *Registry) {
+				NewInt(r, "component")
+				NewInt(sub, "events")
+			},
+		},
+	}
+	for name, test := range conflicts {
+		t.Run(name, func(t *testing.T) {
+			r, sub := NewRegistry(), NewRegistry()
+			NewInt(sub, "extra")
+			test.build(r, sub)
+			before := r.SnapshotFlat()
+
+			err := r.Merge(sub, test.prefix)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "already used")
+			assert.Equal(t, before, r.SnapshotFlat(), "registry must be unchanged")
+		})
+	}
+
+	t.Run("into itself", func(t *testing.T) {
+		r := NewRegistry()
+		assert.Error(t, r.Merge(r, ""))
+		assert.Error(t, r.Merge(nil, "x"))
+	})
+}
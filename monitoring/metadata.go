@@ -0,0 +1,92 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package monitoring
+
+import "strings"
+
+// MetricType is the kind of a metric, used by exporters.
+type MetricType string
+
+const (
+	// Untyped is the type of metrics registered without WithType.
+	Untyped MetricType = ""
+	// Gauge is a value that can go up and down.
+	Gauge MetricType = "gauge"
+	// Counter is a value that only increases.
+	Counter MetricType = "counter"
+)
+
+// Metadata describes a metric. It is set when registering the metric with
+// the WithType, WithUnit and WithDescription options. All fields are
+// optional, the zero value is valid.
+type Metadata struct {
+	Type        MetricType
+	Unit        string
+	Description string
+}
+
+// Metadata returns the metadata of the metric registered under name. The
+// zero value is returned if the metric does not exist or has no metadata.
+func (r *Registry) Metadata(name string) Metadata {
+	e, err := r.find(name)
+	if err != nil {
+		return Metadata{}
+	}
+	return e.meta
+}
+
+// CollectMetadata collects the metadata of all metrics of the registry,
+// keyed by the dotted names used in flat snapshots. Metrics without metadata
+// are included with the zero value.
+func CollectMetadata(r *Registry, mode Mode) map[string]Metadata {
+	if r == nil {
+		r = Default
+	}
+
+	meta := map[string]Metadata{}
+	visitMetrics(r, mode, "", func(name string, m Metadata, _ interface{}) {
+		meta[name] = m
+	})
+	return meta
+}
+
+// visitMetrics calls fn with the dotted name, metadata and value of every
+// metric of the registry.
+func visitMetrics(r *Registry, mode Mode, prefix string, fn func(string, Metadata, interface{})) {
+	for key, e := range r.snapshotEntries() {
+		name := key
+		if prefix != "" {
+			name = strings.Join([]string{prefix, key}, ".")
+		}
+
+		if reg, ok := e.Var.(*Registry); ok {
+			visitMetrics(reg, mode, name, fn)
+			continue
+		}
+		if e.Mode > mode {
+			continue
+		}
+
+		meta := e.meta
+		vs := NewKeyValueVisitor(func(name string, value interface{}) {
+			fn(name, meta, value)
+		})
+		vs.OnKey(name)
+		e.Var.Visit(mode, vs)
+	}
+}
@@ -0,0 +1,86 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+// +build !integration
+
+package monitoring
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadata(t *testing.T) {
+	r := NewRegistry()
+	NewInt(r, "events.total", WithType(Counter), WithDescription("Total number of events."))
+	NewFloat(r, "load", WithType(Gauge))
+	NewInt(r, "plain")
+	sub := r.NewRegistry("output", WithUnit("bytes"))
+	NewUint(sub, "write", WithUnit("bytes"), WithType(Counter))
+	NewUint(sub, "read")
+
+	assert.Equal(t, Metadata{Type: Counter, Description: "Total number of events."}, r.Metadata("events.total"))
+	assert.Equal(t, Metadata{Type: Gauge}, r.Metadata("load"))
+	assert.Equal(t, Metadata{}, r.Metadata("plain"))
+	assert.Equal(t, Metadata{}, r.Metadata("missing"))
+
+	assert.Equal(t, map[string]Metadata{
+		"events.total": {Type: Counter, Description: "Total number of events."},
+		"load":         {Type: Gauge},
+		"plain":        {},
+		"output.write": {Type: Counter, Unit: "bytes"},
+		"output.read":  {},
+	}, CollectMetadata(r, Full))
+}
+
+func TestWritePrometheus(t *testing.T) {
+	r := NewRegistry()
+	NewInt(r, "events.total", WithType(Counter), WithDescription("Total number of events."))
+	NewFloat(r, "load", WithType(Gauge)).Set(1.5)
+	NewBool(r, "ready").Set(true)
+	NewString(r, "name").Set("ignored")
+	NewUint(r.NewRegistry("output"), "write", WithUnit("bytes"), WithType(Counter)).Add(42)
+	r.Get("events.total").(*Int).Add(3)
+
+	var buf strings.Builder
+	require.NoError(t, WritePrometheus(&buf, r, Full))
+
+	expected := strings.Join([]string{
+		"# HELP events_total Total number of events.",
+		"# TYPE events_total counter",
+		"events_total 3",
+		"# TYPE load gauge",
+		"load 1.5",
+		"# TYPE output_write_bytes counter",
+		"output_write_bytes 42",
+		"# TYPE ready untyped",
+		"ready 1",
+		"",
+	}, "\n")
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestPrometheusName(t *testing.T) {
+	assert.Equal(t, "output_write_bytes", prometheusName("output.write", "bytes"))
+	assert.Equal(t, "output_write_bytes", prometheusName("output.write_bytes", "bytes"))
+	assert.Equal(t, "uptime_ms", prometheusName("uptime.ms", "ms"))
+	assert.Equal(t, "_1m_load", prometheusName("1m-load", ""))
+}
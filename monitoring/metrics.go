@@ -35,7 +35,10 @@ const TSLayout = "2006-01-02T15:04:05.000Z"
 type makeExpvar func() string
 
 // Int is a 64 bit integer variable satisfying the Var interface.
-type Int struct{ i atomic.Int64 }
+type Int struct {
+	i atomic.Int64
+	ttlUpdates
+}
 
 // NewInt creates and registers a new integer variable.
 //
@@ -54,16 +57,27 @@ func NewInt(r *Registry, name string, opts ...Option) *Int {
 	return v
 }
 
-func (v *Int) Get() int64               { return v.i.Load() }
-func (v *Int) Set(value int64)          { v.i.Store(value) }
-func (v *Int) Add(delta int64)          { v.i.Add(delta) }
-func (v *Int) Sub(delta int64)          { v.i.Sub(delta) }
-func (v *Int) Inc()                     { v.i.Inc() }
-func (v *Int) Dec()                     { v.i.Dec() }
+func (v *Int) Get() int64      { return v.i.Load() }
+func (v *Int) Set(value int64) { v.i.Store(value); v.update() }
+
+func (v *Int) Add(delta int64)          { v.i.Add(delta); v.update() }
+func (v *Int) Sub(delta int64)          { v.i.Sub(delta); v.update() }
+func (v *Int) Inc()                     { v.i.Inc(); v.update() }
+func (v *Int) Dec()                     { v.i.Dec(); v.update() }
 func (v *Int) Visit(_ Mode, vs Visitor) { vs.OnInt(v.Get()) }
 
+// AddAndGet, SubAndGet, IncAndGet and DecAndGet update the variable
+// atomically and return the new value.
+func (v *Int) AddAndGet(delta int64) int64 { defer v.update(); return v.i.Add(delta) }
+func (v *Int) SubAndGet(delta int64) int64 { defer v.update(); return v.i.Sub(delta) }
+func (v *Int) IncAndGet() int64            { defer v.update(); return v.i.Inc() }
+func (v *Int) DecAndGet() int64            { defer v.update(); return v.i.Dec() }
+
 // Uint is a 64bit unsigned integer variable satisfying the Var interface.
-type Uint struct{ u atomic.Uint64 }
+type Uint struct {
+	u atomic.Uint64
+	ttlUpdates
+}
 
 // NewUint creates and registers a new unsigned integer variable.
 //
@@ -83,18 +97,21 @@ func NewUint(r *Registry, name string, opts ...Option) *Uint {
 }
 
 func (v *Uint) Get() uint64      { return v.u.Load() }
-func (v *Uint) Set(value uint64) { v.u.Store(value) }
-func (v *Uint) Add(delta uint64) { v.u.Add(delta) }
-func (v *Uint) Sub(delta uint64) { v.u.Sub(delta) }
-func (v *Uint) Inc()             { v.u.Inc() }
-func (v *Uint) Dec()             { v.u.Dec() }
+func (v *Uint) Set(value uint64) { v.u.Store(value); v.update() }
+func (v *Uint) Add(delta uint64) { v.u.Add(delta); v.update() }
+func (v *Uint) Sub(delta uint64) { v.u.Sub(delta); v.update() }
+func (v *Uint) Inc()             { v.u.Inc(); v.update() }
+func (v *Uint) Dec()             { v.u.Dec(); v.update() }
 func (v *Uint) Visit(_ Mode, vs Visitor) {
 	value := v.Get() & (^uint64(1 << 63))
 	vs.OnInt(int64(value))
 }
 
 // Float is a 64 bit float variable satisfying the Var interface.
-type Float struct{ f atomic.Uint64 }
+type Float struct {
+	f atomic.Uint64
+	ttlUpdates
+}
 
 // NewFloat creates and registers a new float variable.
 //
@@ -114,7 +131,7 @@ func NewFloat(r *Registry, name string, opts ...Option) *Float {
 }
 
 func (v *Float) Get() float64             { return math.Float64frombits(v.f.Load()) }
-func (v *Float) Set(value float64)        { v.f.Store(math.Float64bits(value)) }
+func (v *Float) Set(value float64)        { v.f.Store(math.Float64bits(value)); v.update() }
 func (v *Float) Sub(delta float64)        { v.Add(-delta) }
 func (v *Float) Visit(_ Mode, vs Visitor) { vs.OnFloat(v.Get()) }
 
@@ -123,13 +140,17 @@ func (v *Float) Add(delta float64) {
 		cur := v.f.Load()
 		next := math.Float64bits(math.Float64frombits(cur) + delta)
 		if v.f.CAS(cur, next) {
+			v.update()
 			return
 		}
 	}
 }
 
 // Bool is a Bool variable satisfying the Var interface.
-type Bool struct{ f atomic.Bool }
+type Bool struct {
+	f atomic.Bool
+	ttlUpdates
+}
 
 // NewBool creates and registers a new bool variable.
 //
@@ -149,13 +170,14 @@ func NewBool(r *Registry, name string, opts ...Option) *Bool {
 }
 
 func (v *Bool) Get() bool                { return v.f.Load() }
-func (v *Bool) Set(value bool)           { v.f.Store(value) }
+func (v *Bool) Set(value bool)           { v.f.Store(value); v.update() }
 func (v *Bool) Visit(_ Mode, vs Visitor) { vs.OnBool(v.Get()) }
 
 // String is a string variable satisfying the Var interface.
 type String struct {
 	mu sync.RWMutex
 	s  string
+	ttlUpdates
 }
 
 // NewString creates and registers a new string variable.
@@ -190,6 +212,7 @@ func (v *String) Set(s string) {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 	v.s = s
+	v.update()
 }
 
 func (v *String) Clear() {
@@ -225,6 +248,9 @@ func (m makeExpvar) String() string { return m() }
 func addVar(r *Registry, name string, opts []Option, v Var, ev expvar.Var) {
 	O := varOpts(r.opts, opts)
 	r.doAdd(name, v, O)
+	if tv, ok := v.(ttlVar); ok && O.ttl > 0 {
+		metricsReaper.track(r, name, tv, O.ttl)
+	}
 	if O.publishExpvar && ev != nil {
 		expvar.Publish(fullName(r, name), ev)
 	}
@@ -242,6 +268,7 @@ type Timestamp struct {
 	mu     sync.RWMutex
 	ts     time.Time
 	cached string
+	ttlUpdates
 }
 
 // NewTimestamp creates and registers a new timestamp variable.
@@ -264,6 +291,7 @@ func (v *Timestamp) Set(t time.Time) {
 
 	v.ts = t
 	v.cached = ""
+	v.update()
 }
 
 func (v *Timestamp) Get() time.Time {
@@ -0,0 +1,85 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+// +build !integration
+
+package monitoring
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntReturnsNewValue(t *testing.T) {
+	v := NewInt(NewRegistry(), "counter")
+	assert.EqualValues(t, 1, v.IncAndGet())
+	assert.EqualValues(t, 11, v.AddAndGet(10))
+	assert.EqualValues(t, 8, v.SubAndGet(3))
+	assert.EqualValues(t, 7, v.DecAndGet())
+	assert.EqualValues(t, 7, v.Get())
+
+	v.Inc()
+	v.Add(10)
+	v.Sub(3)
+	v.Dec()
+	assert.EqualValues(t, 14, v.Get())
+}
+
+func TestIntConcurrentIncrements(t *testing.T) {
+	const (
+		workers    = 8
+		increments = 1000
+	)
+	v := NewInt(NewRegistry(), "counter")
+
+	results := make([][]int64, workers)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < increments; j++ {
+				if j%2 == 0 {
+					results[i] = append(results[i], v.IncAndGet())
+				} else {
+					results[i] = append(results[i], v.AddAndGet(1))
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[int64]bool, workers*increments)
+	for _, values := range results {
+		for j, value := range values {
+			require.False(t, seen[value], "value %v returned twice", value)
+			seen[value] = true
+			if j > 0 {
+				require.Greater(t, value, values[j-1])
+			}
+		}
+	}
+	assert.Len(t, seen, workers*increments)
+	assert.EqualValues(t, workers*increments, v.Get())
+	for i := int64(1); i <= workers*increments; i++ {
+		assert.True(t, seen[i], "value %v not returned", i)
+	}
+}
@@ -17,12 +17,16 @@
 
 package monitoring
 
+import "time"
+
 // Option type for passing additional options to NewRegistry.
 type Option func(options) options
 
 type options struct {
 	publishExpvar bool
 	mode          Mode
+	meta          Metadata
+	ttl           time.Duration
 }
 
 var defaultOptions = options{
@@ -53,6 +57,44 @@ func DoNotReport(o options) options {
 	return o
 }
 
+// WithType sets the type of a metric. The option is ignored by registries.
+func WithType(t MetricType) Option {
+	return func(o options) options {
+		o.meta.Type = t
+		return o
+	}
+}
+
+// WithUnit sets the unit of a metric, e.g. `bytes` or `seconds`. The option
+// is ignored by registries.
+func WithUnit(unit string) Option {
+	return func(o options) options {
+		o.meta.Unit = unit
+		return o
+	}
+}
+
+// WithDescription sets the description of a metric. The option is ignored
+// by registries.
+func WithDescription(description string) Option {
+	return func(o options) options {
+		o.meta.Description = description
+		return o
+	}
+}
+
+// WithTTL removes a metric from its registry once it was not updated for
+// ttl. Any call to a setter of the metric, e.g. Set, Add or Inc, resets its
+// TTL, even if the value is unchanged. Metrics published via expvar are still
+// removed from the registry, but stay published. The option is ignored by
+// registries and Func metrics, they have no setters.
+func WithTTL(ttl time.Duration) Option {
+	return func(o options) options {
+		o.ttl = ttl
+		return o
+	}
+}
+
 func varOpts(regOpts *options, opts []Option) *options {
 	if regOpts != nil && len(opts) == 0 {
 		return regOpts
@@ -78,6 +120,18 @@ func applyOpts(in *options, opts []Option) *options {
 	for _, opt := range opts {
 		tmp = opt(tmp)
 	}
+	return tmp.withoutMeta()
+}
+
+// withoutMeta returns the options without metric metadata and TTL, so
+// registries do not pass them from one metric on to the others.
+func (o *options) withoutMeta() *options {
+	if o.meta == (Metadata{}) && o.ttl == 0 {
+		return o
+	}
+	tmp := *o
+	tmp.meta = Metadata{}
+	tmp.ttl = 0
 	return &tmp
 }
 
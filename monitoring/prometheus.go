@@ -0,0 +1,110 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package monitoring
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type prometheusSample struct {
+	meta  Metadata
+	value string
+}
+
+// WritePrometheus writes the numeric and boolean metrics of the registry in
+// the Prometheus text exposition format. Metric names are derived from the
+// dotted names by replacing unsupported characters with `_`, the unit is
+// appended as suffix if the name does not end with it already. The
+// description is written as HELP line and metrics without type are written
+// as untyped. String metrics are skipped.
+func WritePrometheus(w io.Writer, r *Registry, mode Mode) error {
+	if r == nil {
+		r = Default
+	}
+
+	samples := map[string]prometheusSample{}
+	visitMetrics(r, mode, "", func(name string, meta Metadata, value interface{}) {
+		var v string
+		switch val := value.(type) {
+		case int64:
+			v = strconv.FormatInt(val, 10)
+		case float64:
+			v = strconv.FormatFloat(val, 'g', -1, 64)
+		case bool:
+			v = "0"
+			if val {
+				v = "1"
+			}
+		default:
+			return
+		}
+		samples[prometheusName(name, meta.Unit)] = prometheusSample{meta: meta, value: v}
+	})
+
+	names := make([]string, 0, len(samples))
+	for name := range samples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+	for _, name := range names {
+		sample := samples[name]
+		if sample.meta.Description != "" {
+			fmt.Fprintf(bw, "# HELP %s %s\n", name, escapePrometheusHelp(sample.meta.Description))
+		}
+		typ := string(sample.meta.Type)
+		if typ == "" {
+			typ = "untyped"
+		}
+		fmt.Fprintf(bw, "# TYPE %s %s\n", name, typ)
+		fmt.Fprintf(bw, "%s %s\n", name, sample.value)
+	}
+	return bw.Flush()
+}
+
+func prometheusName(name, unit string) string {
+	if unit != "" && !strings.HasSuffix(name, "."+unit) && !strings.HasSuffix(name, "_"+unit) {
+		name += "_" + unit
+	}
+
+	var b strings.Builder
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_', c == ':':
+			b.WriteRune(c)
+		case c >= '0' && c <= '9':
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(c)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+func escapePrometheusHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
@@ -39,6 +39,7 @@ type Registry struct {
 type entry struct {
 	Var
 	Mode
+	meta Metadata
 }
 
 // Var interface required for every metric to implement.
@@ -95,6 +96,17 @@ func (r *Registry) NewRegistry(name string, opts ...Option) *Registry {
 	return v
 }
 
+// NewNamespace returns the sub-registry registered under name, creating it if
+// it does not exist yet. Metrics added to the namespace are reported below
+// `name.` in the parent registry. Clearing the namespace only removes its own
+// entries.
+func (r *Registry) NewNamespace(name string, opts ...Option) *Registry {
+	if reg := r.GetRegistry(name); reg != nil {
+		return reg
+	}
+	return r.NewRegistry(name, opts...)
+}
+
 // Get tries to find a registered variable by name.
 func (r *Registry) Get(name string) Var {
 	v, err := r.find(name)
@@ -169,7 +181,7 @@ func (r *Registry) addNames(names []string, v Var, opts *options) error {
 			return fmt.Errorf("name %v already used", name)
 		}
 
-		r.entries[name] = entry{v, opts.mode}
+		r.entries[name] = entry{v, opts.mode, opts.meta}
 		return nil
 	}
 
@@ -183,12 +195,12 @@ func (r *Registry) addNames(names []string, v Var, opts *options) error {
 	}
 
 	sub := NewRegistry()
-	sub.opts = opts
+	sub.opts = opts.withoutMeta()
 	if err := sub.addNames(names[1:], v, opts); err != nil {
 		return err
 	}
 
-	r.entries[name] = entry{sub, sub.opts.mode}
+	r.entries[name] = entry{sub, sub.opts.mode, Metadata{}}
 	return nil
 }
 
@@ -199,7 +211,7 @@ func (r *Registry) find(name string) (entry, error) {
 func (r *Registry) findNames(names []string) (entry, error) {
 	switch len(names) {
 	case 0:
-		return entry{r, r.opts.mode}, nil
+		return entry{r, r.opts.mode, Metadata{}}, nil
 	case 1:
 		r.mu.RLock()
 		defer r.mu.RUnlock()
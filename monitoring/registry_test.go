@@ -158,3 +158,27 @@ func TestRegistryIter(t *testing.T) {
 
 	assert.Equal(t, vars, collected)
 }
+
+func TestRegistryNamespace(t *testing.T) {
+	reg := NewRegistry()
+	NewInt(reg, "top", Report).Set(1)
+
+	outputs := reg.NewNamespace("outputs")
+	es := outputs.NewNamespace("elasticsearch")
+	NewInt(es, "events", Report).Set(2)
+	NewInt(outputs.NewNamespace("elasticsearch"), "bytes", Report).Set(3)
+	NewInt(reg.NewNamespace("pipeline"), "events", Report).Set(4)
+
+	assert.Same(t, es, reg.GetRegistry("outputs.elasticsearch"))
+	assert.Equal(t, map[string]int64{
+		"top":                          1,
+		"outputs.elasticsearch.events": 2,
+		"outputs.elasticsearch.bytes":  3,
+		"pipeline.events":              4,
+	}, CollectFlatSnapshot(reg, Full, false).Ints)
+
+	require.NoError(t, es.Clear())
+	assert.Nil(t, reg.Get("outputs.elasticsearch.events"))
+	assert.NotNil(t, reg.Get("pipeline.events"))
+	assert.NotNil(t, reg.Get("top"))
+}
@@ -0,0 +1,79 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package monitoring
+
+import (
+	"runtime"
+)
+
+// RegisterRuntime registers metrics about the Go runtime in the namespace
+// registry of r. The metrics are sampled when a snapshot is collected:
+//
+//   - goroutines: number of goroutines.
+//   - memstats: heap and memory allocation statistics, including the garbage
+//     collector statistics under memstats.gc.
+//   - fds.open: number of open file descriptors, on platforms supporting it.
+//
+// If r is nil, the metrics are registered in the Default registry.
+func RegisterRuntime(r *Registry, namespace string) {
+	if r == nil {
+		r = Default
+	}
+	if namespace != "" {
+		r = r.NewNamespace(namespace)
+	}
+
+	NewFunc(r, "goroutines", reportGoroutines, Report)
+	NewFunc(r, "memstats", reportMemStats, Report)
+	if _, err := openFDCount(); err == nil {
+		NewFunc(r, "fds", reportOpenFDs, Report)
+	}
+}
+
+func reportGoroutines(_ Mode, V Visitor) {
+	V.OnInt(int64(runtime.NumGoroutine()))
+}
+
+func reportMemStats(_ Mode, V Visitor) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	V.OnRegistryStart()
+	defer V.OnRegistryFinished()
+
+	ReportInt(V, "heap_alloc", int64(stats.HeapAlloc))
+	ReportInt(V, "heap_sys", int64(stats.HeapSys))
+	ReportInt(V, "heap_objects", int64(stats.HeapObjects))
+	ReportInt(V, "total_alloc", int64(stats.TotalAlloc))
+	ReportInt(V, "sys", int64(stats.Sys))
+	ReportNamespace(V, "gc", func() {
+		ReportInt(V, "count", int64(stats.NumGC))
+		ReportInt(V, "pause_total_ns", int64(stats.PauseTotalNs))
+		ReportInt(V, "next_gc", int64(stats.NextGC))
+		ReportFloat(V, "cpu_fraction", stats.GCCPUFraction)
+	})
+}
+
+func reportOpenFDs(_ Mode, V Visitor) {
+	V.OnRegistryStart()
+	defer V.OnRegistryFinished()
+
+	if count, err := openFDCount(); err == nil {
+		ReportInt(V, "open", count)
+	}
+}
@@ -0,0 +1,91 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+// +build !integration
+
+package monitoring
+
+import (
+	"os"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterRuntime(t *testing.T) {
+	reg := NewRegistry()
+	RegisterRuntime(reg, "runtime")
+
+	snapshot := CollectFlatSnapshot(reg, Full, false)
+	for _, name := range []string{
+		"runtime.goroutines",
+		"runtime.memstats.heap_alloc",
+		"runtime.memstats.heap_sys",
+		"runtime.memstats.heap_objects",
+		"runtime.memstats.total_alloc",
+		"runtime.memstats.sys",
+		"runtime.memstats.gc.count",
+		"runtime.memstats.gc.pause_total_ns",
+		"runtime.memstats.gc.next_gc",
+	} {
+		assert.Contains(t, snapshot.Ints, name)
+	}
+	assert.Contains(t, snapshot.Floats, "runtime.memstats.gc.cpu_fraction")
+	assert.Contains(t, CollectFlatSnapshot(reg, Reported, false).Ints, "runtime.goroutines")
+
+	const blocked = 10
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	defer close(stop)
+	wg.Add(blocked)
+	for i := 0; i < blocked; i++ {
+		go func() {
+			wg.Done()
+			<-stop
+		}()
+	}
+	wg.Wait()
+	runtime.GC()
+
+	updated := CollectFlatSnapshot(reg, Full, false)
+	assert.GreaterOrEqual(t, updated.Ints["runtime.goroutines"], int64(blocked+1))
+	assert.Greater(t, updated.Ints["runtime.memstats.gc.count"], snapshot.Ints["runtime.memstats.gc.count"])
+}
+
+func TestRegisterRuntimeOpenFDs(t *testing.T) {
+	if _, err := openFDCount(); err != nil {
+		reg := NewRegistry()
+		RegisterRuntime(reg, "")
+		assert.Nil(t, reg.Get("fds"))
+		t.Skip(err)
+	}
+
+	reg := NewRegistry()
+	RegisterRuntime(reg, "")
+
+	before := CollectFlatSnapshot(reg, Full, false).Ints["fds.open"]
+	f, err := os.Open(os.Args[0])
+	require.NoError(t, err)
+	defer f.Close()
+
+	after := CollectFlatSnapshot(reg, Full, false).Ints["fds.open"]
+	assert.Equal(t, before+1, after)
+}
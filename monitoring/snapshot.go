@@ -223,3 +223,18 @@ func (s *eventStack) pop() map[string]interface{} {
 	s.stack = s.stack[:last]
 	return event
 }
+
+// SnapshotFlat collects all metrics of the registry into a single map, joining
+// the names of nested registries and namespaces with `.`. The map can be
+// serialized as is, for example to ship all metrics as one event.
+//
+// Metrics do not carry labels. Dimensions like per-output or per-input metrics
+// are modeled as nested registries, so they are encoded into the key, e.g.
+// `output.elasticsearch.events.acked`.
+func (r *Registry) SnapshotFlat() map[string]interface{} {
+	snapshot := map[string]interface{}{}
+	r.Do(Full, func(key string, value interface{}) {
+		snapshot[key] = value
+	})
+	return snapshot
+}
@@ -104,3 +104,44 @@ func TestSnapshot(t *testing.T) {
 		assert.Equal(t, test.expected, snapshot)
 	}
 }
+
+func TestSnapshotFlat(t *testing.T) {
+	R := NewRegistry()
+	NewInt(R, "events.total").Set(3)
+	NewString(R, "version").Set("8.0.0")
+	outputs := R.NewRegistry("output")
+	es := outputs.NewRegistry("elasticsearch")
+	NewUint(es, "events.acked").Add(2)
+	NewFloat(es, "latency").Set(1.5)
+	NewBool(es, "healthy").Set(true)
+	outputs.NewRegistry("empty")
+
+	flat := R.SnapshotFlat()
+	assert.Equal(t, map[string]interface{}{
+		"events.total":                      int64(3),
+		"version":                           "8.0.0",
+		"output.elasticsearch.events.acked": int64(2),
+		"output.elasticsearch.latency":      1.5,
+		"output.elasticsearch.healthy":      true,
+	}, flat)
+
+	nested := CollectStructSnapshot(R, Full, false)
+	assert.Equal(t, flattenSnapshot("", nested), flat)
+}
+
+func flattenSnapshot(prefix string, m map[string]interface{}) map[string]interface{} {
+	flat := map[string]interface{}{}
+	for k, v := range m {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]interface{}); ok {
+			for nk, nv := range flattenSnapshot(k, nested) {
+				flat[nk] = nv
+			}
+			continue
+		}
+		flat[k] = v
+	}
+	return flat
+}
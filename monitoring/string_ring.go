@@ -0,0 +1,143 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package monitoring
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// StringRingEntry is a value recorded by a StringRing.
+type StringRingEntry struct {
+	Value     string    `json:"value"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// StringRing is a string variable keeping the last values it was set to,
+// satisfying the Var interface. It is reported as a namespace holding the
+// latest value in `latest` and the recent values, newest first, in `values`
+// with their timestamps at the same index in `timestamps`.
+type StringRing struct {
+	mu      sync.RWMutex
+	entries []StringRingEntry // ring buffer of at most depth entries
+	next    int               // index of the next entry to overwrite once full
+	now     func() time.Time
+}
+
+// NewStringRing creates and registers a new string variable keeping the last
+// depth values. A depth of less than 1 keeps only the latest value.
+//
+// Note: If the registry is configured to publish variables to expvar, the
+// variable will be available via expvars package as well, but can not be removed
+// anymore.
+func NewStringRing(r *Registry, name string, depth int, opts ...Option) *StringRing {
+	if r == nil {
+		r = Default
+	}
+	if depth < 1 {
+		depth = 1
+	}
+
+	v := &StringRing{entries: make([]StringRingEntry, 0, depth), now: time.Now}
+	addVar(r, name, opts, v, makeExpvar(func() string {
+		b, _ := json.Marshal(struct {
+			Latest  string            `json:"latest"`
+			History []StringRingEntry `json:"history"`
+		}{v.Get(), v.History()})
+		return string(b)
+	}))
+	return v
+}
+
+// Set records s as the latest value, evicting the oldest value if the ring is
+// full.
+func (v *StringRing) Set(s string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	entry := StringRingEntry{Value: s, Timestamp: v.now()}
+	if len(v.entries) < cap(v.entries) {
+		v.entries = append(v.entries, entry)
+		return
+	}
+	v.entries[v.next] = entry
+	v.next = (v.next + 1) % len(v.entries)
+}
+
+// Fail records the error message as the latest value.
+func (v *StringRing) Fail(err error) {
+	v.Set(err.Error())
+}
+
+// Get returns the latest value, or an empty string if no value was set.
+func (v *StringRing) Get() string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if len(v.entries) == 0 {
+		return ""
+	}
+	return v.entries[v.latestIndex()].Value
+}
+
+// History returns the recorded values, newest first.
+func (v *StringRing) History() []StringRingEntry {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	history := make([]StringRingEntry, len(v.entries))
+	for i := range history {
+		idx := v.latestIndex() - i
+		if idx < 0 {
+			idx += len(v.entries)
+		}
+		history[i] = v.entries[idx]
+	}
+	return history
+}
+
+// latestIndex returns the index of the latest entry, to be called with the
+// lock held and at least one entry.
+func (v *StringRing) latestIndex() int {
+	if v.next == 0 {
+		return len(v.entries) - 1
+	}
+	return v.next - 1
+}
+
+func (v *StringRing) Visit(_ Mode, vs Visitor) {
+	history := v.History()
+	values := make([]string, len(history))
+	timestamps := make([]string, len(history))
+	for i, entry := range history {
+		values[i] = entry.Value
+		timestamps[i] = entry.Timestamp.UTC().Format(TSLayout)
+	}
+
+	latest := ""
+	if len(history) > 0 {
+		latest = history[0].Value
+	}
+
+	vs.OnRegistryStart()
+	ReportString(vs, "latest", latest)
+	ReportStringSlice(vs, "values", values)
+	ReportStringSlice(vs, "timestamps", timestamps)
+	vs.OnRegistryFinished()
+}
@@ -0,0 +1,114 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+// +build !integration
+
+package monitoring
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringRingEviction(t *testing.T) {
+	r := NewRegistry()
+	v := NewStringRing(r, "last_error", 3)
+	assert.Equal(t, "", v.Get())
+	assert.Empty(t, v.History())
+
+	for i := 1; i <= 5; i++ {
+		v.Set(fmt.Sprintf("error %d", i))
+	}
+
+	assert.Equal(t, "error 5", v.Get())
+	history := v.History()
+	require.Len(t, history, 3)
+	assert.Equal(t, "error 5", history[0].Value)
+	assert.Equal(t, "error 4", history[1].Value)
+	assert.Equal(t, "error 3", history[2].Value)
+	assert.False(t, history[0].Timestamp.Before(history[2].Timestamp))
+
+	v.Fail(errors.New("error 6"))
+	assert.Equal(t, []string{"error 6", "error 5", "error 4"}, ringValues(v.History()))
+}
+
+func TestStringRingSnapshot(t *testing.T) {
+	r := NewRegistry()
+	v := NewStringRing(r, "last_error", 2)
+	ts := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	v.now = func() time.Time { return ts }
+
+	v.Set("first")
+	ts = ts.Add(time.Second)
+	v.Set("second")
+	ts = ts.Add(time.Second)
+	v.Set("third")
+
+	snapshot := CollectStructSnapshot(r, Full, false)
+	assert.Equal(t, map[string]interface{}{
+		"last_error": map[string]interface{}{
+			"latest":     "third",
+			"values":     []string{"third", "second"},
+			"timestamps": []string{"2022-03-01T10:00:02.000Z", "2022-03-01T10:00:01.000Z"},
+		},
+	}, snapshot)
+
+	flat := CollectFlatSnapshot(r, Full, false)
+	assert.Equal(t, "third", flat.Strings["last_error.latest"])
+	assert.Equal(t, []string{"third", "second"}, flat.StringSlices["last_error.values"])
+}
+
+func TestStringRingMinimumDepth(t *testing.T) {
+	v := NewStringRing(NewRegistry(), "value", 0)
+	v.Set("a")
+	v.Set("b")
+	assert.Equal(t, []string{"b"}, ringValues(v.History()))
+}
+
+func TestStringRingConcurrentSet(t *testing.T) {
+	v := NewStringRing(NewRegistry(), "value", 10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				v.Set(fmt.Sprintf("%d-%d", i, j))
+				_ = v.History()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(t, v.History(), 10)
+}
+
+func ringValues(entries []StringRingEntry) []string {
+	values := make([]string, len(entries))
+	for i, entry := range entries {
+		values[i] = entry.Value
+	}
+	return values
+}
@@ -0,0 +1,251 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+const (
+	// summaryCompression bounds the number of centroids kept by a Summary,
+	// trading memory for accuracy. At most summaryCompression centroids are
+	// kept.
+	summaryCompression = 100
+
+	// summaryBufferSize is the number of observations buffered before they
+	// are merged into the centroids.
+	summaryBufferSize = 5 * summaryCompression
+)
+
+// Summary is a variable estimating quantiles of the observed values,
+// satisfying the Var interface. The quantiles are estimated with a merging
+// t-digest using bounded memory, the estimation is more accurate for the
+// extreme quantiles than for the median. It is reported as a namespace
+// holding the number of observations in `count`, their sum in `sum` and the
+// configured quantiles named by percentile, e.g. `p50`, `p99` or `p99.9`.
+// Quantiles are not reported until a value has been observed.
+type Summary struct {
+	mu        sync.Mutex
+	quantiles []float64
+	digest    tdigest
+	sum       float64
+}
+
+// NewSummary creates and registers a new summary reporting the given
+// quantiles, between 0 and 1. NewSummary panics if a quantile is out of range.
+//
+// Note: If the registry is configured to publish variables to expvar, the
+// variable will be available via expvars package as well, but can not be removed
+// anymore.
+func NewSummary(r *Registry, name string, quantiles []float64, opts ...Option) *Summary {
+	if r == nil {
+		r = Default
+	}
+	for _, q := range quantiles {
+		if !(q >= 0 && q <= 1) {
+			panic(fmt.Sprintf("summary %v: quantile %v out of range [0, 1]", name, q))
+		}
+	}
+
+	v := &Summary{quantiles: append([]float64(nil), quantiles...)}
+	addVar(r, name, opts, v, makeExpvar(func() string {
+		b, _ := json.Marshal(v.snapshot())
+		return string(b)
+	}))
+	return v
+}
+
+// Observe records value. NaN values are ignored.
+func (v *Summary) Observe(value float64) {
+	if math.IsNaN(value) {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.sum += value
+	v.digest.add(value)
+}
+
+// Count returns the number of observed values.
+func (v *Summary) Count() int64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return int64(v.digest.count)
+}
+
+// Sum returns the sum of the observed values.
+func (v *Summary) Sum() float64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.sum
+}
+
+// Quantile returns the estimated q quantile of the observed values, NaN if no
+// value has been observed.
+func (v *Summary) Quantile(q float64) float64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.digest.quantile(q)
+}
+
+func (v *Summary) Visit(_ Mode, vs Visitor) {
+	s := v.snapshot()
+
+	vs.OnRegistryStart()
+	defer vs.OnRegistryFinished()
+
+	ReportInt(vs, "count", s["count"].(int64))
+	ReportFloat(vs, "sum", s["sum"].(float64))
+	for _, q := range v.quantiles {
+		if value, ok := s[quantileName(q)]; ok {
+			ReportFloat(vs, quantileName(q), value.(float64))
+		}
+	}
+}
+
+// snapshot returns the values reported by the summary.
+func (v *Summary) snapshot() map[string]interface{} {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	s := map[string]interface{}{
+		"count": int64(v.digest.count),
+		"sum":   v.sum,
+	}
+	if v.digest.count > 0 {
+		for _, q := range v.quantiles {
+			s[quantileName(q)] = v.digest.quantile(q)
+		}
+	}
+	return s
+}
+
+// quantileName returns the name of the quantile q as a percentile, e.g. p99.
+func quantileName(q float64) string {
+	return "p" + strconv.FormatFloat(math.Round(q*1e6)/1e4, 'f', -1, 64)
+}
+
+type centroid struct {
+	mean, weight float64
+}
+
+// tdigest is a merging t-digest, see "Computing Extremely Accurate Quantiles
+// Using t-Digests" by T. Dunning and O. Ertl. Values are buffered and merged
+// into centroids whose weight is bounded by a function of their quantile,
+// centroids close to the tails are kept small.
+type tdigest struct {
+	centroids []centroid // sorted by mean
+	buffer    []centroid
+	count     float64
+	min, max  float64
+}
+
+func (d *tdigest) add(value float64) {
+	if d.count == 0 || value < d.min {
+		d.min = value
+	}
+	if d.count == 0 || value > d.max {
+		d.max = value
+	}
+	d.count++
+
+	if d.buffer == nil {
+		d.buffer = make([]centroid, 0, summaryBufferSize)
+	}
+	d.buffer = append(d.buffer, centroid{mean: value, weight: 1})
+	if len(d.buffer) == cap(d.buffer) {
+		d.merge()
+	}
+}
+
+// merge merges the buffered values into the centroids.
+func (d *tdigest) merge() {
+	if len(d.buffer) == 0 {
+		return
+	}
+
+	all := append(d.buffer, d.centroids...)
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+
+	merged := make([]centroid, 0, len(d.centroids)+1)
+	current := all[0]
+	before := 0.0 // weight of the centroids before current
+	for _, c := range all[1:] {
+		proposed := current.weight + c.weight
+		if tdigestScale((before+proposed)/d.count)-tdigestScale(before/d.count) <= 1 {
+			current.mean += (c.mean - current.mean) * c.weight / proposed
+			current.weight = proposed
+			continue
+		}
+		before += current.weight
+		merged = append(merged, current)
+		current = c
+	}
+	d.centroids = append(merged, current)
+	d.buffer = d.buffer[:0]
+}
+
+// tdigestScale is the k1 scale function of the t-digest, a centroid may span
+// at most 1 in scale. The scale is steeper close to the tails, keeping their
+// centroids small.
+func tdigestScale(q float64) float64 {
+	return summaryCompression / (2 * math.Pi) * math.Asin(2*q-1)
+}
+
+// quantile returns the estimated q quantile, interpolating between the
+// centers of the centroids.
+func (d *tdigest) quantile(q float64) float64 {
+	if d.count == 0 {
+		return math.NaN()
+	}
+	d.merge()
+
+	switch {
+	case q <= 0:
+		return d.min
+	case q >= 1:
+		return d.max
+	}
+
+	target := q * d.count
+	first := d.centroids[0]
+	if target < first.weight/2 {
+		return d.min + (first.mean-d.min)*target/(first.weight/2)
+	}
+
+	before := 0.0
+	for i := 0; i < len(d.centroids)-1; i++ {
+		left, right := d.centroids[i], d.centroids[i+1]
+		leftCenter := before + left.weight/2
+		rightCenter := before + left.weight + right.weight/2
+		if target <= rightCenter {
+			return left.mean + (right.mean-left.mean)*(target-leftCenter)/(rightCenter-leftCenter)
+		}
+		before += left.weight
+	}
+
+	last := d.centroids[len(d.centroids)-1]
+	lastCenter := d.count - last.weight/2
+	return last.mean + (d.max-last.mean)*(target-lastCenter)/(d.count-lastCenter)
+}
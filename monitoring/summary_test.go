@@ -0,0 +1,152 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+// +build !integration
+
+package monitoring
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummaryAccuracy(t *testing.T) {
+	const n = 100000
+	rng := rand.New(rand.NewSource(42))
+
+	distributions := map[string]func() float64{
+		"uniform":     func() float64 { return rng.Float64() * 1000 },
+		"normal":      func() float64 { return rng.NormFloat64()*50 + 200 },
+		"exponential": func() float64 { return rng.ExpFloat64() * 10 },
+		"lognormal":   func() float64 { return math.Exp(rng.NormFloat64()) },
+	}
+	// maximum difference between the requested quantile and the rank of the
+	// estimated value
+	quantiles := map[float64]float64{
+		0.5:   0.01,
+		0.9:   0.005,
+		0.95:  0.003,
+		0.99:  0.001,
+		0.999: 0.0005,
+	}
+
+	for name, next := range distributions {
+		t.Run(name, func(t *testing.T) {
+			v := NewSummary(NewRegistry(), "latency", []float64{0.5, 0.99})
+			values := make([]float64, n)
+			for i := range values {
+				values[i] = next()
+				v.Observe(values[i])
+			}
+			sort.Float64s(values)
+
+			for q, maxErr := range quantiles {
+				estimate := v.Quantile(q)
+				rank := float64(sort.SearchFloat64s(values, estimate)) / n
+				assert.InDelta(t, q, rank, maxErr, "quantile %v: estimated %v, exact %v", q, estimate, values[int(q*n)])
+			}
+			assert.Equal(t, values[0], v.Quantile(0))
+			assert.Equal(t, values[n-1], v.Quantile(1))
+			assert.LessOrEqual(t, len(v.digest.centroids), summaryCompression)
+		})
+	}
+}
+
+func TestSummarySmall(t *testing.T) {
+	v := NewSummary(NewRegistry(), "latency", []float64{0.5})
+	assert.True(t, math.IsNaN(v.Quantile(0.5)))
+
+	v.Observe(7)
+	assert.Equal(t, 7.0, v.Quantile(0.5))
+
+	for _, value := range []float64{1, 2, 3, 4, 5, 6, 8, 9, 10, 11, 12, 13} {
+		v.Observe(value)
+	}
+	v.Observe(math.NaN())
+	assert.EqualValues(t, 13, v.Count())
+	assert.Equal(t, 91.0, v.Sum())
+	assert.InDelta(t, 7, v.Quantile(0.5), 0.5)
+}
+
+func TestSummaryConcurrentObserve(t *testing.T) {
+	const (
+		workers = 8
+		values  = 10000
+	)
+
+	v := NewSummary(NewRegistry(), "latency", []float64{0.5})
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < values; j++ {
+				v.Observe(float64(j))
+				if j%1000 == 0 {
+					v.Quantile(0.5)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, workers*values, v.Count())
+	assert.InDelta(t, values/2, v.Quantile(0.5), values/100)
+}
+
+func TestSummarySnapshot(t *testing.T) {
+	reg := NewRegistry()
+	v := NewSummary(reg, "latency", []float64{0.5, 0.95, 0.999})
+
+	snapshot := CollectStructSnapshot(reg, Full, false)
+	assert.Equal(t, map[string]interface{}{"count": int64(0), "sum": 0.0}, snapshot["latency"])
+
+	for i := 1; i <= 1000; i++ {
+		v.Observe(float64(i))
+	}
+	flat := CollectFlatSnapshot(reg, Full, false)
+	assert.EqualValues(t, 1000, flat.Ints["latency.count"])
+	assert.Equal(t, 500500.0, flat.Floats["latency.sum"])
+	assert.InDelta(t, 500, flat.Floats["latency.p50"], 10)
+	assert.InDelta(t, 950, flat.Floats["latency.p95"], 5)
+	assert.InDelta(t, 999, flat.Floats["latency.p99.9"], 1)
+
+	b, err := json.Marshal(v.snapshot())
+	require.NoError(t, err)
+	var decoded map[string]float64
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, 1000.0, decoded["count"])
+}
+
+func TestNewSummaryInvalidQuantile(t *testing.T) {
+	assert.Panics(t, func() { NewSummary(NewRegistry(), "latency", []float64{1.5}) })
+	assert.Panics(t, func() { NewSummary(NewRegistry(), "latency", []float64{math.NaN()}) })
+}
+
+func TestQuantileName(t *testing.T) {
+	for q, name := range map[float64]string{0: "p0", 0.5: "p50", 0.95: "p95", 0.99: "p99", 0.999: "p99.9", 1: "p100"} {
+		assert.Equal(t, name, quantileName(q))
+	}
+}
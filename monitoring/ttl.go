@@ -0,0 +1,156 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package monitoring
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// metricsReaper removes the metrics registered with WithTTL.
+var metricsReaper = &reaper{}
+
+// reaper periodically removes the tracked metrics that were not updated
+// within their TTL. Its goroutine is started with the first tracked metric
+// and stops once no metrics are tracked.
+type reaper struct {
+	mu      sync.Mutex
+	entries map[*ttlEntry]struct{}
+	running bool
+	wake    chan struct{}
+}
+
+type ttlEntry struct {
+	updated int64 // Unix time in nanoseconds of the last update, accessed atomically.
+	r       *Registry
+	name    string
+	v       Var
+	ttl     time.Duration
+}
+
+// ttlUpdates is embedded by the metrics with setters, the setters call
+// update to reset the TTL of the metric. Only the metrics registered
+// with WithTTL read the clock.
+type ttlUpdates struct {
+	entry *ttlEntry
+}
+
+func (u *ttlUpdates) update() {
+	if u.entry != nil {
+		atomic.StoreInt64(&u.entry.updated, time.Now().UnixNano())
+	}
+}
+
+func (u *ttlUpdates) setTTLEntry(e *ttlEntry) { u.entry = e }
+
+// ttlVar is implemented by the metrics supporting WithTTL.
+type ttlVar interface {
+	Var
+	setTTLEntry(e *ttlEntry)
+}
+
+func (rp *reaper) track(r *Registry, name string, v ttlVar, ttl time.Duration) {
+	e := &ttlEntry{r: r, name: name, v: v, ttl: ttl, updated: time.Now().UnixNano()}
+	v.setTTLEntry(e)
+
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	if rp.entries == nil {
+		rp.entries = map[*ttlEntry]struct{}{}
+		rp.wake = make(chan struct{}, 1)
+	}
+	rp.entries[e] = struct{}{}
+
+	if !rp.running {
+		rp.running = true
+		go rp.run()
+		return
+	}
+
+	// Recompute the interval, the new entry might have a shorter TTL.
+	select {
+	case rp.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (rp *reaper) run() {
+	for {
+		interval := rp.interval()
+		if interval == 0 {
+			return
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+			rp.reap(time.Now())
+		case <-rp.wake:
+			timer.Stop()
+		}
+	}
+}
+
+// interval returns half of the shortest TTL of the tracked entries, at least
+// 1ms. If there are none, the reaper stops and 0 is returned.
+func (rp *reaper) interval() time.Duration {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	var interval time.Duration
+	for e := range rp.entries {
+		if interval == 0 || e.ttl < interval {
+			interval = e.ttl
+		}
+	}
+	if interval == 0 {
+		rp.running = false
+		return 0
+	}
+	if interval < 2*time.Millisecond {
+		return time.Millisecond
+	}
+	return interval / 2
+}
+
+func (rp *reaper) reap(now time.Time) {
+	var expired []*ttlEntry
+
+	rp.mu.Lock()
+	for e := range rp.entries {
+		// Stop tracking metrics that were removed or replaced.
+		if e.r.Get(e.name) != e.v {
+			delete(rp.entries, e)
+			continue
+		}
+
+		if now.Sub(time.Unix(0, atomic.LoadInt64(&e.updated))) >= e.ttl {
+			delete(rp.entries, e)
+			expired = append(expired, e)
+		}
+	}
+	rp.mu.Unlock()
+
+	for _, e := range expired {
+		if e.r.Get(e.name) == e.v {
+			e.r.Remove(e.name)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+// +build !integration
+
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricTTL(t *testing.T) {
+	const ttl = 50 * time.Millisecond
+
+	t.Run("expires", func(t *testing.T) {
+		r := NewRegistry()
+		NewInt(r, "conn.a.bytes", WithTTL(ttl)).Set(1)
+		NewInt(r, "total").Set(1)
+
+		assert.Eventually(t, func() bool {
+			return r.Get("conn.a.bytes") == nil
+		}, time.Second, 5*time.Millisecond)
+		assert.Nil(t, r.Get("conn"), "empty sub-registries are removed")
+		assert.NotNil(t, r.Get("total"), "metrics without TTL are kept")
+	})
+
+	t.Run("updates reset the TTL", func(t *testing.T) {
+		r := NewRegistry()
+		v := NewUint(r, "conn.b.bytes", WithTTL(ttl))
+
+		for deadline := time.Now().Add(4 * ttl); time.Now().Before(deadline); {
+			v.Inc()
+			time.Sleep(ttl / 5)
+		}
+		assert.Equal(t, v, r.Get("conn.b.bytes"))
+
+		assert.Eventually(t, func() bool {
+			return r.Get("conn.b.bytes") == nil
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("updates with the same value reset the TTL", func(t *testing.T) {
+		r := NewRegistry()
+		v := NewString(r, "conn.d.state", WithTTL(ttl))
+
+		for deadline := time.Now().Add(4 * ttl); time.Now().Before(deadline); {
+			v.Set("connected")
+			time.Sleep(ttl / 5)
+		}
+		assert.Equal(t, v, r.Get("conn.d.state"))
+	})
+
+	t.Run("reading does not reset the TTL", func(t *testing.T) {
+		r := NewRegistry()
+		v := NewInt(r, "conn.e.bytes", WithTTL(ttl))
+		v.Set(1)
+
+		assert.Eventually(t, func() bool {
+			_ = v.Get()
+			_ = CollectFlatSnapshot(r, Full, false)
+			return r.Get("conn.e.bytes") == nil
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("replaced metric is kept", func(t *testing.T) {
+		r := NewRegistry()
+		NewInt(r, "conn.c", WithTTL(ttl))
+		r.Remove("conn.c")
+		v := NewInt(r, "conn.c")
+
+		time.Sleep(3 * ttl)
+		assert.Equal(t, v, r.Get("conn.c"))
+	})
+
+	t.Run("registries and funcs ignore the TTL", func(t *testing.T) {
+		r := NewRegistry()
+		sub := r.NewRegistry("sub", WithTTL(ttl))
+		NewInt(sub, "x")
+		NewFunc(r, "f", func(_ Mode, vs Visitor) { vs.OnInt(1) }, WithTTL(ttl))
+
+		time.Sleep(3 * ttl)
+		assert.NotNil(t, r.Get("sub.x"))
+		assert.NotNil(t, r.Get("f"))
+	})
+}
@@ -0,0 +1,120 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package service
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotSupported is returned by Install and Uninstall on platforms without
+// service management support.
+var ErrNotSupported = errors.New("service management is not supported on this platform")
+
+// StartType defines when the service is started.
+type StartType int
+
+const (
+	// StartManual services must be started manually.
+	StartManual StartType = iota
+	// StartAutomatic services are started when the system boots.
+	StartAutomatic
+	// StartDelayed services are started shortly after the other automatic
+	// services when the system boots.
+	StartDelayed
+	// StartDisabled services cannot be started.
+	StartDisabled
+)
+
+// RecoveryActionType is the action performed when the service fails.
+type RecoveryActionType int
+
+const (
+	// RecoveryNoAction does nothing when the service fails.
+	RecoveryNoAction RecoveryActionType = iota
+	// RecoveryRestart restarts the service.
+	RecoveryRestart
+	// RecoveryReboot reboots the computer.
+	RecoveryReboot
+)
+
+// RecoveryAction is performed after Delay when the service fails.
+type RecoveryAction struct {
+	Type  RecoveryActionType
+	Delay time.Duration
+}
+
+// RecoveryConfig configures the actions performed when the service
+// terminates without reporting it stopped. Actions[i] is performed on the
+// (i+1)th failure, the last action is repeated on subsequent failures. The
+// failure count is reset after ResetPeriod without failures, a zero
+// ResetPeriod never resets it.
+type RecoveryConfig struct {
+	Actions     []RecoveryAction
+	ResetPeriod time.Duration
+}
+
+// RestartOnFailure returns a RecoveryConfig restarting the service after
+// delay on every failure, resetting the failure count after resetPeriod.
+func RestartOnFailure(delay, resetPeriod time.Duration) RecoveryConfig {
+	return RecoveryConfig{
+		Actions:     []RecoveryAction{{Type: RecoveryRestart, Delay: delay}},
+		ResetPeriod: resetPeriod,
+	}
+}
+
+// InstallConfig describes a service registered by Install.
+type InstallConfig struct {
+	// Name is the name the service is registered with, required.
+	Name        string
+	DisplayName string
+	Description string
+
+	// Executable is the path of the service binary, it defaults to the
+	// executable of the current process.
+	Executable string
+	// Arguments are passed to Executable when the service is started.
+	Arguments []string
+
+	StartType StartType
+	// Dependencies are the names of the services that must be running
+	// before this service is started.
+	Dependencies []string
+	Recovery     RecoveryConfig
+}
+
+func (c *InstallConfig) validate() error {
+	if c.Name == "" {
+		return errors.New("service name is required")
+	}
+	if c.StartType < StartManual || c.StartType > StartDisabled {
+		return errors.New("invalid service start type")
+	}
+	for _, action := range c.Recovery.Actions {
+		if action.Type < RecoveryNoAction || action.Type > RecoveryReboot {
+			return errors.New("invalid service recovery action")
+		}
+		if action.Delay < 0 {
+			return errors.New("service recovery delay cannot be negative")
+		}
+	}
+	if c.Recovery.ResetPeriod < 0 {
+		return errors.New("service recovery reset period cannot be negative")
+	}
+	return nil
+}
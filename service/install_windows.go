@@ -0,0 +1,134 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package service
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// Install registers the service described by cfg with the Windows service
+// control manager. It fails if a service with the same name exists.
+func Install(cfg InstallConfig) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+
+	exe := cfg.Executable
+	if exe == "" {
+		var err error
+		if exe, err = os.Executable(); err != nil {
+			return fmt.Errorf("failed to get service executable: %w", err)
+		}
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the service control manager: %w", err)
+	}
+	defer m.Disconnect() //nolint:errcheck // nothing to do on failure
+
+	if s, err := m.OpenService(cfg.Name); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s already exists", cfg.Name)
+	}
+
+	s, err := m.CreateService(cfg.Name, exe, toMgrConfig(cfg), cfg.Arguments...)
+	if err != nil {
+		return fmt.Errorf("failed to create service %s: %w", cfg.Name, err)
+	}
+	defer s.Close()
+
+	if len(cfg.Recovery.Actions) > 0 {
+		err := s.SetRecoveryActions(toMgrRecoveryActions(cfg.Recovery.Actions), resetPeriodSeconds(cfg.Recovery.ResetPeriod))
+		if err != nil {
+			_ = s.Delete()
+			return fmt.Errorf("failed to set recovery actions of service %s: %w", cfg.Name, err)
+		}
+	}
+	return nil
+}
+
+// Uninstall removes the service name from the Windows service control
+// manager. A running service is removed once it stops.
+func Uninstall(name string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the service control manager: %w", err)
+	}
+	defer m.Disconnect() //nolint:errcheck // nothing to do on failure
+
+	s, err := m.OpenService(name)
+	if err != nil {
+		return fmt.Errorf("failed to open service %s: %w", name, err)
+	}
+	defer s.Close()
+
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("failed to delete service %s: %w", name, err)
+	}
+	return nil
+}
+
+func toMgrConfig(cfg InstallConfig) mgr.Config {
+	c := mgr.Config{
+		DisplayName:  cfg.DisplayName,
+		Description:  cfg.Description,
+		Dependencies: cfg.Dependencies,
+		ErrorControl: mgr.ErrorNormal,
+	}
+	switch cfg.StartType {
+	case StartManual:
+		c.StartType = mgr.StartManual
+	case StartAutomatic:
+		c.StartType = mgr.StartAutomatic
+	case StartDelayed:
+		c.StartType = mgr.StartAutomatic
+		c.DelayedAutoStart = true
+	case StartDisabled:
+		c.StartType = mgr.StartDisabled
+	}
+	return c
+}
+
+func toMgrRecoveryActions(actions []RecoveryAction) []mgr.RecoveryAction {
+	out := make([]mgr.RecoveryAction, len(actions))
+	for i, action := range actions {
+		out[i].Delay = action.Delay
+		switch action.Type {
+		case RecoveryNoAction:
+			out[i].Type = mgr.NoAction
+		case RecoveryRestart:
+			out[i].Type = mgr.ServiceRestart
+		case RecoveryReboot:
+			out[i].Type = mgr.ComputerReboot
+		}
+	}
+	return out
+}
+
+func resetPeriodSeconds(period time.Duration) uint32 {
+	if period == 0 {
+		return windows.INFINITE
+	}
+	return uint32(period / time.Second)
+}
@@ -0,0 +1,106 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build windows
+// +build windows
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+func TestToMgrConfig(t *testing.T) {
+	c := toMgrConfig(InstallConfig{
+		Name:         "test",
+		DisplayName:  "Test service",
+		Description:  "A test service",
+		StartType:    StartDelayed,
+		Dependencies: []string{"Tcpip"},
+	})
+	assert.Equal(t, uint32(mgr.StartAutomatic), c.StartType)
+	assert.True(t, c.DelayedAutoStart)
+	assert.Equal(t, "Test service", c.DisplayName)
+	assert.Equal(t, []string{"Tcpip"}, c.Dependencies)
+
+	assert.Equal(t, uint32(mgr.StartDisabled), toMgrConfig(InstallConfig{StartType: StartDisabled}).StartType)
+	assert.Equal(t, uint32(mgr.StartManual), toMgrConfig(InstallConfig{}).StartType)
+}
+
+func TestToMgrRecoveryActions(t *testing.T) {
+	actions := toMgrRecoveryActions([]RecoveryAction{
+		{Type: RecoveryRestart, Delay: time.Second},
+		{Type: RecoveryRestart, Delay: time.Minute},
+		{Type: RecoveryNoAction},
+	})
+	assert.Equal(t, []mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: time.Second},
+		{Type: mgr.ServiceRestart, Delay: time.Minute},
+		{Type: mgr.NoAction},
+	}, actions)
+
+	assert.Equal(t, uint32(windows.INFINITE), resetPeriodSeconds(0))
+	assert.Equal(t, uint32(86400), resetPeriodSeconds(24*time.Hour))
+}
+
+func TestInstallUninstall(t *testing.T) {
+	m, err := mgr.Connect()
+	if err != nil {
+		t.Skipf("service control manager not accessible: %v", err)
+	}
+	m.Disconnect() //nolint:errcheck // test
+
+	name := fmt.Sprintf("elastic-agent-libs-test-%d", os.Getpid())
+	err = Install(InstallConfig{
+		Name:        name,
+		DisplayName: "elastic-agent-libs test service",
+		StartType:   StartManual,
+		Recovery:    RestartOnFailure(5*time.Second, 24*time.Hour),
+	})
+	if err != nil && errors.Is(err, windows.ERROR_ACCESS_DENIED) {
+		t.Skipf("insufficient privileges to install a service: %v", err)
+	}
+	require.NoError(t, err)
+	defer func() {
+		_ = Uninstall(name)
+	}()
+
+	assert.Error(t, Install(InstallConfig{Name: name}), "installing an existing service must fail")
+
+	m, err = mgr.Connect()
+	require.NoError(t, err)
+	defer m.Disconnect() //nolint:errcheck // test
+	s, err := m.OpenService(name)
+	require.NoError(t, err)
+	actions, err := s.RecoveryActions()
+	s.Close()
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	assert.Equal(t, mgr.ServiceRestart, actions[0].Type)
+	assert.Equal(t, 5*time.Second, actions[0].Delay)
+
+	require.NoError(t, Uninstall(name))
+}
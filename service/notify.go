@@ -0,0 +1,85 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+// Notifier reports the state of the service to the service manager, e.g.
+// systemd. Consumers call Ready once the service is fully started.
+type Notifier interface {
+	// Ready reports that the service finished starting up.
+	Ready() error
+
+	// Stopping reports that the service is shutting down.
+	Stopping() error
+
+	// Watchdog sends a keep-alive ping to the service manager.
+	Watchdog() error
+
+	// WatchdogInterval returns the interval at which the service manager
+	// expects keep-alive pings. It returns 0 if the watchdog is disabled.
+	WatchdogInterval() time.Duration
+}
+
+// NewNotifier returns the Notifier for the service manager the process runs
+// under. If there is none, a no-op Notifier is returned.
+func NewNotifier() Notifier {
+	if n := newSystemdNotifier(); n != nil {
+		return n
+	}
+	return nopNotifier{}
+}
+
+// StartWatchdog pings the watchdog of n at half the interval requested by the
+// service manager until ctx is cancelled. It does nothing if the watchdog is
+// disabled.
+func StartWatchdog(ctx context.Context, n Notifier) {
+	interval := n.WatchdogInterval() / 2
+	if interval <= 0 {
+		return
+	}
+
+	logger := logp.NewLogger("service")
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := n.Watchdog(); err != nil {
+					logger.Warnf("Failed to ping service watchdog: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+type nopNotifier struct{}
+
+func (nopNotifier) Ready() error                    { return nil }
+func (nopNotifier) Stopping() error                 { return nil }
+func (nopNotifier) Watchdog() error                 { return nil }
+func (nopNotifier) WatchdogInterval() time.Duration { return 0 }
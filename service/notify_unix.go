@@ -0,0 +1,85 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !windows
+// +build !windows
+
+package service
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// systemdNotifier implements the sd_notify protocol: state updates are sent
+// as datagrams to the unix socket named in NOTIFY_SOCKET.
+type systemdNotifier struct {
+	addr     *net.UnixAddr
+	watchdog time.Duration
+}
+
+func newSystemdNotifier() Notifier {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+
+	// A leading '@' denotes a socket in the abstract namespace.
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+
+	return &systemdNotifier{
+		addr:     &net.UnixAddr{Name: socket, Net: "unixgram"},
+		watchdog: systemdWatchdogInterval(),
+	}
+}
+
+func (n *systemdNotifier) Ready() error    { return n.notify("READY=1") }
+func (n *systemdNotifier) Stopping() error { return n.notify("STOPPING=1") }
+func (n *systemdNotifier) Watchdog() error { return n.notify("WATCHDOG=1") }
+
+func (n *systemdNotifier) WatchdogInterval() time.Duration {
+	return n.watchdog
+}
+
+func (n *systemdNotifier) notify(state string) error {
+	conn, err := net.DialUnix(n.addr.Net, nil, n.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// systemdWatchdogInterval reads the watchdog interval requested by systemd.
+// WATCHDOG_PID, if set, must match the current process.
+func systemdWatchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
@@ -0,0 +1,86 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !windows
+// +build !windows
+
+package service
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemdNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	t.Setenv("NOTIFY_SOCKET", path)
+	t.Setenv("WATCHDOG_USEC", "20000")
+
+	n := NewNotifier()
+	require.IsType(t, &systemdNotifier{}, n)
+	assert.Equal(t, 20*time.Millisecond, n.WatchdogInterval())
+
+	read := func() string {
+		buf := make([]byte, 64)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		n, err := conn.Read(buf)
+		require.NoError(t, err)
+		return string(buf[:n])
+	}
+
+	require.NoError(t, n.Ready())
+	assert.Equal(t, "READY=1", read())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	StartWatchdog(ctx, n)
+	assert.Equal(t, "WATCHDOG=1", read())
+	assert.Equal(t, "WATCHDOG=1", read())
+	cancel()
+
+	require.NoError(t, n.Stopping())
+	// Skip the pings sent before the watchdog stopped.
+	msg := read()
+	for msg == "WATCHDOG=1" {
+		msg = read()
+	}
+	assert.Equal(t, "STOPPING=1", msg)
+}
+
+func TestNotifierWithoutServiceManager(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	n := NewNotifier()
+	assert.Equal(t, nopNotifier{}, n)
+	assert.NoError(t, n.Ready())
+	assert.Zero(t, n.WatchdogInterval())
+}
+
+func TestSystemdWatchdogPID(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "1000000")
+	t.Setenv("WATCHDOG_PID", "1")
+	assert.Zero(t, systemdWatchdogInterval())
+}
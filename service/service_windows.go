@@ -44,8 +44,12 @@ var serviceInstance = &beatService{
 // occur in the environment or runtime that may affect the beat.
 func (m *beatService) Execute(args []string, r <-chan svc.ChangeRequest, changes chan<- svc.Status) (ssec bool, errno uint32) {
 	const cmdsAccepted = svc.AcceptStop | svc.AcceptShutdown
-	changes <- svc.Status{State: svc.StartPending}
-	changes <- svc.Status{State: svc.Running, Accepts: cmdsAccepted}
+	report := func(s svc.Status) {
+		changes <- s
+		setState(State(s.State))
+	}
+	report(svc.Status{State: svc.StartPending})
+	report(svc.Status{State: svc.Running, Accepts: cmdsAccepted})
 
 loop:
 	for c := range r {
@@ -61,7 +65,7 @@ loop:
 			logp.Err("Unexpected control request: $%d. Ignored.", c)
 		}
 	}
-	changes <- svc.Status{State: svc.StopPending}
+	report(svc.Status{State: svc.StopPending})
 	m.stopCallback()
 	// Block until notifyWindowsServiceStopped below is called. This is required
 	// as the windows/svc package will transition the service to STOPPED state
@@ -0,0 +1,49 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/windows/svc"
+)
+
+func TestExecuteReportsState(t *testing.T) {
+	defer setState(CurrentState())
+
+	s := &beatService{done: make(chan struct{})}
+	s.stopCallback = func() {
+		assert.Equal(t, StateStopPending, CurrentState())
+		s.stop()
+	}
+
+	requests := make(chan svc.ChangeRequest)
+	changes := make(chan svc.Status)
+	go s.Execute(nil, requests, changes)
+
+	assert.Equal(t, svc.StartPending, (<-changes).State)
+	assert.Equal(t, svc.Running, (<-changes).State)
+	requests <- svc.ChangeRequest{Cmd: svc.Interrogate, CurrentStatus: svc.Status{State: svc.Running}}
+	<-changes
+	<-changes
+	assert.Equal(t, StateRunning, CurrentState())
+
+	requests <- svc.ChangeRequest{Cmd: svc.Stop}
+	assert.Equal(t, svc.StopPending, (<-changes).State)
+}
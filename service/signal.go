@@ -0,0 +1,82 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package service
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+// signalHandlers fans out the signals received by a single signal loop to
+// all the handlers registered for them.
+type signalHandlers struct {
+	mu       sync.Mutex
+	handlers map[os.Signal][]func()
+	sigc     chan os.Signal
+	start    sync.Once
+}
+
+var defaultSignalHandlers = newSignalHandlers()
+
+func newSignalHandlers() *signalHandlers {
+	return &signalHandlers{
+		handlers: map[os.Signal][]func(){},
+		sigc:     make(chan os.Signal, 1),
+	}
+}
+
+// OnSignal registers fn to be called every time the process receives sig.
+// Any number of handlers can be registered for the same signal, they are
+// called in registration order from a single signal loop, started with the
+// first registration, so handlers should not block. Signals that can not be
+// delivered on the current platform, like SIGUSR1 on Windows, are ignored.
+func OnSignal(sig os.Signal, fn func()) {
+	defaultSignalHandlers.add(sig, fn)
+}
+
+func (s *signalHandlers) add(sig os.Signal, fn func()) {
+	if !isSupportedSignal(sig) {
+		logp.NewLogger("service").Debugf("Ignoring handler for signal %v, it is not supported on this platform", sig)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.handlers[sig]) == 0 {
+		signal.Notify(s.sigc, sig)
+	}
+	s.handlers[sig] = append(s.handlers[sig], fn)
+
+	s.start.Do(func() { go s.run() })
+}
+
+func (s *signalHandlers) run() {
+	for sig := range s.sigc {
+		s.mu.Lock()
+		handlers := s.handlers[sig]
+		s.mu.Unlock()
+
+		for _, fn := range handlers {
+			fn()
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !windows
+// +build !windows
+
+package service
+
+import (
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnSignal(t *testing.T) {
+	s := newSignalHandlers()
+	defer signal.Stop(s.sigc)
+
+	calls := make(chan string, 2)
+	s.add(syscall.SIGUSR1, func() { calls <- "first" })
+	s.add(syscall.SIGUSR1, func() { calls <- "second" })
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+
+	for _, expected := range []string{"first", "second"} {
+		select {
+		case name := <-calls:
+			assert.Equal(t, expected, name)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s signal handler was not called", expected)
+		}
+	}
+}
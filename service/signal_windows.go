@@ -0,0 +1,30 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package service
+
+import (
+	"os"
+	"syscall"
+)
+
+// isSupportedSignal reports whether sig can be received. Windows only
+// delivers Ctrl-C as os.Interrupt and console close, logoff and shutdown
+// events as SIGTERM.
+func isSupportedSignal(sig os.Signal) bool {
+	return sig == os.Interrupt || sig == syscall.SIGTERM
+}
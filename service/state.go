@@ -0,0 +1,68 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package service
+
+import "sync/atomic"
+
+// State is the state of the service as last reported to the Windows service
+// control manager. Its values match those of svc.State.
+type State uint32
+
+const (
+	// StateUnknown is reported when the process does not run as a Windows
+	// service, or before the first state was sent to the service manager.
+	StateUnknown State = iota
+	StateStopped
+	StateStartPending
+	StateStopPending
+	StateRunning
+	StateContinuePending
+	StatePausePending
+	StatePaused
+)
+
+var stateNames = map[State]string{
+	StateUnknown:         "unknown",
+	StateStopped:         "stopped",
+	StateStartPending:    "start pending",
+	StateStopPending:     "stop pending",
+	StateRunning:         "running",
+	StateContinuePending: "continue pending",
+	StatePausePending:    "pause pending",
+	StatePaused:          "paused",
+}
+
+func (s State) String() string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+var currentState uint32
+
+// CurrentState returns the last state the service reported to the Windows
+// service control manager. It is safe for concurrent use. On other platforms
+// it always returns StateUnknown.
+func CurrentState() State {
+	return State(atomic.LoadUint32(&currentState))
+}
+
+func setState(s State) {
+	atomic.StoreUint32(&currentState, uint32(s))
+}
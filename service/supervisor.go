@@ -0,0 +1,270 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+// RestartPolicy configures how a Supervisor restarts its child process.
+type RestartPolicy struct {
+	// MaxRestarts is the number of times the child is restarted before the
+	// supervisor gives up. 0 restarts the child indefinitely.
+	MaxRestarts int
+
+	// InitialBackoff is the delay before the first restart, it doubles on
+	// every restart up to MaxBackoff. It is reset to InitialBackoff when the
+	// child ran for at least MaxBackoff before exiting. Defaults to 1s and
+	// 1m.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// GracePeriod is the time Stop waits for the child to exit after asking
+	// it to terminate, before killing it. Defaults to 10s.
+	GracePeriod time.Duration
+
+	// Output receives the stdout and stderr of the child. If nil, the
+	// Stdout and Stderr of the supervised command are used.
+	Output io.Writer
+}
+
+const (
+	defaultInitialBackoff = time.Second
+	defaultMaxBackoff     = time.Minute
+	defaultGracePeriod    = 10 * time.Second
+)
+
+// ErrRestartBudgetExhausted is returned by (*Supervisor).Wait when the child
+// exited unexpectedly after it has been restarted MaxRestarts times.
+var ErrRestartBudgetExhausted = errors.New("child process restarted too many times")
+
+var errSupervisorStopped = errors.New("supervisor stopped")
+
+// Supervisor runs a child process and restarts it, with an exponential
+// backoff, every time it exits unexpectedly. The supervision ends when the
+// child exits with status 0, when the restart budget is exhausted or when
+// Stop is called.
+type Supervisor struct {
+	template *exec.Cmd
+	policy   RestartPolicy
+	logger   *logp.Logger
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+	err      error
+
+	mu       sync.Mutex
+	current  *exec.Cmd
+	restarts int
+}
+
+// Supervise starts cmd and supervises it following policy. The command must
+// not have been started, it is the template of the restarted processes:
+// its path, arguments, environment, working directory and system process
+// attributes are reused.
+func Supervise(cmd *exec.Cmd, policy RestartPolicy) (*Supervisor, error) {
+	if cmd.Process != nil {
+		return nil, errors.New("command already started")
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = defaultInitialBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = defaultMaxBackoff
+	}
+	if policy.GracePeriod <= 0 {
+		policy.GracePeriod = defaultGracePeriod
+	}
+
+	s := &Supervisor{
+		template: cmd,
+		policy:   policy,
+		logger:   logp.NewLogger("service"),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	child, err := s.start()
+	if err != nil {
+		return nil, err
+	}
+	go s.run(child)
+	return s, nil
+}
+
+// start starts a new child process from the template.
+func (s *Supervisor) start() (*exec.Cmd, error) {
+	t := s.template
+	cmd := &exec.Cmd{
+		Path:        t.Path,
+		Args:        t.Args,
+		Env:         t.Env,
+		Dir:         t.Dir,
+		Stdin:       t.Stdin,
+		Stdout:      t.Stdout,
+		Stderr:      t.Stderr,
+		ExtraFiles:  t.ExtraFiles,
+		SysProcAttr: t.SysProcAttr,
+	}
+	if s.policy.Output != nil {
+		cmd.Stdout = s.policy.Output
+		cmd.Stderr = s.policy.Output
+	}
+
+	// Stop reads the current process with the lock held, it either sees the
+	// new process or the process is not started.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopping() {
+		return nil, errSupervisorStopped
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %v: %w", t.Path, err)
+	}
+	s.current = cmd
+	return cmd, nil
+}
+
+func (s *Supervisor) run(child *exec.Cmd) {
+	defer close(s.done)
+
+	backoff := s.policy.InitialBackoff
+	started := time.Now()
+	for {
+		err := child.Wait()
+		if s.stopping() {
+			return
+		}
+		if err == nil {
+			s.logger.Debugf("Child process %v exited", s.template.Path)
+			return
+		}
+		// A child that ran long enough is not crash-looping, restart it
+		// quickly.
+		if time.Since(started) >= s.policy.MaxBackoff {
+			backoff = s.policy.InitialBackoff
+		}
+
+		if s.policy.MaxRestarts > 0 && s.Restarts() >= s.policy.MaxRestarts {
+			s.err = fmt.Errorf("%w: %v", ErrRestartBudgetExhausted, err)
+			return
+		}
+		s.logger.Warnf("Child process %v exited unexpectedly (%v), restarting in %v", s.template.Path, err, backoff)
+
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > s.policy.MaxBackoff {
+				backoff = s.policy.MaxBackoff
+			}
+
+			s.mu.Lock()
+			s.restarts++
+			s.mu.Unlock()
+
+			child, err = s.start()
+			if err == nil {
+				started = time.Now()
+				break
+			}
+			if errors.Is(err, errSupervisorStopped) {
+				return
+			}
+			if s.policy.MaxRestarts > 0 && s.Restarts() >= s.policy.MaxRestarts {
+				s.err = fmt.Errorf("%w: %v", ErrRestartBudgetExhausted, err)
+				return
+			}
+			s.logger.Warnf("%v, retrying in %v", err, backoff)
+		}
+	}
+}
+
+func (s *Supervisor) stopping() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// Restarts returns the number of times the child has been restarted.
+func (s *Supervisor) Restarts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.restarts
+}
+
+// Done returns a channel closed when the supervision ends.
+func (s *Supervisor) Done() <-chan struct{} {
+	return s.done
+}
+
+// Wait waits for the supervision to end. It returns an error wrapping
+// ErrRestartBudgetExhausted if the child was restarted too many times, nil
+// if it exited with status 0 or was stopped.
+func (s *Supervisor) Wait() error {
+	<-s.done
+	return s.err
+}
+
+// Stop stops the supervision and the child process. The child is asked to
+// terminate, SIGTERM on unix, and is killed if it is still running after
+// the grace period. On Windows the child is terminated immediately.
+func (s *Supervisor) Stop() error {
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	s.mu.Lock()
+	child := s.current
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return nil
+	default:
+	}
+
+	if err := terminateProcess(child.Process); err != nil {
+		s.logger.Debugf("Failed to terminate child process %v: %v", s.template.Path, err)
+	}
+
+	grace := time.NewTimer(s.policy.GracePeriod)
+	defer grace.Stop()
+	select {
+	case <-s.done:
+		return nil
+	case <-grace.C:
+	}
+
+	s.logger.Warnf("Child process %v did not exit within %v, killing it", s.template.Path, s.policy.GracePeriod)
+	if err := child.Process.Kill(); err != nil {
+		return fmt.Errorf("failed to kill child process: %w", err)
+	}
+	<-s.done
+	return nil
+}
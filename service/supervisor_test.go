@@ -0,0 +1,169 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+const supervisorHelperEnv = "SUPERVISOR_HELPER_MODE"
+
+// TestSupervisorHelperProcess is the child process of the supervisor tests.
+func TestSupervisorHelperProcess(t *testing.T) {
+	mode := os.Getenv(supervisorHelperEnv)
+	if mode == "" {
+		t.Skip("helper process")
+	}
+
+	fmt.Println("started")
+	switch mode {
+	case "crash":
+		os.Exit(3)
+	case "crash-late":
+		time.Sleep(200 * time.Millisecond)
+		os.Exit(3)
+	case "exit":
+		os.Exit(0)
+	case "sleep":
+		time.Sleep(time.Minute)
+	}
+	os.Exit(0)
+}
+
+func helperCommand(mode string) *exec.Cmd {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSupervisorHelperProcess$")
+	cmd.Env = append(os.Environ(), supervisorHelperEnv+"="+mode)
+	return cmd
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestSupervisorRestartsCrashedChild(t *testing.T) {
+	var out syncBuffer
+	s, err := Supervise(helperCommand("crash"), RestartPolicy{
+		MaxRestarts:    2,
+		InitialBackoff: time.Millisecond,
+		Output:         &out,
+	})
+	require.NoError(t, err)
+
+	err = s.Wait()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrRestartBudgetExhausted), err)
+	assert.Equal(t, 2, s.Restarts())
+	assert.Equal(t, 3, strings.Count(out.String(), "started"))
+}
+
+func TestSupervisorBackoff(t *testing.T) {
+	restartDelays := func(t *testing.T, mode string, maxBackoff time.Duration) []string {
+		t.Helper()
+		require.NoError(t, logp.DevelopmentSetup(logp.ToObserverOutput()))
+		s, err := Supervise(helperCommand(mode), RestartPolicy{
+			MaxRestarts:    3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     maxBackoff,
+			Output:         &syncBuffer{},
+		})
+		require.NoError(t, err)
+		require.Error(t, s.Wait())
+
+		var delays []string
+		for _, entry := range logp.ObserverLogs().FilterMessageSnippet("restarting in").TakeAll() {
+			delays = append(delays, entry.Message[strings.LastIndex(entry.Message, " ")+1:])
+		}
+		return delays
+	}
+
+	t.Run("doubles on crash loops", func(t *testing.T) {
+		assert.Equal(t, []string{"1ms", "2ms", "4ms"}, restartDelays(t, "crash", time.Minute))
+	})
+
+	t.Run("reset after a long run", func(t *testing.T) {
+		assert.Equal(t, []string{"1ms", "1ms", "1ms"}, restartDelays(t, "crash-late", 100*time.Millisecond))
+	})
+}
+
+func TestSupervisorCleanExitIsNotRestarted(t *testing.T) {
+	var out syncBuffer
+	s, err := Supervise(helperCommand("exit"), RestartPolicy{
+		InitialBackoff: time.Millisecond,
+		Output:         &out,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Wait())
+	assert.Equal(t, 0, s.Restarts())
+	assert.Equal(t, 1, strings.Count(out.String(), "started"))
+}
+
+func TestSupervisorStop(t *testing.T) {
+	var out syncBuffer
+	s, err := Supervise(helperCommand("sleep"), RestartPolicy{
+		GracePeriod: 5 * time.Second,
+		Output:      &out,
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(out.String(), "started")
+	}, 10*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, s.Stop())
+	select {
+	case <-s.Done():
+	default:
+		t.Fatal("supervision not done after Stop")
+	}
+	assert.NoError(t, s.Wait())
+	assert.Equal(t, 0, s.Restarts())
+}
+
+func TestSuperviseStartedCommand(t *testing.T) {
+	cmd := helperCommand("exit")
+	require.NoError(t, cmd.Run())
+	_, err := Supervise(cmd, RestartPolicy{})
+	assert.Error(t, err)
+}
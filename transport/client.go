@@ -45,6 +45,23 @@ type Config struct {
 	TLS     *tlscommon.TLSConfig
 	Timeout time.Duration
 	Stats   IOStatser
+
+	// ConnWrappers wrap every dialed connection, e.g. to count bytes or to
+	// write a PROXY protocol header. They are applied in order with WrapConn
+	// to the connection to the remote host, before TLS.
+	ConnWrappers []func(net.Conn) net.Conn
+
+	// DialLimiter, if set, caps the number of dials in flight across the
+	// dialers sharing it.
+	DialLimiter *DialLimiter
+
+	// PreDial, if set, is called before every dial attempt.
+	PreDial func(network, addr string)
+
+	// PostDial, if set, is called after every dial attempt with its result.
+	// The connection and error it returns replace the result, so it can wrap
+	// or reject the connection.
+	PostDial func(conn net.Conn, err error) (net.Conn, error)
 }
 
 func NewClient(c Config, network, host string, defaultPort int) (*Client, error) {
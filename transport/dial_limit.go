@@ -0,0 +1,136 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package transport
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+// DialLimiter caps the number of dials in flight across the dialers sharing
+// it, protecting the process and the remote hosts from reconnect storms. A
+// dial is in flight from the time it gets a slot until the connection,
+// including the TLS handshake if any, is established or fails.
+type DialLimiter struct {
+	slots chan struct{}
+
+	inFlight int64 // atomic
+	waiting  int64 // atomic
+	waitTime int64 // atomic, cumulative wait time in nanoseconds
+}
+
+// NewDialLimiter creates a DialLimiter allowing up to max dials in flight.
+// NewDialLimiter returns nil, which does not limit the dials, if max is 0 or
+// less.
+func NewDialLimiter(max int) *DialLimiter {
+	if max <= 0 {
+		return nil
+	}
+	return &DialLimiter{slots: make(chan struct{}, max)}
+}
+
+// InFlight returns the number of dials in flight.
+func (l *DialLimiter) InFlight() int {
+	return int(atomic.LoadInt64(&l.inFlight))
+}
+
+// Waiting returns the number of dials waiting for a slot.
+func (l *DialLimiter) Waiting() int {
+	return int(atomic.LoadInt64(&l.waiting))
+}
+
+// WaitTime returns the total time dials have waited for a slot.
+func (l *DialLimiter) WaitTime() time.Duration {
+	return time.Duration(atomic.LoadInt64(&l.waitTime))
+}
+
+// RegisterMetrics adds the dials namespace to reg, reporting the in_flight
+// and waiting gauges and the cumulative wait_time.ms counter.
+func (l *DialLimiter) RegisterMetrics(reg *monitoring.Registry) {
+	monitoring.NewFunc(reg, "dials", func(_ monitoring.Mode, v monitoring.Visitor) {
+		v.OnRegistryStart()
+		defer v.OnRegistryFinished()
+
+		monitoring.ReportInt(v, "in_flight", int64(l.InFlight()))
+		monitoring.ReportInt(v, "waiting", int64(l.Waiting()))
+		monitoring.ReportNamespace(v, "wait_time", func() {
+			monitoring.ReportInt(v, "ms", l.WaitTime().Milliseconds())
+		})
+	}, monitoring.Report)
+}
+
+// acquire blocks until a slot is free or ctx is cancelled.
+func (l *DialLimiter) acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+	default:
+		atomic.AddInt64(&l.waiting, 1)
+		start := time.Now()
+		var err error
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		atomic.AddInt64(&l.waitTime, int64(time.Since(start)))
+		atomic.AddInt64(&l.waiting, -1)
+		if err != nil {
+			return err
+		}
+	}
+	atomic.AddInt64(&l.inFlight, 1)
+	return nil
+}
+
+func (l *DialLimiter) release() {
+	atomic.AddInt64(&l.inFlight, -1)
+	<-l.slots
+}
+
+// LimitedDialer is a Dialer whose dials are capped by a DialLimiter.
+type LimitedDialer struct {
+	dialer  Dialer
+	limiter *DialLimiter
+}
+
+// LimitDialer caps the dials of d with l. d is returned as is if l is nil.
+func LimitDialer(d Dialer, l *DialLimiter) Dialer {
+	if l == nil {
+		return d
+	}
+	return &LimitedDialer{dialer: d, limiter: l}
+}
+
+// Dial waits for a free slot and dials address.
+func (d *LimitedDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, address)
+}
+
+// DialContext waits for a free slot and dials address. An error is returned
+// if ctx is cancelled while waiting, the dial itself is not cancelled.
+func (d *LimitedDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if err := d.limiter.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer d.limiter.release()
+	return d.dialer.Dial(network, address)
+}
@@ -0,0 +1,127 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package transport
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+func TestDialLimiterConcurrency(t *testing.T) {
+	const (
+		max   = 3
+		dials = 50
+	)
+
+	var current, peak int64
+	d := DialerFunc(func(_, _ string) (net.Conn, error) {
+		n := atomic.AddInt64(&current, 1)
+		for {
+			p := atomic.LoadInt64(&peak)
+			if n <= p || atomic.CompareAndSwapInt64(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt64(&current, -1)
+		client, server := net.Pipe()
+		server.Close()
+		return client, nil
+	})
+
+	limiter := NewDialLimiter(max)
+	dialer := mustLimitedDialer(t, d, limiter)
+
+	var wg sync.WaitGroup
+	for i := 0; i < dials; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, err := dialer.Dial("tcp", "localhost:80")
+			if assert.NoError(t, err) {
+				conn.Close()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, max, atomic.LoadInt64(&peak))
+	assert.Zero(t, limiter.InFlight())
+	assert.Zero(t, limiter.Waiting())
+	assert.Greater(t, int64(limiter.WaitTime()), int64(0))
+}
+
+func TestDialLimiterContextCancel(t *testing.T) {
+	release := make(chan struct{})
+	d := DialerFunc(func(_, _ string) (net.Conn, error) {
+		<-release
+		return nil, net.ErrClosed
+	})
+
+	limiter := NewDialLimiter(1)
+	dialer := mustLimitedDialer(t, d, limiter)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = dialer.Dial("tcp", "localhost:80")
+	}()
+	require.Eventually(t, func() bool { return limiter.InFlight() == 1 }, time.Second, time.Millisecond)
+
+	reg := monitoring.NewRegistry()
+	limiter.RegisterMetrics(reg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := dialer.DialContext(ctx, "tcp", "localhost:80")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	snapshot := monitoring.CollectFlatSnapshot(reg, monitoring.Full, false)
+	assert.EqualValues(t, 1, snapshot.Ints["dials.in_flight"])
+	assert.EqualValues(t, 0, snapshot.Ints["dials.waiting"])
+	assert.GreaterOrEqual(t, snapshot.Ints["dials.wait_time.ms"], int64(20))
+
+	close(release)
+	<-done
+	assert.Zero(t, limiter.InFlight())
+}
+
+func TestLimitDialerUnlimited(t *testing.T) {
+	d, _ := pipeDialer()
+	assert.Nil(t, NewDialLimiter(0))
+	assert.IsType(t, DialerFunc(nil), LimitDialer(d, nil))
+
+	dialer, err := MakeDialer(Config{DialLimiter: NewDialLimiter(1)})
+	require.NoError(t, err)
+	assert.IsType(t, &LimitedDialer{}, dialer)
+}
+
+func mustLimitedDialer(t *testing.T, d Dialer, l *DialLimiter) *LimitedDialer {
+	dialer, ok := LimitDialer(d, l).(*LimitedDialer)
+	require.True(t, ok)
+	return dialer
+}
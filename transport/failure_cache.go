@@ -0,0 +1,132 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package transport
+
+import (
+	"math/rand"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultFailureDecay is the time after which a failed address is considered
+// healthy again.
+const DefaultFailureDecay = 1 * time.Minute
+
+// AddressFailureCache tracks the last failed dial attempt per address, so
+// addresses that failed recently are tried last.
+type AddressFailureCache struct {
+	decay time.Duration
+	now   func() time.Time
+
+	mu       sync.Mutex
+	failures map[string]time.Time
+}
+
+// NewAddressFailureCache creates an AddressFailureCache forgetting failures
+// after decay. DefaultFailureDecay is used when decay is not positive.
+func NewAddressFailureCache(decay time.Duration) *AddressFailureCache {
+	if decay <= 0 {
+		decay = DefaultFailureDecay
+	}
+	return &AddressFailureCache{
+		decay:    decay,
+		now:      time.Now,
+		failures: map[string]time.Time{},
+	}
+}
+
+// RecordFailure marks address as failed now.
+func (c *AddressFailureCache) RecordFailure(address string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures[address] = c.now()
+}
+
+// RecordSuccess clears any failure recorded for address.
+func (c *AddressFailureCache) RecordSuccess(address string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.failures, address)
+}
+
+// LastFailure returns the time of the last failure of address, the boolean is
+// false if the address has not failed within the decay period.
+func (c *AddressFailureCache) LastFailure(address string) (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastFailure(address, c.now())
+}
+
+func (c *AddressFailureCache) lastFailure(address string, now time.Time) (time.Time, bool) {
+	ts, ok := c.failures[address]
+	if !ok {
+		return time.Time{}, false
+	}
+	if now.Sub(ts) >= c.decay {
+		delete(c.failures, address)
+		return time.Time{}, false
+	}
+	return ts, true
+}
+
+// Sort returns the addresses in the order they should be dialed: healthy
+// addresses first, in random order to spread the load, followed by the
+// addresses that failed recently, the ones that failed the longest time ago
+// first.
+func (c *AddressFailureCache) Sort(addresses []string) []string {
+	healthy := make([]string, 0, len(addresses))
+	var failed []string
+	failedAt := map[string]time.Time{}
+
+	c.mu.Lock()
+	now := c.now()
+	for _, i := range rand.Perm(len(addresses)) {
+		addr := addresses[i]
+		if ts, ok := c.lastFailure(addr, now); ok {
+			failed = append(failed, addr)
+			failedAt[addr] = ts
+		} else {
+			healthy = append(healthy, addr)
+		}
+	}
+	c.mu.Unlock()
+
+	sort.SliceStable(failed, func(i, j int) bool {
+		return failedAt[failed[i]].Before(failedAt[failed[j]])
+	})
+	return append(healthy, failed...)
+}
+
+// FailoverDialer wraps forward, resolving the host of the dialed address and
+// dialing its IP addresses in the order given by cache. Failures and successes
+// of every attempt are recorded in cache.
+func FailoverDialer(forward Dialer, cache *AddressFailureCache) Dialer {
+	return DialerFunc(func(network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		addresses, err := net.LookupHost(host)
+		if err != nil {
+			return nil, err
+		}
+		return DialWithFailureCache(forward, cache, network, host, addresses, port)
+	})
+}
@@ -0,0 +1,124 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package transport
+
+import (
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialWithFailureCacheSkipsFailingAddress(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	_, port, err := net.SplitHostPort(l.Addr().String())
+	require.NoError(t, err)
+
+	var failedDials int32
+	dialer := DialerFunc(func(network, address string) (net.Conn, error) {
+		if host, _, _ := net.SplitHostPort(address); host == "192.0.2.1" {
+			atomic.AddInt32(&failedDials, 1)
+			return nil, errors.New("connection refused")
+		}
+		return net.Dial(network, address)
+	})
+
+	cache := NewAddressFailureCache(time.Hour)
+	addresses := []string{"192.0.2.1", "127.0.0.1"}
+	for i := 0; i < 20; i++ {
+		conn, err := DialWithFailureCache(dialer, cache, "tcp", "example.com", addresses, port)
+		require.NoError(t, err)
+		conn.Close()
+	}
+
+	assert.LessOrEqual(t, atomic.LoadInt32(&failedDials), int32(1))
+	_, failed := cache.LastFailure("127.0.0.1")
+	assert.False(t, failed)
+}
+
+func TestDialWithFailureCacheAllFailing(t *testing.T) {
+	dialer := DialerFunc(func(network, address string) (net.Conn, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	cache := NewAddressFailureCache(time.Hour)
+	_, err := DialWithFailureCache(dialer, cache, "tcp", "example.com", []string{"192.0.2.1", "192.0.2.2"}, "80")
+	require.Error(t, err)
+
+	for _, addr := range []string{"192.0.2.1", "192.0.2.2"} {
+		_, failed := cache.LastFailure(addr)
+		assert.True(t, failed, addr)
+	}
+}
+
+func TestAddressFailureCacheSort(t *testing.T) {
+	now := time.Now()
+	cache := NewAddressFailureCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.RecordFailure("10.0.0.3")
+	now = now.Add(10 * time.Second)
+	cache.RecordFailure("10.0.0.1")
+
+	sorted := cache.Sort([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3"})
+	assert.Equal(t, []string{"10.0.0.2", "10.0.0.3", "10.0.0.1"}, sorted)
+
+	// the failure of 10.0.0.3 decays
+	now = now.Add(55 * time.Second)
+	sorted = cache.Sort([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3"})
+	assert.ElementsMatch(t, []string{"10.0.0.2", "10.0.0.3"}, sorted[:2])
+	assert.Equal(t, "10.0.0.1", sorted[2])
+	_, failed := cache.LastFailure("10.0.0.3")
+	assert.False(t, failed)
+
+	cache.RecordSuccess("10.0.0.1")
+	_, failed = cache.LastFailure("10.0.0.1")
+	assert.False(t, failed)
+}
+
+func TestFailoverDialer(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			conn.Close()
+		}
+	}()
+
+	cache := NewAddressFailureCache(0)
+	conn, err := FailoverDialer(NetDialer(time.Second), cache).Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	conn.Close()
+}
@@ -0,0 +1,92 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package httpcommon
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// ErrResponseBodyTooLarge is returned when a response body exceeds
+// HTTPTransportSettings.MaxResponseBodyBytes.
+var ErrResponseBodyTooLarge = errors.New("response body exceeds the configured size limit")
+
+type noBodyLimitKey struct{}
+
+// WithoutResponseBodyLimit returns a context disabling the response body
+// size limit for the requests using it. This is meant for streaming
+// responses that legitimately exceed the configured limit.
+func WithoutResponseBodyLimit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noBodyLimitKey{}, true)
+}
+
+type bodyLimitRoundTripper struct {
+	max int64
+	rt  http.RoundTripper
+}
+
+func (rt *bodyLimitRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.rt.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		return resp, err
+	}
+	if disabled, _ := req.Context().Value(noBodyLimitKey{}).(bool); disabled {
+		return resp, nil
+	}
+
+	if resp.ContentLength > rt.max {
+		_ = resp.Body.Close()
+		return nil, ErrResponseBodyTooLarge
+	}
+	resp.Body = &limitedBody{body: resp.Body, remaining: rt.max}
+	return resp, nil
+}
+
+// limitedBody fails with ErrResponseBodyTooLarge once more than remaining
+// bytes have been read.
+type limitedBody struct {
+	body      io.ReadCloser
+	remaining int64
+	exceeded  bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, ErrResponseBodyTooLarge
+	}
+
+	// Read one extra byte to detect if the body is larger than the limit.
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.body.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = 0
+		b.exceeded = true
+		return n, ErrResponseBodyTooLarge
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	return b.body.Close()
+}
@@ -0,0 +1,89 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package httpcommon
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxResponseBodyBytes(t *testing.T) {
+	payload := strings.Repeat("x", 1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Flush before writing the body to use chunked encoding and not
+		// announce the body size.
+		if r.URL.Query().Get("chunked") != "" {
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write([]byte(payload))
+	}))
+	defer server.Close()
+
+	newClient := func(t *testing.T, max int64) *http.Client {
+		settings := DefaultHTTPTransportSettings()
+		settings.MaxResponseBodyBytes = max
+		client, err := settings.Client()
+		require.NoError(t, err)
+		return client
+	}
+
+	get := func(t *testing.T, client *http.Client, req *http.Request) (string, error) {
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	t.Run("body within limit", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"?chunked=1", nil)
+		body, err := get(t, newClient(t, 1024), req)
+		require.NoError(t, err)
+		assert.Equal(t, payload, body)
+	})
+
+	t.Run("oversized content length", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		_, err := get(t, newClient(t, 100), req)
+		assert.True(t, errors.Is(err, ErrResponseBodyTooLarge), err)
+	})
+
+	t.Run("oversized chunked body", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"?chunked=1", nil)
+		body, err := get(t, newClient(t, 100), req)
+		assert.True(t, errors.Is(err, ErrResponseBodyTooLarge), err)
+		assert.Len(t, body, 100)
+	})
+
+	t.Run("limit disabled per request", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"?chunked=1", nil)
+		req = req.WithContext(WithoutResponseBodyLimit(req.Context()))
+		body, err := get(t, newClient(t, 100), req)
+		require.NoError(t, err)
+		assert.Equal(t, payload, body)
+	})
+}
@@ -0,0 +1,88 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package httpcommon
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/config"
+)
+
+func TestDialTimeout(t *testing.T) {
+	newClient := func(t *testing.T) *http.Client {
+		settings := DefaultHTTPTransportSettings()
+		settings.Timeout = 10 * time.Second
+		settings.DialTimeout = 200 * time.Millisecond
+		client, err := settings.Client()
+		require.NoError(t, err)
+		return client
+	}
+
+	t.Run("connect fails fast", func(t *testing.T) {
+		// 10.255.255.1 is not routable, the connection attempt hangs
+		// until the dial timeout expires.
+		start := time.Now()
+		resp, err := newClient(t).Get("http://10.255.255.1:9200/")
+		if err == nil {
+			resp.Body.Close()
+		}
+		require.Error(t, err)
+		assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+	})
+
+	t.Run("slow request is not cut short", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(500 * time.Millisecond)
+			_, _ = w.Write([]byte("done"))
+		}))
+		defer server.Close()
+
+		resp, err := newClient(t).Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "done", string(body))
+	})
+}
+
+func TestDialTimeoutUnpack(t *testing.T) {
+	settings := DefaultHTTPTransportSettings()
+	err := config.MustNewConfigFrom(map[string]interface{}{
+		"timeout":      "30s",
+		"dial_timeout": "5s",
+	}).Unpack(&settings)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, settings.Timeout)
+	assert.Equal(t, 5*time.Second, settings.DialTimeout)
+	assert.Equal(t, 5*time.Second, settings.dialTimeout())
+
+	settings.DialTimeout = 0
+	assert.Equal(t, 30*time.Second, settings.dialTimeout())
+
+	settings = DefaultHTTPTransportSettings()
+	err = config.MustNewConfigFrom(map[string]interface{}{"dial_timeout": "-1s"}).Unpack(&settings)
+	assert.Error(t, err)
+}
@@ -0,0 +1,81 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package httpcommon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
+)
+
+func protoHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Proto", r.Proto)
+	})
+}
+
+func TestForceHTTP1(t *testing.T) {
+	server := httptest.NewUnstartedServer(protoHandler())
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	settings := DefaultHTTPTransportSettings()
+	settings.TLS = &tlscommon.Config{VerificationMode: tlscommon.VerifyNone}
+	settings.ForceHTTP1 = true
+
+	rt, err := settings.RoundTripper(WithForceAttemptHTTP2(true))
+	require.NoError(t, err)
+	transport, ok := rt.(*http.Transport)
+	require.True(t, ok)
+	assert.False(t, transport.ForceAttemptHTTP2)
+	assert.NotNil(t, transport.TLSNextProto)
+	assert.Empty(t, transport.TLSNextProto)
+
+	resp, err := (&http.Client{Transport: rt}).Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "HTTP/1.1", resp.Proto)
+	assert.Equal(t, "HTTP/1.1", resp.Header.Get("X-Proto"))
+}
+
+func TestH2C(t *testing.T) {
+	server := httptest.NewServer(h2c.NewHandler(protoHandler(), &http2.Server{}))
+	defer server.Close()
+
+	settings := DefaultHTTPTransportSettings()
+	client, err := settings.Client(WithH2C(true))
+	require.NoError(t, err)
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 2, resp.ProtoMajor)
+	assert.Equal(t, "HTTP/2.0", resp.Header.Get("X-Proto"))
+
+	settings.ForceHTTP1 = true
+	_, err = settings.Client(WithH2C(true))
+	assert.Error(t, err)
+}
@@ -18,6 +18,7 @@
 package httpcommon
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -35,17 +36,41 @@ type HTTPTransportSettings struct {
 	// TLS provides ssl/tls setup settings
 	TLS *tlscommon.Config `config:"ssl" yaml:"ssl,omitempty" json:"ssl,omitempty"`
 
-	// Timeout configures the `(http.Transport).Timeout`.
+	// Timeout configures the `(http.Client).Timeout`, the time limit of a
+	// request including reading the response body. It is used as
+	// DialTimeout if DialTimeout is not set.
 	Timeout time.Duration `config:"timeout" yaml:"timeout,omitempty" json:"timeout,omitempty"`
 
+	// DialTimeout limits the time to establish a connection, it does not
+	// limit requests on established connections. The TLS handshake gets its
+	// own DialTimeout after the connection is established, so a TLS
+	// connection can take up to twice DialTimeout. Timeout still bounds the
+	// whole request, including the dial. Timeout is used if not set.
+	DialTimeout time.Duration `config:"dial_timeout" yaml:"dial_timeout,omitempty" json:"dial_timeout,omitempty" validate:"min=0"`
+
 	Proxy HTTPClientProxySettings `config:",inline" yaml:",inline"`
 
+	// MaxResponseBodyBytes limits the size of the response bodies that can be
+	// read. Reading past the limit fails with ErrResponseBodyTooLarge. 0
+	// disables the limit, see also WithoutResponseBodyLimit.
+	MaxResponseBodyBytes int64 `config:"max_response_body_bytes" yaml:"max_response_body_bytes,omitempty" json:"max_response_body_bytes,omitempty" validate:"min=0"`
+
+	// ForceHTTP1 disables HTTP/2, requests are always sent using HTTP/1.1.
+	// The transport then never upgrades a connection to HTTP/2, even if the
+	// server selects h2 during the TLS ALPN negotiation. It can not be
+	// combined with WithHTTP2Only or WithH2C.
+	ForceHTTP1 bool `config:"force_http1" yaml:"force_http1,omitempty" json:"force_http1,omitempty"`
+
+	// Host overrides the Host header of the requests, e.g. to route the
+	// requests sent through a load balancer. The address dialed is still
+	// taken from the request URL.
+	Host string `config:"host_header" yaml:"host_header,omitempty" json:"host_header,omitempty"`
+
 	// Add more settings:
 	//  - DisableKeepAlive
 	//  - MaxIdleConns
 	//  - IdleConnTimeout
 	//  - ResponseHeaderTimeout
-	//  - ConnectionTimeout (currently 'Timeout' is used for both)
 }
 
 // WithKeepaliveSettings options can be used to modify the Keepalive
@@ -68,11 +93,13 @@ type (
 	extraSettings struct {
 		logger *logp.Logger
 		http2  bool
+		h2c    bool
+		trace  func(*http.Request, RequestTrace)
 	}
 
 	dialerOption interface {
 		TransportOption
-		baseDialer() transport.Dialer
+		baseDialer(*HTTPTransportSettings) transport.Dialer
 	}
 	dialerModOption interface {
 		TransportOption
@@ -92,13 +119,13 @@ type (
 	}
 )
 
-type baseDialerFunc func() transport.Dialer
+type baseDialerFunc func(*HTTPTransportSettings) transport.Dialer
 
 var _ dialerOption = baseDialerFunc(nil)
 
 func (baseDialerFunc) sealTransportOption() {}
-func (fn baseDialerFunc) baseDialer() transport.Dialer {
-	return fn()
+func (fn baseDialerFunc) baseDialer(s *HTTPTransportSettings) transport.Dialer {
+	return fn(s)
 }
 
 type dialerOptFunc func(transport.Dialer) transport.Dialer
@@ -159,9 +186,19 @@ func DefaultHTTPTransportSettings() HTTPTransportSettings {
 // Unpack reads a config object into the settings.
 func (settings *HTTPTransportSettings) Unpack(cfg *config.C) error {
 	tmp := struct {
-		TLS     *tlscommon.Config `config:"ssl"`
-		Timeout time.Duration     `config:"timeout"`
-	}{Timeout: settings.Timeout}
+		TLS                  *tlscommon.Config `config:"ssl"`
+		Timeout              time.Duration     `config:"timeout"`
+		DialTimeout          time.Duration     `config:"dial_timeout" validate:"min=0"`
+		MaxResponseBodyBytes int64             `config:"max_response_body_bytes" validate:"min=0"`
+		ForceHTTP1           bool              `config:"force_http1"`
+		Host                 string            `config:"host_header"`
+	}{
+		Timeout:              settings.Timeout,
+		DialTimeout:          settings.DialTimeout,
+		MaxResponseBodyBytes: settings.MaxResponseBodyBytes,
+		ForceHTTP1:           settings.ForceHTTP1,
+		Host:                 settings.Host,
+	}
 
 	if err := cfg.Unpack(&tmp); err != nil {
 		return err
@@ -178,13 +215,25 @@ func (settings *HTTPTransportSettings) Unpack(cfg *config.C) error {
 	}
 
 	*settings = HTTPTransportSettings{
-		TLS:     tmp.TLS,
-		Timeout: tmp.Timeout,
-		Proxy:   proxy,
+		TLS:                  tmp.TLS,
+		Timeout:              tmp.Timeout,
+		DialTimeout:          tmp.DialTimeout,
+		Proxy:                proxy,
+		MaxResponseBodyBytes: tmp.MaxResponseBodyBytes,
+		ForceHTTP1:           tmp.ForceHTTP1,
+		Host:                 tmp.Host,
 	}
 	return nil
 }
 
+// dialTimeout returns the time limit to establish a connection.
+func (settings *HTTPTransportSettings) dialTimeout() time.Duration {
+	if settings.DialTimeout > 0 {
+		return settings.DialTimeout
+	}
+	return settings.Timeout
+}
+
 // RoundTripper creates a http.RoundTripper for use with http.Client.
 //
 // The dialers will registers with stats if given. Stats is used to collect metrics for io errors,
@@ -201,12 +250,13 @@ func (settings *HTTPTransportSettings) RoundTripper(opts ...TransportOption) (ht
 
 	for _, opt := range opts {
 		if dialOpt, ok := opt.(dialerOption); ok {
-			dialer = dialOpt.baseDialer()
+			dialer = dialOpt.baseDialer(settings)
 		}
 	}
 
-	if dialer == nil {
-		dialer = transport.NetDialer(settings.Timeout)
+	defaultDialer := dialer == nil
+	if defaultDialer {
+		dialer = transport.NetDialer(settings.dialTimeout())
 	}
 
 	tls, err := tlscommon.LoadTLSConfig(settings.TLS)
@@ -214,27 +264,55 @@ func (settings *HTTPTransportSettings) RoundTripper(opts ...TransportOption) (ht
 		return nil, err
 	}
 
-	tlsDialer := transport.TLSDialer(dialer, tls, settings.Timeout)
-	for _, opt := range opts {
-		if dialOpt, ok := opt.(dialerModOption); ok {
-			dialer = dialOpt.applyDialer(settings, dialer)
-			tlsDialer = dialOpt.applyDialer(settings, tlsDialer)
+	buildDialers := func(dialer transport.Dialer) (transport.Dialer, transport.Dialer) {
+		tlsDialer := transport.TLSDialer(dialer, tls, settings.dialTimeout())
+		for _, opt := range opts {
+			if dialOpt, ok := opt.(dialerModOption); ok {
+				dialer = dialOpt.applyDialer(settings, dialer)
+				tlsDialer = dialOpt.applyDialer(settings, tlsDialer)
+			}
+		}
+
+		if logger := extra.logger; logger != nil {
+			dialer = transport.LoggingDialer(dialer, logger)
+			tlsDialer = transport.LoggingDialer(tlsDialer, logger)
 		}
+		return dialer, tlsDialer
+	}
+
+	var trace *dialTrace
+	if extra.trace != nil {
+		trace = &dialTrace{base: dialer, resolve: defaultDialer, build: buildDialers}
 	}
+	dialer, tlsDialer := buildDialers(dialer)
 
-	if logger := extra.logger; logger != nil {
-		dialer = transport.LoggingDialer(dialer, logger)
-		tlsDialer = transport.LoggingDialer(tlsDialer, logger)
+	if settings.ForceHTTP1 && (extra.http2 || extra.h2c) {
+		return nil, errors.New("force_http1 can not be used with an HTTP/2 only transport")
 	}
 
 	var rt http.RoundTripper
-	if extra.http2 {
-		rt, err = settings.http2RoundTripper(tls, dialer, tlsDialer, opts...)
+	switch {
+	case extra.h2c:
+		rt = h2cRoundTripper(dialer)
+	case extra.http2:
+		rt, err = settings.http2RoundTripper(tls, dialer, tlsDialer, trace, opts...)
 		if err != nil {
 			return nil, err
 		}
-	} else {
-		rt = settings.httpRoundTripper(tls, dialer, tlsDialer, opts...)
+	default:
+		rt = settings.httpRoundTripper(tls, dialer, tlsDialer, trace, opts...)
+	}
+
+	if extra.trace != nil {
+		rt = &traceRoundTripper{rt: rt, fn: extra.trace}
+	}
+
+	if settings.MaxResponseBodyBytes > 0 {
+		rt = &bodyLimitRoundTripper{max: settings.MaxResponseBodyBytes, rt: rt}
+	}
+
+	if settings.Host != "" {
+		rt = &hostRoundTripper{host: settings.Host, rt: rt}
 	}
 
 	for _, opt := range opts {
@@ -248,6 +326,7 @@ func (settings *HTTPTransportSettings) RoundTripper(opts ...TransportOption) (ht
 func (settings *HTTPTransportSettings) httpRoundTripper(
 	tls *tlscommon.TLSConfig,
 	dialer, tlsDialer transport.Dialer,
+	trace *dialTrace,
 	opts ...TransportOption,
 ) *http.Transport {
 	t := http.DefaultTransport.(*http.Transport).Clone()
@@ -255,6 +334,10 @@ func (settings *HTTPTransportSettings) httpRoundTripper(
 	t.DialTLSContext = nil
 	t.Dial = dialer.Dial       // nolint: staticcheck // use deprecated function to preserve functionality
 	t.DialTLS = tlsDialer.Dial // nolint: staticcheck // use deprecated function to preserve functionality
+	if trace != nil {
+		t.DialContext = trace.dial
+		t.DialTLSContext = trace.dialTLS
+	}
 	t.TLSClientConfig = tls.ToConfig()
 	t.ForceAttemptHTTP2 = false
 	t.Proxy = settings.Proxy.ProxyFunc()
@@ -270,15 +353,20 @@ func (settings *HTTPTransportSettings) httpRoundTripper(
 		}
 	}
 
+	if settings.ForceHTTP1 {
+		disableHTTP2(t)
+	}
+
 	return t
 }
 
 func (settings *HTTPTransportSettings) http2RoundTripper(
 	tls *tlscommon.TLSConfig,
 	dialer, tlsDialer transport.Dialer,
+	trace *dialTrace,
 	opts ...TransportOption,
 ) (*http2.Transport, error) {
-	t1 := settings.httpRoundTripper(tls, dialer, tlsDialer, opts...)
+	t1 := settings.httpRoundTripper(tls, dialer, tlsDialer, trace, opts...)
 	t2, err := http2.ConfigureTransports(t1)
 	if err != nil {
 		return nil, err
@@ -315,7 +403,7 @@ func (opts WithKeepaliveSettings) applyTransport(_ *HTTPTransportSettings, t *ht
 
 // WithBaseDialer configures the dialer used for TCP and TLS connections.
 func WithBaseDialer(d transport.Dialer) TransportOption {
-	return baseDialerFunc(func() transport.Dialer {
+	return baseDialerFunc(func(*HTTPTransportSettings) transport.Dialer {
 		return d
 	})
 }
@@ -346,6 +434,15 @@ func WithHTTP2Only(b bool) TransportOption {
 	})
 }
 
+// WithH2C will ensure that a HTTP 2 only roundtripper sending requests over
+// plaintext connections (h2c) is created. The server must support HTTP/2 with
+// prior knowledge. Proxy and TLS settings are not used.
+func WithH2C(b bool) TransportOption {
+	return extraOptionFunc(func(settings *extraSettings) {
+		settings.h2c = b
+	})
+}
+
 // WithForceAttemptHTTP2 sets the `http.Tansport.ForceAttemptHTTP2` field.
 func WithForceAttemptHTTP2(b bool) TransportOption {
 	return transportOptFunc(func(settings *HTTPTransportSettings, t *http.Transport) {
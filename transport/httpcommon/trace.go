@@ -0,0 +1,244 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package httpcommon
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/transport"
+)
+
+// RequestTrace holds the phase timings of a single HTTP request attempt.
+// Phases that did not happen, for example DNS and connect on a reused
+// connection, are zero.
+type RequestTrace struct {
+	// DNS is the time spent resolving the host name.
+	DNS time.Duration
+	// Connect is the time spent establishing the TCP connection.
+	Connect time.Duration
+	// TLSHandshake is the time spent in the TLS handshake.
+	TLSHandshake time.Duration
+	// FirstByte is the time from the start of the attempt until the first
+	// byte of the response was received.
+	FirstByte time.Duration
+	// Total is the time from the start of the attempt until the response
+	// headers were read or the attempt failed.
+	Total time.Duration
+	// ConnReused reports whether an idle connection was used.
+	ConnReused bool
+	// Err is the error returned by the attempt, if any.
+	Err error
+}
+
+// WithRequestTrace attaches an httptrace.ClientTrace to every request sent
+// through the transport and calls fn with the phase timings once the
+// response headers are read. Each call to RoundTrip is traced separately, so
+// round trippers retrying a request report one trace per attempt.
+func WithRequestTrace(fn func(*http.Request, RequestTrace)) TransportOption {
+	return extraOptionFunc(func(s *extraSettings) {
+		s.trace = fn
+	})
+}
+
+type traceRoundTripper struct {
+	rt http.RoundTripper
+	fn func(*http.Request, RequestTrace)
+}
+
+func (rt *traceRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := &traceRecorder{start: time.Now()}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), rec.clientTrace()))
+
+	resp, err := rt.rt.RoundTrip(req)
+	rt.fn(req, rec.finish(err))
+	return resp, err
+}
+
+// traceRecorder collects the timings of one attempt. The hooks can be called
+// from the goroutines dialing the connection, so access is synchronized.
+type traceRecorder struct {
+	mu        sync.Mutex
+	start     time.Time
+	dnsStart  time.Time
+	connStart time.Time
+	tlsStart  time.Time
+	trace     RequestTrace
+}
+
+func (r *traceRecorder) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.trace.DNS += since(r.dnsStart)
+		},
+		ConnectStart: func(_, _ string) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if r.connStart.IsZero() {
+				r.connStart = time.Now()
+			}
+		},
+		ConnectDone: func(_, _ string, _ error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.trace.Connect = since(r.connStart)
+		},
+		TLSHandshakeStart: func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.trace.TLSHandshake += since(r.tlsStart)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.trace.ConnReused = info.Reused
+		},
+		GotFirstResponseByte: func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.trace.FirstByte = since(r.start)
+		},
+	}
+}
+
+func (r *traceRecorder) finish(err error) RequestTrace {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.trace.Total = since(r.start)
+	r.trace.Err = err
+	return r.trace
+}
+
+func since(t time.Time) time.Duration {
+	if t.IsZero() {
+		return 0
+	}
+	return time.Since(t)
+}
+
+// dialTrace makes the transport report the DNS, connect and TLS phases of
+// its dials to the httptrace.ClientTrace of the request. The configured
+// dialers do not accept a context, so the dialer chain is built per dial on
+// top of a dialer bound to the request context.
+type dialTrace struct {
+	base    transport.Dialer
+	resolve bool
+	build   func(transport.Dialer) (transport.Dialer, transport.Dialer)
+}
+
+func (d *dialTrace) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	dialer, _ := d.build(&traceDialer{ctx: ctx, forward: d.base, resolve: d.resolve})
+	return dialer.Dial(network, address)
+}
+
+func (d *dialTrace) dialTLS(ctx context.Context, network, address string) (net.Conn, error) {
+	traced := &traceDialer{ctx: ctx, forward: d.base, resolve: d.resolve, tls: true}
+	_, tlsDialer := d.build(traced)
+	conn, err := tlsDialer.Dial(network, address)
+	if trace := httptrace.ContextClientTrace(ctx); traced.handshake && trace != nil && trace.TLSHandshakeDone != nil {
+		var state tls.ConnectionState
+		if tlsConn, ok := conn.(*tls.Conn); ok {
+			state = tlsConn.ConnectionState()
+		}
+		trace.TLSHandshakeDone(state, err)
+	}
+	return conn, err
+}
+
+// traceDialer reports the phases of a dial done by the forward dialer to the
+// httptrace.ClientTrace found in ctx. If resolve is set the host name is
+// resolved before dialing, like transport.NetDialer does, so the DNS lookup
+// can be reported separately.
+type traceDialer struct {
+	ctx       context.Context
+	forward   transport.Dialer
+	resolve   bool
+	tls       bool
+	handshake bool
+}
+
+func (d *traceDialer) Dial(network, address string) (net.Conn, error) {
+	trace := httptrace.ContextClientTrace(d.ctx)
+	if trace == nil {
+		return d.forward.Dial(network, address)
+	}
+
+	connect := transport.DialerFunc(func(network, address string) (net.Conn, error) {
+		if trace.ConnectStart != nil {
+			trace.ConnectStart(network, address)
+		}
+		conn, err := d.forward.Dial(network, address)
+		if trace.ConnectDone != nil {
+			trace.ConnectDone(network, address, err)
+		}
+		return conn, err
+	})
+
+	conn, err := d.dialResolved(trace, connect, network, address)
+	if err == nil && d.tls {
+		d.handshake = true
+		if trace.TLSHandshakeStart != nil {
+			trace.TLSHandshakeStart()
+		}
+	}
+	return conn, err
+}
+
+func (d *traceDialer) dialResolved(trace *httptrace.ClientTrace, connect transport.Dialer, network, address string) (net.Conn, error) {
+	if !d.resolve {
+		return connect.Dial(network, address)
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || net.ParseIP(host) != nil {
+		return connect.Dial(network, address)
+	}
+
+	if trace.DNSStart != nil {
+		trace.DNSStart(httptrace.DNSStartInfo{Host: host})
+	}
+	addresses, err := net.DefaultResolver.LookupHost(d.ctx, host)
+	if trace.DNSDone != nil {
+		info := httptrace.DNSDoneInfo{Err: err}
+		for _, addr := range addresses {
+			info.Addrs = append(info.Addrs, net.IPAddr{IP: net.ParseIP(addr)})
+		}
+		trace.DNSDone(info)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return transport.DialWith(connect, network, host, addresses, port)
+}
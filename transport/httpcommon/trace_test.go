@@ -0,0 +1,132 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package httpcommon
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
+)
+
+func TestWithRequestTrace(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	newClient := func(t *testing.T, settings HTTPTransportSettings, opts ...TransportOption) (*http.Client, func() []RequestTrace) {
+		var mu sync.Mutex
+		var traces []RequestTrace
+		opts = append(opts, WithRequestTrace(func(_ *http.Request, trace RequestTrace) {
+			mu.Lock()
+			defer mu.Unlock()
+			traces = append(traces, trace)
+		}))
+		client, err := settings.Client(opts...)
+		require.NoError(t, err)
+		return client, func() []RequestTrace {
+			mu.Lock()
+			defer mu.Unlock()
+			return traces
+		}
+	}
+
+	get := func(t *testing.T, client *http.Client, url string) {
+		resp, err := client.Get(url)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		_, err = ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+	}
+
+	t.Run("http", func(t *testing.T) {
+		server := httptest.NewServer(handler)
+		defer server.Close()
+		url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+		client, traces := newClient(t, DefaultHTTPTransportSettings())
+		get(t, client, url)
+		get(t, client, url)
+
+		require.Len(t, traces(), 2)
+		first, second := traces()[0], traces()[1]
+		assert.False(t, first.ConnReused)
+		assert.Greater(t, int64(first.DNS), int64(0))
+		assert.Greater(t, int64(first.Connect), int64(0))
+		assert.Zero(t, first.TLSHandshake)
+		assert.Greater(t, int64(first.FirstByte), int64(0))
+		assert.GreaterOrEqual(t, int64(first.Total), int64(first.FirstByte))
+		assert.NoError(t, first.Err)
+
+		assert.True(t, second.ConnReused)
+		assert.Zero(t, second.DNS)
+		assert.Zero(t, second.Connect)
+		assert.Greater(t, int64(second.FirstByte), int64(0))
+	})
+
+	t.Run("https", func(t *testing.T) {
+		server := httptest.NewTLSServer(handler)
+		defer server.Close()
+
+		settings := DefaultHTTPTransportSettings()
+		settings.TLS = &tlscommon.Config{VerificationMode: tlscommon.VerifyNone}
+		client, traces := newClient(t, settings)
+		get(t, client, server.URL)
+
+		require.Len(t, traces(), 1)
+		trace := traces()[0]
+		assert.Zero(t, trace.DNS)
+		assert.Greater(t, int64(trace.Connect), int64(0))
+		assert.Greater(t, int64(trace.TLSHandshake), int64(0))
+		assert.Greater(t, int64(trace.FirstByte), int64(0))
+	})
+
+	t.Run("one trace per attempt", func(t *testing.T) {
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		retry := WithModRoundtripper(func(rt http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				resp, err := rt.RoundTrip(req)
+				if err != nil {
+					return nil, err
+				}
+				_, _ = ioutil.ReadAll(resp.Body)
+				resp.Body.Close()
+				return rt.RoundTrip(req)
+			})
+		})
+		client, traces := newClient(t, DefaultHTTPTransportSettings(), retry)
+		get(t, client, server.URL)
+
+		require.Len(t, traces(), 2)
+		assert.False(t, traces()[0].ConnReused)
+		assert.True(t, traces()[1].ConnReused)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return fn(req) }
@@ -0,0 +1,84 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package httpcommon
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/elastic/elastic-agent-libs/transport"
+)
+
+// UnixSocketScheme is the URL scheme of hosts served over a unix socket. The
+// hosts are configured as unix://[host]/path/to/socket, where host is the
+// value of the HTTP Host header and defaults to localhost.
+const UnixSocketScheme = "unix"
+
+const defaultUnixSocketHost = "localhost"
+
+// IsUnixSocketURL checks if rawURL uses the unix socket scheme.
+func IsUnixSocketURL(rawURL string) bool {
+	return strings.HasPrefix(strings.ToLower(rawURL), UnixSocketScheme+"://")
+}
+
+// ParseUnixSocketURL splits a unix://[host]/path/to/socket URL into the HTTP
+// host to use in requests and the path of the socket.
+func ParseUnixSocketURL(rawURL string) (host, socketPath string, err error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Scheme != UnixSocketScheme {
+		return "", "", fmt.Errorf("invalid unix socket URL '%v': scheme must be %v", rawURL, UnixSocketScheme)
+	}
+	if u.Path == "" || u.Path == "/" {
+		return "", "", fmt.Errorf("invalid unix socket URL '%v': missing socket path", rawURL)
+	}
+
+	host = u.Host
+	if host == "" {
+		host = defaultUnixSocketHost
+	}
+	return host, u.Path, nil
+}
+
+// WithUnixSocket connects to the unix socket at path instead of the hosts of
+// the requests. The proxy settings are ignored. TLS is still used for https
+// requests, using the request host as server name.
+func WithUnixSocket(path string) TransportOption {
+	return unixSocketOption(path)
+}
+
+type unixSocketOption string
+
+var (
+	_ dialerOption        = unixSocketOption("")
+	_ httpTransportOption = unixSocketOption("")
+)
+
+func (unixSocketOption) sealTransportOption() {}
+
+func (path unixSocketOption) baseDialer(s *HTTPTransportSettings) transport.Dialer {
+	return transport.UnixDialer(s.dialTimeout(), string(path))
+}
+
+func (unixSocketOption) applyTransport(_ *HTTPTransportSettings, t *http.Transport) {
+	t.Proxy = nil
+}
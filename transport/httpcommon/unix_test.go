@@ -0,0 +1,107 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package httpcommon
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newUnixSocketServer starts an HTTP server listening on a unix socket and
+// returns the path of the socket.
+func newUnixSocketServer(t *testing.T, handler http.Handler) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "httpcommon")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "http.sock")
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(handler)
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+	return path
+}
+
+func TestParseUnixSocketURL(t *testing.T) {
+	tests := map[string]struct {
+		url, host, path string
+		wantErr         bool
+	}{
+		"default host":   {url: "unix:///var/run/es.sock", host: "localhost", path: "/var/run/es.sock"},
+		"with host":      {url: "unix://es.local/var/run/es.sock", host: "es.local", path: "/var/run/es.sock"},
+		"missing path":   {url: "unix://es.local", wantErr: true},
+		"invalid scheme": {url: "http://localhost/var/run/es.sock", wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			host, path, err := ParseUnixSocketURL(test.url)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.host, host)
+			assert.Equal(t, test.path, path)
+		})
+	}
+
+	assert.True(t, IsUnixSocketURL("UNIX:///var/run/es.sock"))
+	assert.False(t, IsUnixSocketURL("http://localhost:9200"))
+}
+
+func TestWithUnixSocket(t *testing.T) {
+	var host string
+	path := newUnixSocketServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+
+	// The proxy must not be used to reach the socket.
+	proxyURL, err := url.Parse("http://127.0.0.1:1")
+	require.NoError(t, err)
+	settings := DefaultHTTPTransportSettings()
+	settings.Proxy.URL = (*ProxyURI)(proxyURL)
+
+	client, err := settings.Client(WithUnixSocket(path))
+	require.NoError(t, err)
+
+	resp, err := client.Get("http://es.local/_cluster/health")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "/_cluster/health", string(body))
+	assert.Equal(t, "es.local", host)
+}
@@ -0,0 +1,174 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package transport
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket limiting the number of bytes per second going
+// through the connections sharing it. The bucket holds up to a tenth of a
+// second worth of bytes, bounding the bursts after idle periods. The limit can
+// be changed at any time with SetLimit.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // bytes per second, 0 means unlimited
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a RateLimiter allowing bytesPerSecond bytes per
+// second. A limit of 0 or less disables the rate limiting.
+func NewRateLimiter(bytesPerSecond int64) *RateLimiter {
+	l := &RateLimiter{}
+	l.SetLimit(bytesPerSecond)
+	l.tokens = l.burst()
+	return l
+}
+
+// Limit returns the current limit in bytes per second, 0 if unlimited.
+func (l *RateLimiter) Limit() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int64(l.rate)
+}
+
+// SetLimit changes the limit to bytesPerSecond bytes per second. A limit of 0
+// or less disables the rate limiting.
+func (l *RateLimiter) SetLimit(bytesPerSecond int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(time.Now())
+	if bytesPerSecond < 0 {
+		bytesPerSecond = 0
+	}
+	l.rate = float64(bytesPerSecond)
+	if burst := l.burst(); l.tokens > burst {
+		l.tokens = burst
+	}
+}
+
+// burst returns the capacity of the bucket, to be called with the lock held.
+func (l *RateLimiter) burst() float64 {
+	if burst := l.rate / 10; burst > 1 {
+		return burst
+	}
+	return 1
+}
+
+// refill adds the tokens accumulated since the last call, to be called with
+// the lock held.
+func (l *RateLimiter) refill(now time.Time) {
+	if !l.last.IsZero() && l.rate > 0 {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if burst := l.burst(); l.tokens > burst {
+			l.tokens = burst
+		}
+	}
+	l.last = now
+}
+
+// take returns the number of bytes that can be transferred now, at most n,
+// and how long to wait before transferring them.
+func (l *RateLimiter) take(n int) (int, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate <= 0 {
+		return n, 0
+	}
+
+	if burst := int(l.burst()); n > burst {
+		n = burst
+	}
+	l.refill(time.Now())
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return n, 0
+	}
+	return n, time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// wait blocks until up to n bytes can be transferred and returns how many.
+func (l *RateLimiter) wait(n int) int {
+	n, delay := l.take(n)
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	return n
+}
+
+// giveBack returns n unused bytes to the bucket.
+func (l *RateLimiter) giveBack(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate > 0 {
+		l.tokens += float64(n)
+		if burst := l.burst(); l.tokens > burst {
+			l.tokens = burst
+		}
+	}
+}
+
+type rateLimitConn struct {
+	net.Conn
+	write, read *RateLimiter
+}
+
+// RateLimitDialer limits the bandwidth of the connections created by d. Writes
+// are limited by write and reads by read, all the connections sharing a
+// limiter share its bandwidth. A nil limiter doesn't limit the corresponding
+// direction. Waiting for the limiter does not take the deadlines of the
+// connection into account.
+func RateLimitDialer(d Dialer, write, read *RateLimiter) Dialer {
+	return ConnWrapper(d, func(c net.Conn) net.Conn {
+		return &rateLimitConn{Conn: c, write: write, read: read}
+	})
+}
+
+func (c *rateLimitConn) Read(b []byte) (int, error) {
+	if c.read == nil || len(b) == 0 {
+		return c.Conn.Read(b)
+	}
+	allowed := c.read.wait(len(b))
+	n, err := c.Conn.Read(b[:allowed])
+	if n < allowed {
+		c.read.giveBack(allowed - n)
+	}
+	return n, err
+}
+
+func (c *rateLimitConn) Write(b []byte) (int, error) {
+	if c.write == nil {
+		return c.Conn.Write(b)
+	}
+
+	written := 0
+	for written < len(b) {
+		n := c.write.wait(len(b) - written)
+		n, err := c.Conn.Write(b[written : written+n])
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
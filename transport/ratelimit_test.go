@@ -0,0 +1,121 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package transport
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pipeDialer returns a dialer creating in-memory connections and the channel
+// receiving their server side.
+func pipeDialer() (Dialer, <-chan net.Conn) {
+	servers := make(chan net.Conn, 1)
+	return DialerFunc(func(_, _ string) (net.Conn, error) {
+		client, server := net.Pipe()
+		servers <- server
+		return client, nil
+	}), servers
+}
+
+func TestRateLimitDialerWrites(t *testing.T) {
+	const (
+		limit = 100 * 1024
+		size  = 40 * 1024
+	)
+
+	d, servers := pipeDialer()
+	conn, err := RateLimitDialer(d, NewRateLimiter(limit), nil).Dial("tcp", "localhost:80")
+	require.NoError(t, err)
+	defer conn.Close()
+	server := <-servers
+	go func() { _, _ = io.Copy(ioutil.Discard, server) }()
+
+	start := time.Now()
+	n, err := conn.Write(make([]byte, size))
+	elapsed := time.Since(start)
+	require.NoError(t, err)
+	assert.Equal(t, size, n)
+
+	// The first tenth of a second worth of bytes is sent right away, the
+	// remaining bytes can't go faster than the limit.
+	minDuration := time.Duration(float64(size-limit/10) / limit * float64(time.Second))
+	assert.GreaterOrEqual(t, int64(elapsed), int64(minDuration)*9/10, "write took %v", elapsed)
+	assert.LessOrEqual(t, float64(size-limit/10)/elapsed.Seconds(), limit*1.1)
+}
+
+func TestRateLimitDialerReads(t *testing.T) {
+	const (
+		limit = 100 * 1024
+		size  = 30 * 1024
+	)
+
+	d, servers := pipeDialer()
+	conn, err := RateLimitDialer(d, nil, NewRateLimiter(limit)).Dial("tcp", "localhost:80")
+	require.NoError(t, err)
+	defer conn.Close()
+	server := <-servers
+	go func() {
+		_, _ = server.Write(make([]byte, size))
+		server.Close()
+	}()
+
+	start := time.Now()
+	n, err := io.Copy(ioutil.Discard, conn)
+	elapsed := time.Since(start)
+	require.NoError(t, err)
+	assert.Equal(t, int64(size), n)
+
+	minDuration := time.Duration(float64(size-limit/10) / limit * float64(time.Second))
+	assert.GreaterOrEqual(t, int64(elapsed), int64(minDuration)*9/10, "read took %v", elapsed)
+}
+
+func TestRateLimiterSetLimit(t *testing.T) {
+	l := NewRateLimiter(0)
+	assert.Equal(t, int64(0), l.Limit())
+
+	// unlimited
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, 1024*1024, l.wait(1024*1024))
+	}
+	assert.Less(t, int64(time.Since(start)), int64(100*time.Millisecond))
+
+	l.SetLimit(50 * 1024)
+	assert.Equal(t, int64(50*1024), l.Limit())
+
+	// chunks are bounded by the bucket capacity
+	assert.Equal(t, 5*1024, l.wait(1024*1024))
+
+	start = time.Now()
+	sent := 0
+	for sent < 10*1024 {
+		sent += l.wait(10*1024 - sent)
+	}
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, int64(elapsed), int64(180*time.Millisecond), "took %v", elapsed)
+
+	l.SetLimit(-1)
+	assert.Equal(t, int64(0), l.Limit())
+}
@@ -25,6 +25,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/elastic/elastic-agent-libs/logp"
 	"github.com/elastic/elastic-agent-libs/testing"
 	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
 )
@@ -175,6 +176,14 @@ func tlsDialWith(
 	}
 
 	err = conn.Handshake()
+	if err != nil && config != nil && config.PlaintextFallback && isNotTLSError(err) {
+		_ = conn.Close()
+		logp.NewLogger(logSelector).Warnf("SECURITY WARNING: %v did not answer the TLS handshake with TLS (%v), "+
+			"falling back to an unencrypted connection because ssl.plaintext_fallback is enabled. "+
+			"Configure TLS on the server or disable the fallback.", address, err)
+		d.Warn("security", "server does not speak TLS, falling back to an unencrypted connection")
+		return dialer.Dial(network, address)
+	}
 	d.Fatal("handshake", err)
 	if err != nil {
 		_ = conn.Close()
@@ -197,6 +206,14 @@ func tlsDialWith(
 	return conn, nil
 }
 
+// isNotTLSError reports whether the TLS handshake failed because the server
+// answered with something else than a TLS record, e.g. a plaintext HTTP
+// response. Connection and certificate verification errors are not.
+func isNotTLSError(err error) bool {
+	var recordErr tls.RecordHeaderError
+	return errors.As(err, &recordErr)
+}
+
 func postVerifyTLSConnection(d testing.Driver, conn *tls.Conn, config *tlscommon.TLSConfig) error {
 	st := conn.ConnectionState()
 
@@ -0,0 +1,118 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package transport
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"net"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
+)
+
+// TLSConnectionInfo describes the parameters negotiated during a TLS handshake.
+type TLSConnectionInfo struct {
+	Version            tlscommon.TLSVersion
+	CipherSuite        string
+	NegotiatedProtocol string
+	ServerName         string
+	DidResume          bool
+
+	// PeerCertificates is the certificate chain presented by the peer, leaf
+	// first.
+	PeerCertificates []CertificateSummary
+
+	// VerifiedChains is only populated if the standard library verified the
+	// peer, our own verification (see tlscommon.TLSConfig) does not report
+	// the chains it built.
+	VerifiedChains [][]CertificateSummary
+}
+
+// CertificateSummary contains the human relevant fields of a certificate.
+type CertificateSummary struct {
+	Subject      string
+	Issuer       string
+	SerialNumber string
+	NotBefore    time.Time
+	NotAfter     time.Time
+	SHA256       string
+}
+
+// NewTLSConnectionInfo extracts the negotiated parameters from st.
+func NewTLSConnectionInfo(st tls.ConnectionState) TLSConnectionInfo {
+	info := TLSConnectionInfo{
+		Version:            tlscommon.TLSVersion(st.Version),
+		CipherSuite:        tls.CipherSuiteName(st.CipherSuite),
+		NegotiatedProtocol: st.NegotiatedProtocol,
+		ServerName:         st.ServerName,
+		DidResume:          st.DidResume,
+		PeerCertificates:   summarizeCertificates(st.PeerCertificates),
+	}
+	for _, chain := range st.VerifiedChains {
+		info.VerifiedChains = append(info.VerifiedChains, summarizeCertificates(chain))
+	}
+	return info
+}
+
+// TLSDialerWithInfo creates a TLS dialer like TLSDialer, calling onHandshake
+// with the negotiated parameters after every successful handshake.
+func TLSDialerWithInfo(
+	forward Dialer,
+	config *tlscommon.TLSConfig,
+	timeout time.Duration,
+	onHandshake func(TLSConnectionInfo),
+) Dialer {
+	dialer := TLSDialer(forward, config, timeout)
+	if onHandshake == nil {
+		return dialer
+	}
+
+	return DialerFunc(func(network, address string) (net.Conn, error) {
+		conn, err := dialer.Dial(network, address)
+		if err != nil {
+			return nil, err
+		}
+		if tlsConn, ok := conn.(*tls.Conn); ok {
+			onHandshake(NewTLSConnectionInfo(tlsConn.ConnectionState()))
+		}
+		return conn, nil
+	})
+}
+
+func summarizeCertificates(certs []*x509.Certificate) []CertificateSummary {
+	if len(certs) == 0 {
+		return nil
+	}
+
+	summaries := make([]CertificateSummary, len(certs))
+	for i, cert := range certs {
+		fingerprint := sha256.Sum256(cert.Raw)
+		summaries[i] = CertificateSummary{
+			Subject:      cert.Subject.String(),
+			Issuer:       cert.Issuer.String(),
+			SerialNumber: cert.SerialNumber.String(),
+			NotBefore:    cert.NotBefore,
+			NotAfter:     cert.NotAfter,
+			SHA256:       hex.EncodeToString(fingerprint[:]),
+		}
+	}
+	return summaries
+}
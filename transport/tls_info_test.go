@@ -0,0 +1,66 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package transport
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
+)
+
+func TestTLSDialerWithInfo(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		MaxVersion: tls.VersionTLS12,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	var infos []TLSConnectionInfo
+	dialer := TLSDialerWithInfo(
+		NetDialer(time.Second),
+		&tlscommon.TLSConfig{Verification: tlscommon.VerifyNone},
+		time.Second,
+		func(info TLSConnectionInfo) { infos = append(infos, info) },
+	)
+
+	conn, err := dialer.Dial("tcp", u.Host)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.Len(t, infos, 1)
+	info := infos[0]
+	assert.Equal(t, tlscommon.TLSVersion12, info.Version)
+	assert.Equal(t, "TLSv1.2", info.Version.String())
+	assert.NotEmpty(t, info.CipherSuite)
+	require.Len(t, info.PeerCertificates, 1)
+	assert.Equal(t, server.Certificate().Subject.String(), info.PeerCertificates[0].Subject)
+	assert.Equal(t, server.Certificate().NotAfter, info.PeerCertificates[0].NotAfter)
+}
@@ -0,0 +1,112 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package transport
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
+)
+
+// plaintextServer answers the first connection, the TLS handshake, with a
+// plaintext message and echos the first line received on the following
+// connections.
+func plaintextServer(t *testing.T) (addr string, lines <-chan string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		for i := 0; ; i++ {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			if i == 0 {
+				_, _ = conn.Write([]byte("HTTP/1.1 400 Bad Request\r\n\r\n"))
+				conn.Close()
+				continue
+			}
+			line, _ := bufio.NewReader(conn).ReadString('\n')
+			received <- line
+			conn.Close()
+		}
+	}()
+	return l.Addr().String(), received
+}
+
+func TestTLSDialerPlaintextFallback(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		addr, _ := plaintextServer(t)
+		dialer := TLSDialer(NetDialer(time.Second), &tlscommon.TLSConfig{}, time.Second)
+
+		_, err := dialer.Dial("tcp", addr)
+		require.Error(t, err)
+		var recordErr tls.RecordHeaderError
+		assert.True(t, errors.As(err, &recordErr), err)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		addr, lines := plaintextServer(t)
+		dialer := TLSDialer(NetDialer(time.Second), &tlscommon.TLSConfig{PlaintextFallback: true}, time.Second)
+
+		conn, err := dialer.Dial("tcp", addr)
+		require.NoError(t, err)
+		defer conn.Close()
+		_, isTLS := conn.(*tls.Conn)
+		assert.False(t, isTLS)
+
+		_, err = conn.Write([]byte("hello\n"))
+		require.NoError(t, err)
+		assert.Equal(t, "hello\n", <-lines)
+	})
+
+	t.Run("refused connection does not fall back", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := l.Addr().String()
+		l.Close()
+
+		dialer := TLSDialer(NetDialer(time.Second), &tlscommon.TLSConfig{PlaintextFallback: true}, time.Second)
+		_, err = dialer.Dial("tcp", addr)
+		require.Error(t, err)
+		assert.False(t, isNotTLSError(err), err)
+	})
+
+	t.Run("certificate verification failure does not fall back", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		defer server.Close()
+
+		dialer := TLSDialer(NetDialer(time.Second), &tlscommon.TLSConfig{PlaintextFallback: true}, time.Second)
+		_, err := dialer.Dial("tcp", server.Listener.Addr().String())
+		require.Error(t, err)
+		assert.False(t, isNotTLSError(err), err)
+	})
+}
@@ -311,10 +311,14 @@ func TestCAPinning(t *testing.T) {
 }
 
 func genCA() (tls.Certificate, error) {
+	return genNamedCA("localhost")
+}
+
+func genNamedCA(commonName string) (tls.Certificate, error) {
 	ca := &x509.Certificate{
 		SerialNumber: serial(),
 		Subject: pkix.Name{
-			CommonName:    "localhost",
+			CommonName:    commonName,
 			Organization:  []string{"TESTING"},
 			Country:       []string{"CANADA"},
 			Province:      []string{"QUEBEC"},
@@ -0,0 +1,61 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tlscommon
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+)
+
+// makeGetClientCertificate returns a tls.Config.GetClientCertificate callback
+// presenting the first certificate issued by one of the CAs accepted by the
+// server. The first certificate is used if none matches or if the server did
+// not send any hint.
+func makeGetClientCertificate(certs []tls.Certificate) func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return func(cri *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		if len(certs) == 0 {
+			// No certificate configured, don't send one.
+			return &tls.Certificate{}, nil
+		}
+
+		for i := range certs {
+			if issuedByAcceptableCA(&certs[i], cri.AcceptableCAs) {
+				return &certs[i], nil
+			}
+		}
+		return &certs[0], nil
+	}
+}
+
+// issuedByAcceptableCA checks if any certificate in the chain of cert has been
+// issued by one of the given distinguished names.
+func issuedByAcceptableCA(cert *tls.Certificate, acceptableCAs [][]byte) bool {
+	for _, der := range cert.Certificate {
+		x509Cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			continue
+		}
+		for _, ca := range acceptableCAs {
+			if bytes.Equal(x509Cert.RawIssuer, ca) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tlscommon
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetClientCertificate(t *testing.T) {
+	caA, err := genNamedCA("ca-a")
+	require.NoError(t, err)
+	caB, err := genNamedCA("ca-b")
+	require.NoError(t, err)
+	caC, err := genNamedCA("ca-c")
+	require.NoError(t, err)
+
+	certA, err := genSignedCert(caA, x509.KeyUsageDigitalSignature, false)
+	require.NoError(t, err)
+	certB, err := genSignedCert(caB, x509.KeyUsageDigitalSignature, false)
+	require.NoError(t, err)
+
+	getClientCertificate := makeGetClientCertificate([]tls.Certificate{certA, certB})
+
+	tests := map[string]struct {
+		acceptableCAs [][]byte
+		want          tls.Certificate
+	}{
+		"no hint": {
+			want: certA,
+		},
+		"matching second certificate": {
+			acceptableCAs: [][]byte{caC.Leaf.RawSubject, caB.Leaf.RawSubject},
+			want:          certB,
+		},
+		"two acceptable CAs": {
+			acceptableCAs: [][]byte{caB.Leaf.RawSubject, caA.Leaf.RawSubject},
+			want:          certA,
+		},
+		"no matching CA": {
+			acceptableCAs: [][]byte{caC.Leaf.RawSubject},
+			want:          certA,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cert, err := getClientCertificate(&tls.CertificateRequestInfo{AcceptableCAs: test.acceptableCAs})
+			require.NoError(t, err)
+			assert.Equal(t, test.want.Certificate, cert.Certificate)
+		})
+	}
+
+	t.Run("no certificates", func(t *testing.T) {
+		cert, err := makeGetClientCertificate(nil)(&tls.CertificateRequestInfo{})
+		require.NoError(t, err)
+		assert.Empty(t, cert.Certificate)
+	})
+}
+
+func TestClientCertificateSelectedByServerCA(t *testing.T) {
+	caA, err := genNamedCA("ca-a")
+	require.NoError(t, err)
+	caB, err := genNamedCA("ca-b")
+	require.NoError(t, err)
+	caServer, err := genNamedCA("ca-server")
+	require.NoError(t, err)
+
+	certA, err := genSignedCert(caA, x509.KeyUsageDigitalSignature, false)
+	require.NoError(t, err)
+	certB, err := genSignedCert(caB, x509.KeyUsageDigitalSignature, false)
+	require.NoError(t, err)
+	serverCert, err := genSignedCert(caServer, x509.KeyUsageDigitalSignature, false)
+	require.NoError(t, err)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(caB.Leaf)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{ //nolint:gosec // test server
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})
+	require.NoError(t, err)
+	defer listener.Close()
+
+	peerCerts := make(chan []*x509.Certificate, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tlsConn, _ := conn.(*tls.Conn)
+		if err := tlsConn.Handshake(); err != nil {
+			peerCerts <- nil
+			return
+		}
+		peerCerts <- tlsConn.ConnectionState().PeerCertificates
+	}()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(caServer.Leaf)
+	config := &TLSConfig{
+		Verification: VerifyFull,
+		Certificates: []tls.Certificate{certA, certB},
+		RootCAs:      rootCAs,
+	}
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	client := tls.Client(conn, config.BuildModuleClientConfig("localhost"))
+	defer client.Close()
+	require.NoError(t, client.Handshake())
+
+	certs := <-peerCerts
+	require.Len(t, certs, 1)
+	assert.Equal(t, certB.Leaf.Raw, certs[0].Raw)
+}
+
+func TestLoadTLSConfigWithMultipleCertificates(t *testing.T) {
+	cfg, err := load(`
+enabled: true
+certificate: ca_test.pem
+key: ca_test.key
+certificates:
+  - certificate: ca_test.pem
+    key: ca_test.key
+`)
+	require.NoError(t, err)
+
+	tlsC, err := LoadTLSConfig(cfg)
+	require.NoError(t, err)
+	assert.Len(t, tlsC.Certificates, 2)
+	assert.NotNil(t, tlsC.ToConfig().GetClientCertificate)
+
+	_, err = load(`
+enabled: true
+certificates:
+  - certificate: ca_test.pem
+`)
+	assert.Error(t, err)
+}
@@ -36,15 +36,21 @@ type Config struct {
 	CipherSuites         []CipherSuite           `config:"cipher_suites" yaml:"cipher_suites,omitempty"`
 	CAs                  []string                `config:"certificate_authorities" yaml:"certificate_authorities,omitempty"`
 	Certificate          CertificateConfig       `config:",inline" yaml:",inline"`
+	Certificates         []CertificateConfig     `config:"certificates" yaml:"certificates,omitempty"`
 	CurveTypes           []tlsCurveType          `config:"curve_types" yaml:"curve_types,omitempty"`
 	Renegotiation        TLSRenegotiationSupport `config:"renegotiation" yaml:"renegotiation"`
 	CASha256             []string                `config:"ca_sha256" yaml:"ca_sha256,omitempty"`
 	CATrustedFingerprint string                  `config:"ca_trusted_fingerprint" yaml:"ca_trusted_fingerprint,omitempty"`
+	OCSPStapling         OCSPStaplingMode        `config:"ocsp_stapling" yaml:"ocsp_stapling,omitempty"`
+	PlaintextFallback    bool                    `config:"plaintext_fallback" yaml:"plaintext_fallback,omitempty"` // Retry without TLS if the server does not speak TLS, for migrations only.
+	NextProtos           []string                `config:"alpn_protocols" yaml:"alpn_protocols,omitempty"`         // ALPN protocols advertised, in order of preference.
 }
 
 // LoadTLSConfig will load a certificate from config with all TLS based keys
 // defined. If Certificate and CertificateKey are configured, client authentication
-// will be configured. If no CAs are configured, the host CA will be used by go
+// will be configured. Additional client certificates can be configured with
+// Certificates, the certificate presented is then selected based on the CAs
+// accepted by the server. If no CAs are configured, the host CA will be used by go
 // built-in TLS support.
 func LoadTLSConfig(config *Config) (*TLSConfig, error) {
 	if !config.IsEnabled() {
@@ -68,6 +74,15 @@ func LoadTLSConfig(config *Config) (*TLSConfig, error) {
 	cert, err := LoadCertificate(&config.Certificate)
 	logFail(err)
 
+	var additionalCerts []tls.Certificate
+	for i := range config.Certificates {
+		c, err := LoadCertificate(&config.Certificates[i])
+		logFail(err)
+		if c != nil {
+			additionalCerts = append(additionalCerts, *c)
+		}
+	}
+
 	cas, errs := LoadCertificateAuthorities(config.CAs)
 	logFail(errs...)
 
@@ -80,6 +95,7 @@ func LoadTLSConfig(config *Config) (*TLSConfig, error) {
 	if cert != nil {
 		certs = []tls.Certificate{*cert}
 	}
+	certs = append(certs, additionalCerts...)
 
 	// return config if no error occurred
 	return &TLSConfig{
@@ -92,6 +108,9 @@ func LoadTLSConfig(config *Config) (*TLSConfig, error) {
 		Renegotiation:        tls.RenegotiationSupport(config.Renegotiation),
 		CASha256:             config.CASha256,
 		CATrustedFingerprint: config.CATrustedFingerprint,
+		OCSPStapling:         config.OCSPStapling,
+		PlaintextFallback:    config.PlaintextFallback,
+		NextProtos:           config.NextProtos,
 	}, nil
 }
 
@@ -102,7 +121,15 @@ func (c *Config) Validate() error {
 		cfgwarn.Deprecate("8.0.0", "Treating the CommonName field on X.509 certificates as a host name when no Subject Alternative Names are present is going to be removed. Please update your certificates if needed.")
 	})
 
-	return c.Certificate.Validate()
+	if err := c.Certificate.Validate(); err != nil {
+		return err
+	}
+	for i := range c.Certificates {
+		if err := c.Certificates[i].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // IsEnabled returns true if the `enable` field is set to true in the yaml.
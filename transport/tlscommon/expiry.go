@@ -0,0 +1,152 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tlscommon
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"sync"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+// CertificateExpiry identifies a certificate and when it expires.
+type CertificateExpiry struct {
+	Subject  string
+	NotAfter time.Time
+}
+
+// EarliestExpiry returns the certificate expiring first among all the
+// certificates, including the intermediate certificates of their chains,
+// configured in c. False is returned if no certificate is configured.
+func (c *TLSConfig) EarliestExpiry() (CertificateExpiry, bool) {
+	if c == nil {
+		return CertificateExpiry{}, false
+	}
+	return earliestExpiry(c.Certificates)
+}
+
+func earliestExpiry(certs []tls.Certificate) (CertificateExpiry, bool) {
+	var earliest CertificateExpiry
+	found := false
+	for _, cert := range certs {
+		for i, der := range cert.Certificate {
+			x509Cert := cert.Leaf
+			if i > 0 || x509Cert == nil {
+				var err error
+				if x509Cert, err = x509.ParseCertificate(der); err != nil {
+					continue
+				}
+			}
+			if !found || x509Cert.NotAfter.Before(earliest.NotAfter) {
+				earliest = CertificateExpiry{
+					Subject:  x509Cert.Subject.String(),
+					NotAfter: x509Cert.NotAfter,
+				}
+				found = true
+			}
+		}
+	}
+	return earliest, found
+}
+
+// ExpiryMonitor reports certificates of a TLSConfig that expire within a
+// window of time, or are already expired.
+type ExpiryMonitor struct {
+	config *TLSConfig
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	callbacks []func(CertificateExpiry)
+}
+
+// NewExpiryMonitor creates an ExpiryMonitor for the certificates of config.
+// Callbacks are called if the earliest expiry is within window.
+func NewExpiryMonitor(config *TLSConfig, window time.Duration) *ExpiryMonitor {
+	return &ExpiryMonitor{config: config, window: window, now: time.Now}
+}
+
+// OnExpiringSoon registers fn to be called by Check when a certificate
+// expires within the monitor window.
+func (m *ExpiryMonitor) OnExpiringSoon(fn func(CertificateExpiry)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.callbacks = append(m.callbacks, fn)
+}
+
+// Check calls the registered callbacks if the earliest certificate expiry is
+// within the monitor window. Callbacks are called on every Check until the
+// certificate is replaced. It returns true if the callbacks were called.
+func (m *ExpiryMonitor) Check() bool {
+	expiry, ok := m.config.EarliestExpiry()
+	if !ok || expiry.NotAfter.Sub(m.now()) > m.window {
+		return false
+	}
+
+	m.mu.Lock()
+	callbacks := append([]func(CertificateExpiry){}, m.callbacks...)
+	m.mu.Unlock()
+	for _, fn := range callbacks {
+		fn(expiry)
+	}
+	return true
+}
+
+// Run calls Check every interval until ctx is cancelled.
+func (m *ExpiryMonitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	m.Check()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Check()
+		}
+	}
+}
+
+// DaysUntilExpiry returns the number of days until the earliest certificate
+// expiry, negative if it is already expired. False is returned if no
+// certificate is configured.
+func (m *ExpiryMonitor) DaysUntilExpiry() (float64, bool) {
+	expiry, ok := m.config.EarliestExpiry()
+	if !ok {
+		return 0, false
+	}
+	return expiry.NotAfter.Sub(m.now()).Hours() / 24, true
+}
+
+// RegisterMetrics adds the certificate namespace with the days_until_expiry
+// gauge to reg. The gauge is computed when the registry is collected and is
+// not reported if no certificate is configured.
+func (m *ExpiryMonitor) RegisterMetrics(reg *monitoring.Registry) {
+	monitoring.NewFunc(reg, "certificate", func(_ monitoring.Mode, v monitoring.Visitor) {
+		v.OnRegistryStart()
+		defer v.OnRegistryFinished()
+
+		if days, ok := m.DaysUntilExpiry(); ok {
+			monitoring.ReportFloat(v, "days_until_expiry", days)
+		}
+	}, monitoring.Report)
+}
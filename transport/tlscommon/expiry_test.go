@@ -0,0 +1,115 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tlscommon
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+func genCertExpiringAt(t *testing.T, name string, notAfter time.Time) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestEarliestExpiry(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	config := &TLSConfig{Certificates: []tls.Certificate{
+		genCertExpiringAt(t, "later", now.Add(90*24*time.Hour)),
+		genCertExpiringAt(t, "sooner", now.Add(10*24*time.Hour)),
+	}}
+
+	expiry, ok := config.EarliestExpiry()
+	require.True(t, ok)
+	assert.Equal(t, "CN=sooner", expiry.Subject)
+	assert.True(t, now.Add(10*24*time.Hour).Equal(expiry.NotAfter))
+
+	_, ok = (&TLSConfig{}).EarliestExpiry()
+	assert.False(t, ok)
+}
+
+func TestExpiryMonitor(t *testing.T) {
+	now := time.Now()
+
+	tests := map[string]struct {
+		notAfter time.Time
+		fired    bool
+		days     float64
+	}{
+		"valid":       {notAfter: now.Add(60 * 24 * time.Hour), fired: false, days: 60},
+		"near expiry": {notAfter: now.Add(5 * 24 * time.Hour), fired: true, days: 5},
+		"expired":     {notAfter: now.Add(-2 * 24 * time.Hour), fired: true, days: -2},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := &TLSConfig{Certificates: []tls.Certificate{genCertExpiringAt(t, name, test.notAfter)}}
+			monitor := NewExpiryMonitor(config, 30*24*time.Hour)
+			monitor.now = func() time.Time { return now }
+
+			var expiries []CertificateExpiry
+			monitor.OnExpiringSoon(func(e CertificateExpiry) { expiries = append(expiries, e) })
+
+			assert.Equal(t, test.fired, monitor.Check())
+			if test.fired {
+				require.Len(t, expiries, 1)
+				assert.Equal(t, "CN="+name, expiries[0].Subject)
+			} else {
+				assert.Empty(t, expiries)
+			}
+
+			reg := monitoring.NewRegistry()
+			monitor.RegisterMetrics(reg)
+			snapshot := monitoring.CollectFlatSnapshot(reg, monitoring.Full, false)
+			assert.InDelta(t, test.days, snapshot.Floats["certificate.days_until_expiry"], 0.01)
+		})
+	}
+
+	t.Run("no certificates", func(t *testing.T) {
+		monitor := NewExpiryMonitor(&TLSConfig{}, time.Hour)
+		monitor.OnExpiringSoon(func(CertificateExpiry) { t.Fatal("unexpected callback") })
+		assert.False(t, monitor.Check())
+
+		reg := monitoring.NewRegistry()
+		monitor.RegisterMetrics(reg)
+		assert.Empty(t, reg.SnapshotFlat())
+	})
+}
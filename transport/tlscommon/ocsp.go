@@ -0,0 +1,171 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tlscommon
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// OCSPStaplingMode configures the verification of the OCSP response stapled
+// by the server during the handshake: `none`, `soft_fail` or `hard_fail`,
+// default to `none`. In both soft_fail and hard_fail modes the handshake
+// fails if the stapled response is invalid or reports the certificate as
+// revoked. They differ when no conclusive response is stapled: soft_fail
+// accepts the connection, hard_fail rejects it.
+type OCSPStaplingMode uint8
+
+// Constants of the supported OCSP stapling modes.
+const (
+	OCSPStaplingNone OCSPStaplingMode = iota
+	OCSPStaplingSoftFail
+	OCSPStaplingHardFail
+)
+
+var ocspStaplingModes = map[string]OCSPStaplingMode{
+	"":          OCSPStaplingNone,
+	"none":      OCSPStaplingNone,
+	"soft_fail": OCSPStaplingSoftFail,
+	"hard_fail": OCSPStaplingHardFail,
+}
+
+var ocspStaplingModesInverse = map[OCSPStaplingMode]string{
+	OCSPStaplingNone:     "none",
+	OCSPStaplingSoftFail: "soft_fail",
+	OCSPStaplingHardFail: "hard_fail",
+}
+
+var (
+	// ErrOCSPStapleMissing is returned in hard_fail mode when the server does
+	// not staple a conclusive OCSP response.
+	ErrOCSPStapleMissing = errors.New("server did not staple a conclusive OCSP response")
+
+	// ErrCertificateRevoked is returned when the stapled OCSP response
+	// reports the server certificate as revoked.
+	ErrCertificateRevoked = errors.New("server certificate is revoked")
+)
+
+func (m OCSPStaplingMode) String() string {
+	if s, ok := ocspStaplingModesInverse[m]; ok {
+		return s
+	}
+	return unknownType
+}
+
+// MarshalText marshal the OCSP stapling mode into a human readable value.
+func (m OCSPStaplingMode) MarshalText() ([]byte, error) {
+	if s, ok := ocspStaplingModesInverse[m]; ok {
+		return []byte(s), nil
+	}
+	return nil, fmt.Errorf("could not marshal '%+v' to text", m)
+}
+
+// Unpack unpacks the string into constants.
+func (m *OCSPStaplingMode) Unpack(s string) error {
+	mode, found := ocspStaplingModes[s]
+	if !found {
+		return fmt.Errorf("unknown OCSP stapling mode '%v'", s)
+	}
+
+	*m = mode
+	return nil
+}
+
+// withOCSPStapling adds the verification of the stapled OCSP response to
+// verify, the verification of the client connections.
+func withOCSPStapling(cfg *TLSConfig, verify func(tls.ConnectionState) error) func(tls.ConnectionState) error {
+	if cfg.OCSPStapling == OCSPStaplingNone {
+		return verify
+	}
+
+	return func(cs tls.ConnectionState) error {
+		if verify != nil {
+			if err := verify(cs); err != nil {
+				return err
+			}
+		}
+		return verifyOCSPStaple(cfg, cs)
+	}
+}
+
+func verifyOCSPStaple(cfg *TLSConfig, cs tls.ConnectionState) error {
+	if len(cs.OCSPResponse) == 0 {
+		return missingOCSPStaple(cfg)
+	}
+	if len(cs.PeerCertificates) == 0 {
+		return ErrMissingPeerCertificate
+	}
+
+	leaf := cs.PeerCertificates[0]
+	issuer := findIssuer(cs)
+	if issuer == nil {
+		return fmt.Errorf("cannot verify stapled OCSP response: issuer of '%s' not found", leaf.Subject)
+	}
+
+	resp, err := ocsp.ParseResponseForCert(cs.OCSPResponse, leaf, issuer)
+	if err != nil {
+		return fmt.Errorf("invalid stapled OCSP response: %w", err)
+	}
+
+	now := time.Now()
+	if cfg.time != nil {
+		now = cfg.time()
+	}
+	if !resp.NextUpdate.IsZero() && now.After(resp.NextUpdate) {
+		return fmt.Errorf("stapled OCSP response expired at %v", resp.NextUpdate)
+	}
+
+	switch resp.Status {
+	case ocsp.Good:
+		return nil
+	case ocsp.Revoked:
+		return fmt.Errorf("%w: revoked at %v", ErrCertificateRevoked, resp.RevokedAt)
+	default:
+		return missingOCSPStaple(cfg)
+	}
+}
+
+func missingOCSPStaple(cfg *TLSConfig) error {
+	if cfg.OCSPStapling == OCSPStaplingHardFail {
+		return ErrOCSPStapleMissing
+	}
+	return nil
+}
+
+// findIssuer returns the certificate that issued the server certificate,
+// taken from the verified chain if there is one or from the certificates
+// sent by the server.
+func findIssuer(cs tls.ConnectionState) *x509.Certificate {
+	for _, chain := range cs.VerifiedChains {
+		if len(chain) > 1 {
+			return chain[1]
+		}
+	}
+	leaf := cs.PeerCertificates[0]
+	for _, cert := range cs.PeerCertificates[1:] {
+		if leaf.CheckSignatureFrom(cert) == nil {
+			return cert
+		}
+	}
+	return nil
+}
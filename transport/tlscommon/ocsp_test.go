@@ -0,0 +1,165 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tlscommon
+
+import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestOCSPStapling(t *testing.T) {
+	ca, err := genCA()
+	require.NoError(t, err)
+	cert, err := genSignedCert(ca, x509.KeyUsageDigitalSignature, false)
+	require.NoError(t, err)
+
+	good := createOCSPResponse(t, ca, cert, ocsp.Good)
+	revoked := createOCSPResponse(t, ca, cert, ocsp.Revoked)
+
+	tests := map[string]struct {
+		mode   OCSPStaplingMode
+		staple []byte
+		err    error
+	}{
+		"disabled ignores revoked staple": {mode: OCSPStaplingNone, staple: revoked},
+		"soft fail with good staple":      {mode: OCSPStaplingSoftFail, staple: good},
+		"soft fail with revoked staple":   {mode: OCSPStaplingSoftFail, staple: revoked, err: ErrCertificateRevoked},
+		"soft fail without staple":        {mode: OCSPStaplingSoftFail},
+		"hard fail with good staple":      {mode: OCSPStaplingHardFail, staple: good},
+		"hard fail with revoked staple":   {mode: OCSPStaplingHardFail, staple: revoked, err: ErrCertificateRevoked},
+		"hard fail without staple":        {mode: OCSPStaplingHardFail, err: ErrOCSPStapleMissing},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			roots := x509.NewCertPool()
+			roots.AddCert(ca.Leaf)
+			cfg := &TLSConfig{
+				Verification: VerifyFull,
+				RootCAs:      roots,
+				OCSPStapling: test.mode,
+			}
+
+			serverCert := tls.Certificate{
+				Certificate: [][]byte{cert.Certificate[0], ca.Certificate[0]},
+				PrivateKey:  cert.PrivateKey,
+				OCSPStaple:  test.staple,
+			}
+			err := handshake(t, cfg.BuildModuleClientConfig("localhost"), serverCert)
+			if test.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, test.err), "unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestOCSPStaplingInvalidStaple(t *testing.T) {
+	ca, err := genCA()
+	require.NoError(t, err)
+	cert, err := genSignedCert(ca, x509.KeyUsageDigitalSignature, false)
+	require.NoError(t, err)
+	otherCA, err := genCA()
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Leaf)
+	cfg := &TLSConfig{
+		Verification: VerifyFull,
+		RootCAs:      roots,
+		OCSPStapling: OCSPStaplingSoftFail,
+	}
+
+	// A response signed by another CA must be rejected even in soft fail mode.
+	serverCert := tls.Certificate{
+		Certificate: [][]byte{cert.Certificate[0], ca.Certificate[0]},
+		PrivateKey:  cert.PrivateKey,
+		OCSPStaple:  createOCSPResponse(t, otherCA, cert, ocsp.Good),
+	}
+	err = handshake(t, cfg.BuildModuleClientConfig("localhost"), serverCert)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid stapled OCSP response")
+}
+
+func TestOCSPStaplingModeUnpack(t *testing.T) {
+	for name, expected := range map[string]OCSPStaplingMode{
+		"":          OCSPStaplingNone,
+		"none":      OCSPStaplingNone,
+		"soft_fail": OCSPStaplingSoftFail,
+		"hard_fail": OCSPStaplingHardFail,
+	} {
+		var mode OCSPStaplingMode
+		require.NoError(t, mode.Unpack(name))
+		assert.Equal(t, expected, mode)
+	}
+
+	var mode OCSPStaplingMode
+	assert.Error(t, mode.Unpack("always"))
+
+	cfg, err := load("ocsp_stapling: hard_fail")
+	require.NoError(t, err)
+	assert.Equal(t, OCSPStaplingHardFail, cfg.OCSPStapling)
+}
+
+func createOCSPResponse(t *testing.T, issuer, cert tls.Certificate, status int) []byte {
+	t.Helper()
+	now := time.Now()
+	template := ocsp.Response{
+		Status:       status,
+		SerialNumber: cert.Leaf.SerialNumber,
+		ThisUpdate:   now.Add(-time.Minute),
+		NextUpdate:   now.Add(time.Hour),
+	}
+	if status == ocsp.Revoked {
+		template.RevokedAt = now.Add(-time.Minute)
+	}
+
+	resp, err := ocsp.CreateResponse(issuer.Leaf, issuer.Leaf, template, issuer.PrivateKey.(crypto.Signer))
+	require.NoError(t, err)
+	return resp
+}
+
+// handshake runs a TLS handshake between a client using clientCfg and a server
+// presenting serverCert and returns the error of the client.
+func handshake(t *testing.T, clientCfg *tls.Config, serverCert tls.Certificate) error {
+	t.Helper()
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	go func() {
+		server := tls.Server(serverConn, &tls.Config{ //nolint:gosec // test server
+			Certificates: []tls.Certificate{serverCert},
+		})
+		_ = server.Handshake()
+		serverConn.Close()
+	}()
+
+	return tls.Client(clientConn, clientCfg).Handshake()
+}
@@ -37,6 +37,7 @@ type ServerConfig struct {
 	CurveTypes       []tlsCurveType      `config:"curve_types"`
 	ClientAuth       tlsClientAuth       `config:"client_authentication"` //`none`, `optional` or `required`
 	CASha256         []string            `config:"ca_sha256" yaml:"ca_sha256,omitempty"`
+	NextProtos       []string            `config:"alpn_protocols" yaml:"alpn_protocols,omitempty"` // ALPN protocols supported, in order of preference.
 }
 
 // LoadTLSServerConfig tranforms a ServerConfig into a `tls.Config` to be used directly with golang
@@ -91,6 +92,7 @@ func LoadTLSServerConfig(config *ServerConfig) (*TLSConfig, error) {
 		CurvePreferences: curves,
 		ClientAuth:       tls.ClientAuthType(config.ClientAuth),
 		CASha256:         config.CASha256,
+		NextProtos:       config.NextProtos,
 	}, nil
 }
 
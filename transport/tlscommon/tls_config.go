@@ -43,7 +43,8 @@ type TLSConfig struct {
 	Verification TLSVerificationMode
 
 	// List of certificate chains to present to the other side of the
-	// connection.
+	// connection. If more than one is configured, clients present the first
+	// one issued by a CA accepted by the server, or the first one otherwise.
 	Certificates []tls.Certificate
 
 	// Set of root certificate authorities use to verify server certificates.
@@ -80,6 +81,22 @@ type TLSConfig struct {
 	// this certificate will be added to the list of trusted CAs (RootCAs) during the handshake.
 	CATrustedFingerprint string
 
+	// OCSPStapling controls the verification of the OCSP response stapled by
+	// the server. It does not affect TLS servers.
+	OCSPStapling OCSPStaplingMode
+
+	// PlaintextFallback makes the client dialers retry without TLS when the
+	// server does not speak TLS. Only the handshakes failing because the
+	// server answered with something else than a TLS record fall back, a
+	// failed certificate verification never does. Disabled by default.
+	PlaintextFallback bool
+
+	// NextProtos is the list of supported application level protocols
+	// negotiated with ALPN, in order of preference. If empty, no protocol is
+	// advertised. The negotiated protocol is available from the connection
+	// state once the handshake completed.
+	NextProtos []string
+
 	// time returns the current time as the number of seconds since the epoch.
 	// If time is nil, TLS uses time.Now.
 	time func() time.Time
@@ -104,7 +121,7 @@ func (c *TLSConfig) ToConfig() *tls.Config {
 		logp.NewLogger("tls").Warn("SSL/TLS verifications disabled.")
 	}
 
-	return &tls.Config{
+	config := &tls.Config{
 		MinVersion:         minVersion,
 		MaxVersion:         maxVersion,
 		Certificates:       c.Certificates,
@@ -116,8 +133,18 @@ func (c *TLSConfig) ToConfig() *tls.Config {
 		Renegotiation:      c.Renegotiation,
 		ClientAuth:         c.ClientAuth,
 		Time:               c.time,
-		VerifyConnection:   makeVerifyConnection(c),
+		VerifyConnection:   withOCSPStapling(c, makeVerifyConnection(c)),
+	}
+	if len(c.NextProtos) > 0 {
+		config.NextProtos = append([]string(nil), c.NextProtos...)
 	}
+
+	// Let the server CA hints select the client certificate to present when
+	// more than one is configured.
+	if len(c.Certificates) > 1 {
+		config.GetClientCertificate = makeGetClientCertificate(c.Certificates)
+	}
+	return config
 }
 
 // BuildModuleConfig takes the TLSConfig and transform it into a `tls.Config`.
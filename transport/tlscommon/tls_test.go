@@ -639,3 +639,58 @@ supported_protocols: null
 		})
 	})
 }
+
+func TestALPNNegotiation(t *testing.T) {
+	var serverCfg ServerConfig
+	require.NoError(t, config.MustNewConfigFrom(`
+    certificate: testdata/server.crt
+    key: testdata/server.key
+    alpn_protocols: [custom/2, custom/1]
+  `).Unpack(&serverCfg))
+	serverTLS, err := LoadTLSServerConfig(&serverCfg)
+	require.NoError(t, err)
+
+	l, err := tls.Listen("tcp", "127.0.0.1:0", serverTLS.BuildServerConfig(""))
+	require.NoError(t, err)
+	defer l.Close()
+
+	negotiated := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		tlsConn, ok := conn.(*tls.Conn)
+		if !ok {
+			negotiated <- "not a TLS connection"
+			return
+		}
+		if err := tlsConn.Handshake(); err != nil {
+			negotiated <- err.Error()
+			return
+		}
+		negotiated <- tlsConn.ConnectionState().NegotiatedProtocol
+	}()
+
+	clientTLS, err := LoadTLSConfig(mustLoad(t, `
+    verification_mode: none
+    alpn_protocols: [custom/1, unknown]
+  `))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"custom/1", "unknown"}, clientTLS.ToConfig().NextProtos)
+
+	conn, err := tls.Dial("tcp", l.Addr().String(), clientTLS.BuildModuleClientConfig("localhost"))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	assert.Equal(t, "custom/1", conn.ConnectionState().NegotiatedProtocol)
+	assert.Equal(t, "custom/1", <-negotiated)
+}
+
+func TestALPNDefaults(t *testing.T) {
+	tmp, err := LoadTLSConfig(&Config{})
+	require.NoError(t, err)
+	assert.Nil(t, tmp.ToConfig().NextProtos)
+}
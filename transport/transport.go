@@ -53,12 +53,21 @@ func MakeDialer(c Config) (Dialer, error) {
 	if err != nil {
 		return nil, err
 	}
+	if c.PreDial != nil || c.PostDial != nil {
+		dialer = HookDialer(dialer, c.PreDial, c.PostDial)
+	}
+	if len(c.ConnWrappers) > 0 {
+		wrappers := c.ConnWrappers
+		dialer = ConnWrapper(dialer, func(conn net.Conn) net.Conn {
+			return WrapConn(conn, wrappers...)
+		})
+	}
 	if c.Stats != nil {
 		dialer = StatsDialer(dialer, c.Stats)
 	}
 
 	if c.TLS != nil {
-		return TLSDialer(dialer, c.TLS, c.Timeout), nil
+		dialer = TLSDialer(dialer, c.TLS, c.Timeout)
 	}
-	return dialer, nil
+	return LimitDialer(dialer, c.DialLimiter), nil
 }
@@ -47,12 +47,28 @@ func DialWith(
 	network, host string,
 	addresses []string,
 	port string,
+) (c net.Conn, err error) {
+	return DialWithFailureCache(dialer, nil, network, host, addresses, port)
+}
+
+// DialWithFailureCache dials one of a number of addresses with a given dialer,
+// trying the addresses that did not fail recently according to cache first.
+// The outcome of every attempt is recorded in cache. With a nil cache the
+// addresses are dialed in random order, like DialWith.
+func DialWithFailureCache(
+	dialer Dialer,
+	cache *AddressFailureCache,
+	network, host string,
+	addresses []string,
+	port string,
 ) (c net.Conn, err error) {
 	switch len(addresses) {
 	case 0:
 		return nil, fmt.Errorf("no route to host %v", host)
 	case 1:
-		return dialer.Dial(network, net.JoinHostPort(addresses[0], port))
+		if cache == nil {
+			return dialer.Dial(network, net.JoinHostPort(addresses[0], port))
+		}
 	}
 
 	// Use randomization on DNS reported addresses combined with timeout and ACKs
@@ -68,11 +84,27 @@ func DialWith(
 	// https://tools.ietf.org/html/rfc1794
 	// > "Clients, of course, may reorder this information" - with respect to
 	// > handling order of dns records in a response.forwarded. Really required?
-	for _, i := range rand.Perm(len(addresses)) {
-		c, err = dialer.Dial(network, net.JoinHostPort(addresses[i], port))
+	var ordered []string
+	if cache != nil {
+		ordered = cache.Sort(addresses)
+	} else {
+		ordered = make([]string, len(addresses))
+		for i, j := range rand.Perm(len(addresses)) {
+			ordered[i] = addresses[j]
+		}
+	}
+
+	for _, addr := range ordered {
+		c, err = dialer.Dial(network, net.JoinHostPort(addr, port))
 		if err == nil && c != nil {
+			if cache != nil {
+				cache.RecordSuccess(addr)
+			}
 			return c, err
 		}
+		if cache != nil {
+			cache.RecordFailure(addr)
+		}
 	}
 
 	if err == nil {
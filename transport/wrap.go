@@ -30,3 +30,53 @@ func ConnWrapper(d Dialer, w func(net.Conn) net.Conn) Dialer {
 		return w(c), nil
 	})
 }
+
+// HookDialer calls pre before and post after every dial of d. Either hook
+// may be nil. The result of post is returned to the caller.
+func HookDialer(
+	d Dialer,
+	pre func(network, addr string),
+	post func(conn net.Conn, err error) (net.Conn, error),
+) Dialer {
+	return DialerFunc(func(network, addr string) (net.Conn, error) {
+		if pre != nil {
+			pre(network, addr)
+		}
+		c, err := d.Dial(network, addr)
+		if post != nil {
+			c, err = post(c, err)
+		}
+		return c, err
+	})
+}
+
+// WrapConn applies wrappers to c in order, the first wrapper wraps c and the
+// last one is the outermost.
+func WrapConn(c net.Conn, wrappers ...func(net.Conn) net.Conn) net.Conn {
+	for _, w := range wrappers {
+		c = w(c)
+	}
+	return c
+}
+
+// WrapListener returns a listener applying wrappers, with WrapConn, to every
+// connection accepted by l.
+func WrapListener(l net.Listener, wrappers ...func(net.Conn) net.Conn) net.Listener {
+	if len(wrappers) == 0 {
+		return l
+	}
+	return &wrappedListener{Listener: l, wrappers: wrappers}
+}
+
+type wrappedListener struct {
+	net.Listener
+	wrappers []func(net.Conn) net.Conn
+}
+
+func (l *wrappedListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return WrapConn(c, l.wrappers...), nil
+}
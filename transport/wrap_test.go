@@ -0,0 +1,175 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package transport
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hookedConn struct {
+	net.Conn
+}
+
+func TestDialHooks(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	var preCalls []string
+	var postErrs []error
+	cfg := Config{
+		Timeout: time.Second,
+		PreDial: func(network, addr string) {
+			preCalls = append(preCalls, network+"://"+addr)
+		},
+		PostDial: func(conn net.Conn, err error) (net.Conn, error) {
+			postErrs = append(postErrs, err)
+			if err != nil {
+				return nil, err
+			}
+			return hookedConn{conn}, nil
+		},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		preCalls, postErrs = nil, nil
+		conn, err := Dial(cfg, "tcp", addr)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		assert.IsType(t, hookedConn{}, conn)
+		assert.Equal(t, []string{"tcp://" + addr}, preCalls)
+		assert.Equal(t, []error{nil}, postErrs)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		l.Close()
+		preCalls, postErrs = nil, nil
+		_, err := Dial(cfg, "tcp", addr)
+		require.Error(t, err)
+
+		assert.Equal(t, []string{"tcp://" + addr}, preCalls)
+		if assert.Len(t, postErrs, 1) {
+			assert.Equal(t, err, postErrs[0])
+		}
+	})
+
+	t.Run("post dial rejects", func(t *testing.T) {
+		errRejected := errors.New("rejected")
+		d := HookDialer(DialerFunc(func(_, _ string) (net.Conn, error) {
+			c, _ := net.Pipe()
+			return c, nil
+		}), nil, func(conn net.Conn, err error) (net.Conn, error) {
+			conn.Close()
+			return nil, errRejected
+		})
+		_, err := d.Dial("tcp", "localhost:9200")
+		assert.Equal(t, errRejected, err)
+	})
+}
+
+type countingConn struct {
+	net.Conn
+	read, written *int64
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	atomic.AddInt64(c.read, int64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	atomic.AddInt64(c.written, int64(n))
+	return n, err
+}
+
+func countingWrapper(read, written *int64) func(net.Conn) net.Conn {
+	return func(c net.Conn) net.Conn {
+		return &countingConn{Conn: c, read: read, written: written}
+	}
+}
+
+func TestConnWrappers(t *testing.T) {
+	var serverRead, serverWritten int64
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	l = WrapListener(l, countingWrapper(&serverRead, &serverWritten))
+	defer l.Close()
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn)
+	}()
+
+	var order []string
+	tracing := func(name string) func(net.Conn) net.Conn {
+		return func(c net.Conn) net.Conn {
+			order = append(order, name)
+			return c
+		}
+	}
+
+	var clientRead, clientWritten int64
+	conn, err := Dial(Config{
+		Timeout: time.Second,
+		ConnWrappers: []func(net.Conn) net.Conn{
+			tracing("first"),
+			countingWrapper(&clientRead, &clientWritten),
+			tracing("last"),
+		},
+	}, "tcp", l.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, []string{"first", "last"}, order)
+
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+	buf := make([]byte, 5)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(buf))
+
+	assert.EqualValues(t, 5, atomic.LoadInt64(&clientWritten))
+	assert.EqualValues(t, 5, atomic.LoadInt64(&clientRead))
+	assert.EqualValues(t, 5, atomic.LoadInt64(&serverRead))
+	// the server counts the write after the client received it
+	assert.Eventually(t, func() bool { return atomic.LoadInt64(&serverWritten) == 5 }, time.Second, time.Millisecond)
+}